@@ -2,11 +2,14 @@ package common
 
 import (
 	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	systemmodel "cyber-life/internal/model/system"
 	commonservice "cyber-life/internal/service/common"
@@ -34,19 +37,13 @@ func CreateAccountHandler(ctx *gin.Context) {
 	var req reqType
 	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = commonservice.CreateAccount(req.Type, req.Platform, req.PlatformURL, req.Username, req.Password, req.SecurityEmail, req.SecurityPhone, req.Remark, req.Logo)
+	err = commonservice.CreateAccount(ctx.MustGet("user_id").(uint), req.Type, req.Platform, req.PlatformURL, req.Username, req.Password, req.SecurityEmail, req.SecurityPhone, req.Remark, req.Logo)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -56,88 +53,146 @@ func CreateAccountHandler(ctx *gin.Context) {
 	})
 }
 
-// DeleteAccountHandler 删除账号记录
+// DeleteAccountHandler 删除账号记录，默认移入回收站
 func DeleteAccountHandler(ctx *gin.Context) {
 	type reqType struct {
 		AccountIDs []uint `json:"account_ids" binding:"required,min=1"`
+		HardDelete bool   `json:"hard_delete"` // 彻底删除，仅限回收站中的记录
 	}
 
 	var req reqType
 	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	var failedCount int
-	for _, id := range req.AccountIDs {
-		err = commonservice.DeleteAccount(id, false)
-		if err != nil {
-			failedCount++
-		}
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.AccountIDs), "[]"))
+
+	if req.HardDelete {
+		ctx.Set("audit_detail", "hard delete")
 	}
 
-	if failedCount > 0 {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_DELETE,
-			Info: "delete failed",
-		})
+	deleteAccount := func(userID, accountID uint) error {
+		return commonservice.DeleteAccount(userID, accountID, req.HardDelete)
+	}
+	applyRecordAction(ctx, req.AccountIDs, deleteAccount, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DELETE,
+		Info: "delete success",
+	}, errs.ErrFailedToDelete)
+}
+
+// FindTrashedAccountsHandler 获取回收站中的账号记录列表
+func FindTrashedAccountsHandler(ctx *gin.Context) {
+	var (
+		err  error
+		page int
+		size int
+	)
+
+	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
+		return
+	}
+	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
+		return
+	}
+
+	accounts, total, err := commonservice.FindTrashedAccounts(ctx.MustGet("user_id").(uint), page, size)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, systemmodel.Response{
-		Code: constant.SUCCESSFUL_DELETE,
-		Info: "delete success",
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"list":  accounts,
+			"total": total,
+		},
 	})
 }
 
-// UpdateAccountHandler 更新账号记录
-func UpdateAccountHandler(ctx *gin.Context) {
-	var rawData map[string]interface{}
-	err := ctx.ShouldBindBodyWithJSON(&rawData)
+// RestoreAccountsHandler 恢复回收站中的账号记录
+func RestoreAccountsHandler(ctx *gin.Context) {
+	type reqType struct {
+		AccountIDs []uint `json:"account_ids" binding:"required,min=1"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	accountIDFloat, ok := rawData["account_id"].(float64)
-	if !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
-		return
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.AccountIDs), "[]"))
+
+	applyRecordAction(ctx, req.AccountIDs, commonservice.RestoreAccount, systemmodel.Response{
+		Code: constant.SUCCESSFUL_RESTORE,
+		Info: "restore success",
+	}, errs.ErrFailedToRestore)
+}
+
+// PurgeAccountsHandler 彻底删除回收站中的账号记录
+func PurgeAccountsHandler(ctx *gin.Context) {
+	type reqType struct {
+		AccountIDs []uint `json:"account_ids" binding:"required,min=1"`
 	}
-	accountID := uint(accountIDFloat)
-	delete(rawData, "account_id")
 
-	if len(rawData) == 0 {
-		ctx.JSON(http.StatusOK, systemmodel.Response{
-			Code: constant.SUCCESSFUL_DELETE,
-			Info: "delete success",
-		})
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = commonservice.UpdateAccountFields(accountID, rawData)
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.AccountIDs), "[]"))
+
+	purgeAccount := func(userID, accountID uint) error {
+		return commonservice.DeleteAccount(userID, accountID, true)
+	}
+	applyRecordAction(ctx, req.AccountIDs, purgeAccount, systemmodel.Response{
+		Code: constant.SUCCESSFUL_PURGE,
+		Info: "purge success",
+	}, errs.ErrFailedToPurge)
+}
+
+// FindAccountHistoryHandler 获取账号记录的修订历史
+func FindAccountHistoryHandler(ctx *gin.Context) {
+	findRecordHistory(ctx, "account_id", commonservice.FindAccountHistory)
+}
+
+// RollbackAccountHandler 将账号记录回滚到指定修订之前的状态
+func RollbackAccountHandler(ctx *gin.Context) {
+	type reqType struct {
+		AccountID  uint `json:"account_id" binding:"required"`
+		RevisionID uint `json:"revision_id" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, systemmodel.Response{
-		Code: constant.SUCCESSFUL_UPDATE,
-		Info: "update success",
-	})
+	rollbackRecord(ctx, req.AccountID, req.RevisionID, commonservice.RollbackAccount)
+}
+
+// UpdateAccountHandler 按JSON Merge Patch语义更新账号记录，请求体中除 account_id 外的字段均作为补丁
+func UpdateAccountHandler(ctx *gin.Context) {
+	accountID, document, ok := bindRecordPatch(ctx, "account_id")
+	if !ok {
+		return
+	}
+
+	err := commonservice.PatchAccount(ctx.MustGet("user_id").(uint), accountID, document)
+	respondRecordPatch(ctx, err)
 }
 
 // FindAccountsHandler 搜索账号记录
@@ -152,27 +207,18 @@ func FindAccountsHandler(ctx *gin.Context) {
 	keyword = ctx.DefaultQuery("keyword", "")
 	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
 		return
 	}
 	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	accounts, total, err := commonservice.FindAccounts(keyword, page, size)
+	accounts, total, err := commonservice.FindAccounts(ctx.MustGet("user_id").(uint), keyword, page, size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -196,27 +242,18 @@ func FindAccountsListHandler(ctx *gin.Context) {
 
 	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
 		return
 	}
 	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	accounts, total, err := commonservice.FindAccountsList(page, size)
+	accounts, total, err := commonservice.FindAccountsList(ctx.MustGet("user_id").(uint), page, size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -230,96 +267,122 @@ func FindAccountsListHandler(ctx *gin.Context) {
 	})
 }
 
-// ExportAccountsCSVHandler 导出账号记录为CSV文件
-func ExportAccountsCSVHandler(ctx *gin.Context) {
-	filePath, err := commonservice.ExportAccountsCSV()
+// RevealAccountHandler 查看单条账号记录指定敏感字段的明文
+func RevealAccountHandler(ctx *gin.Context) {
+	type reqType struct {
+		AccountID uint   `json:"account_id" binding:"required"`
+		Field     string `json:"field" binding:"required"`
+		Password  string `json:"password"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_EXPORT,
-			Info: "export failed",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
-	defer os.Remove(filePath)
 
-	filename := filepath.Base(filePath)
-	ctx.Header("Content-Description", "File Transfer")
-	ctx.Header("Content-Transfer-Encoding", "binary")
-	ctx.Header("Content-Disposition", "attachment; filename="+filename)
-	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.AccountID), 10))
+	ctx.Set("audit_detail", "field "+req.Field)
 
-	ctx.File(filePath)
+	if !checkRevealReauth(ctx, req.Password, constant.PERM_ACCOUNTS_READ_PLAINTEXT) {
+		return
+	}
+
+	value, err := commonservice.RevealAccountField(ctx.MustGet("user_id").(uint), req.AccountID, req.Field)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_REVEAL,
+		Info: "reveal success",
+		Data: gin.H{
+			"field": req.Field,
+			"value": value,
+		},
+	})
+}
+
+// ExportAccountsHandler 以CSV、JSON Lines或XLSX格式流式导出账号记录
+func ExportAccountsHandler(ctx *gin.Context) {
+	streamExport(ctx, constant.RESOURCE_ACCOUNTS, constant.PERM_ACCOUNTS_READ_PLAINTEXT, commonservice.ExportAccounts)
 }
 
 // ImportAccountsCSVHandler 从CSV文件导入账号记录
 func ImportAccountsCSVHandler(ctx *gin.Context) {
 	file, err := ctx.FormFile("file")
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "required"))
 		return
 	}
 
 	ext := filepath.Ext(file.Filename)
 	if ext != ".csv" {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "unsupported file type"))
+		return
+	}
+
+	options, err := readImportOptions(ctx)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
 	}
 
 	tempDir := "temp"
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
 	tempFilePath := filepath.Join(tempDir, file.Filename)
 	err = ctx.SaveUploadedFile(file, tempFilePath)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
 	defer os.Remove(tempFilePath)
 
-	result, err := commonservice.ImportAccountsCSV(tempFilePath)
+	result, err := commonservice.ImportAccountsCSV(ctx.MustGet("user_id").(uint), tempFilePath, options)
+	if err != nil {
+		errs.AbortWith(ctx, err, errs.ErrFailedToImport)
+		return
+	}
+
+	respondImportResult(ctx, result)
+}
+
+// ImportAccountsKDBXHandler 从KeePass KDBX 4数据库导入账号记录，表单字段：file、password、keyfile（可选）、dry_run、all_or_nothing、conflict
+func ImportAccountsKDBXHandler(ctx *gin.Context) {
+	data, err := readImportFile(ctx, "file")
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_IMPORT,
-			Info: "import failed",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
-	if result.FailedCount > 0 {
-		ctx.AbortWithStatusJSON(http.StatusOK, systemmodel.Response{
-			Code: constant.FAILED_TO_IMPORT,
-			Info: "import failed",
-			Data: gin.H{
-				"success_count": result.SuccessCount,
-				"failed_count":  result.FailedCount,
-			},
-		})
+	options, err := readImportOptions(ctx)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
-	} else {
-		ctx.AbortWithStatusJSON(http.StatusOK, systemmodel.Response{
-			Code: constant.SUCCESSFUL_IMPORT,
-			Info: "import success",
-			Data: gin.H{
-				"success_count": result.SuccessCount,
-				"failed_count":  result.FailedCount,
-			},
-		})
+	}
+
+	var keyFile []byte
+	if _, err = ctx.FormFile("keyfile"); err == nil {
+		keyFile, err = readImportFile(ctx, "keyfile")
+		if err != nil {
+			errs.Abort(ctx, err)
+			return
+		}
+	}
+
+	result, err := commonservice.ImportAccountsKDBX(ctx.MustGet("user_id").(uint), data, ctx.PostForm("password"), keyFile, options)
+	if err != nil {
+		abortWithImportError(ctx, err)
 		return
 	}
+
+	ctx.Set("audit_detail", fmt.Sprintf("kdbx: %d imported, %d updated, %d failed, %d skipped", result.SuccessCount, result.UpdatedCount, result.FailedCount, result.SkippedCount))
+	respondImportResult(ctx, result)
 }
@@ -0,0 +1,96 @@
+package common
+
+import (
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/exporter"
+	"cyber-life/pkg/logger"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 16:20
+// @Desc:	记录导出的公共处理
+
+// exportFunc 按写入器的格式导出用户可见且匹配关键字的记录，返回导出的记录数
+type exportFunc func(userID uint, keyword string, writer exporter.Writer) (int, error)
+
+// exportRequest 导出请求参数
+type exportRequest struct {
+	Format          string `json:"format"`                                     // 导出格式：csv/jsonl/xlsx，默认csv
+	Keyword         string `json:"keyword"`                                    // 与列表查询含义一致的关键字
+	Password        string `json:"password"`                                   // 当前用户的登录口令，导出含敏感字段的记录时必填
+	ArchivePassword string `json:"archive_password" binding:"omitempty,min=8"` // 非空时导出为以该口令加密的ZIP压缩包
+}
+
+// streamExport 按请求参数将记录直接写入响应；plaintextPerm 非空时导出内容含密码等敏感字段，为查看明文所需的权限，须先重新校验登录口令
+func streamExport(ctx *gin.Context, resource, plaintextPerm string, export exportFunc) {
+	var req exportRequest
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	extension, ok := exporter.Extension(req.Format)
+	if !ok {
+		errs.Abort(ctx, errs.InvalidField("format", "unsupported export format"))
+		return
+	}
+
+	if plaintextPerm != "" && !checkReauth(ctx, req.Password, plaintextPerm) {
+		return
+	}
+
+	var output io.Writer = ctx.Writer
+	var archive io.WriteCloser
+	name := fmt.Sprintf("%s_%s", resource, time.Now().Format("20060102_150405"))
+	if req.ArchivePassword != "" {
+		archive, err = exporter.NewEncryptedArchive(ctx.Writer, name+"."+extension, req.ArchivePassword)
+		if err != nil {
+			errs.AbortWith(ctx, err, errs.ErrFailedToExport)
+			return
+		}
+		output = archive
+	}
+
+	writer, _ := exporter.NewWriter(req.Format, output)
+	filename, contentType := name+"."+extension, writer.ContentType()
+	if archive != nil {
+		filename, contentType = name+"."+exporter.ArchiveExtension, exporter.ArchiveContentType
+	}
+	ctx.Header("Content-Description", "File Transfer")
+	ctx.Header("Content-Transfer-Encoding", "binary")
+	ctx.Header("Content-Disposition", "attachment; filename="+filename)
+	ctx.Header("Content-Type", contentType)
+
+	count, err := export(ctx.MustGet("user_id").(uint), req.Keyword, writer)
+	if err == nil && archive != nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		if ctx.Writer.Written() {
+			// 响应已开始写出，只能中断传输，客户端将收到不完整的文件
+			logger.Error("an error occurred while streaming the export: ", err)
+			ctx.Set("audit_detail", fmt.Sprintf("%s: interrupted after %d exported", extension, count))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Writer.Header().Del("Content-Description")
+		ctx.Writer.Header().Del("Content-Transfer-Encoding")
+		ctx.Writer.Header().Del("Content-Disposition")
+		ctx.Writer.Header().Del("Content-Type")
+		errs.AbortWith(ctx, err, errs.ErrFailedToExport)
+		return
+	}
+
+	detail := fmt.Sprintf("%s: %d exported, keyword %q", extension, count, req.Keyword)
+	if archive != nil {
+		detail += ", encrypted archive"
+	}
+	ctx.Set("audit_detail", detail)
+}
@@ -2,12 +2,14 @@ package common
 
 import (
 	"cyber-life/internal/constant"
-	"encoding/json"
+	"cyber-life/internal/errs"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	systemmodel "cyber-life/internal/model/system"
 	commonservice "cyber-life/internal/service/common"
@@ -39,19 +41,13 @@ func CreateHostHandler(ctx *gin.Context) {
 	var req reqType
 	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = commonservice.CreateHost(req.Provider, req.ProviderURL, req.Hostname, req.Address, req.Ports, req.Username, req.Password, req.OS, req.Logo, req.CpuNum, req.RamSize, req.DiskSize, req.ExpirationTime)
+	err = commonservice.CreateHost(ctx.MustGet("user_id").(uint), req.Provider, req.ProviderURL, req.Hostname, req.Address, req.Ports, req.Username, req.Password, req.OS, req.Logo, req.CpuNum, req.RamSize, req.DiskSize, req.ExpirationTime)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -61,110 +57,147 @@ func CreateHostHandler(ctx *gin.Context) {
 	})
 }
 
-// DeleteHostHandler 删除主机记录
+// DeleteHostHandler 删除主机记录，默认移入回收站
 func DeleteHostHandler(ctx *gin.Context) {
 	type reqType struct {
-		HostIDs []uint `json:"host_ids" binding:"required,min=1"`
+		HostIDs    []uint `json:"host_ids" binding:"required,min=1"`
+		HardDelete bool   `json:"hard_delete"` // 彻底删除，仅限回收站中的记录
 	}
 
 	var req reqType
 	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
 	// 批量删除主机
-	var failedCount int
-	for _, id := range req.HostIDs {
-		err = commonservice.DeleteHost(id, false)
-		if err != nil {
-			failedCount++
-		}
-	}
-
-	if failedCount > 0 {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_DELETE,
-			Info: "delete failed",
-		})
-		return
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.HostIDs), "[]"))
+
+	if req.HardDelete {
+		ctx.Set("audit_detail", "hard delete")
 	}
 
-	ctx.JSON(http.StatusOK, systemmodel.Response{
+	deleteHost := func(userID, hostID uint) error {
+		return commonservice.DeleteHost(userID, hostID, req.HardDelete)
+	}
+	applyRecordAction(ctx, req.HostIDs, deleteHost, systemmodel.Response{
 		Code: constant.SUCCESSFUL_DELETE,
 		Info: "delete success",
-	})
+	}, errs.ErrFailedToDelete)
 }
 
-// UpdateHostHandler 更新主机记录
-func UpdateHostHandler(ctx *gin.Context) {
-	var rawData map[string]interface{}
-	err := ctx.ShouldBindBodyWithJSON(&rawData)
+// FindTrashedHostsHandler 获取回收站中的主机记录列表
+func FindTrashedHostsHandler(ctx *gin.Context) {
+	var (
+		err  error
+		page int
+		size int
+	)
+
+	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
+		return
+	}
+	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	// 提取host_id
-	hostIDFloat, ok := rawData["host_id"].(float64)
-	if !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+	hosts, total, err := commonservice.FindTrashedHosts(ctx.MustGet("user_id").(uint), page, size)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
 	}
-	hostID := uint(hostIDFloat)
-	delete(rawData, "host_id")
 
-	if len(rawData) == 0 {
-		ctx.JSON(http.StatusOK, systemmodel.Response{
-			Code: constant.SUCCESSFUL_DELETE,
-			Info: "delete success",
-		})
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"list":  hosts,
+			"total": total,
+		},
+	})
+}
+
+// RestoreHostsHandler 恢复回收站中的主机记录
+func RestoreHostsHandler(ctx *gin.Context) {
+	type reqType struct {
+		HostIDs []uint `json:"host_ids" binding:"required,min=1"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	if portsInterface, exists := rawData["ports"]; exists {
-		if portsMap, ok := portsInterface.(map[string]interface{}); ok {
-			convertedPorts := make(map[string]string)
-			for k, v := range portsMap {
-				if strValue, ok := v.(string); ok {
-					convertedPorts[k] = strValue
-				}
-			}
-			portsJSON, err := json.Marshal(convertedPorts)
-			if err != nil {
-				ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-					Code: constant.INVALID_REQUEST_PARAMS,
-					Info: "invalid ports format",
-				})
-				return
-			}
-			rawData["ports"] = string(portsJSON)
-		}
-	}
-
-	err = commonservice.UpdateHostFields(hostID, rawData)
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.HostIDs), "[]"))
+
+	applyRecordAction(ctx, req.HostIDs, commonservice.RestoreHost, systemmodel.Response{
+		Code: constant.SUCCESSFUL_RESTORE,
+		Info: "restore success",
+	}, errs.ErrFailedToRestore)
+}
+
+// PurgeHostsHandler 彻底删除回收站中的主机记录
+func PurgeHostsHandler(ctx *gin.Context) {
+	type reqType struct {
+		HostIDs []uint `json:"host_ids" binding:"required,min=1"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, systemmodel.Response{
-		Code: constant.SUCCESSFUL_UPDATE,
-		Info: "update success",
-	})
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.HostIDs), "[]"))
+
+	purgeHost := func(userID, hostID uint) error {
+		return commonservice.DeleteHost(userID, hostID, true)
+	}
+	applyRecordAction(ctx, req.HostIDs, purgeHost, systemmodel.Response{
+		Code: constant.SUCCESSFUL_PURGE,
+		Info: "purge success",
+	}, errs.ErrFailedToPurge)
+}
+
+// FindHostHistoryHandler 获取主机记录的修订历史
+func FindHostHistoryHandler(ctx *gin.Context) {
+	findRecordHistory(ctx, "host_id", commonservice.FindHostHistory)
+}
+
+// RollbackHostHandler 将主机记录回滚到指定修订之前的状态
+func RollbackHostHandler(ctx *gin.Context) {
+	type reqType struct {
+		HostID     uint `json:"host_id" binding:"required"`
+		RevisionID uint `json:"revision_id" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	rollbackRecord(ctx, req.HostID, req.RevisionID, commonservice.RollbackHost)
+}
+
+// UpdateHostHandler 按JSON Merge Patch语义更新主机记录，请求体中除 host_id 外的字段均作为补丁
+func UpdateHostHandler(ctx *gin.Context) {
+	hostID, document, ok := bindRecordPatch(ctx, "host_id")
+	if !ok {
+		return
+	}
+
+	err := commonservice.PatchHost(ctx.MustGet("user_id").(uint), hostID, document)
+	respondRecordPatch(ctx, err)
 }
 
 // FindHostsHandler 搜索主机记录
@@ -179,27 +212,18 @@ func FindHostsHandler(ctx *gin.Context) {
 	keyword = ctx.DefaultQuery("keyword", "")
 	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
 		return
 	}
 	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	hosts, total, err := commonservice.FindHosts(keyword, page, size)
+	hosts, total, err := commonservice.FindHosts(ctx.MustGet("user_id").(uint), keyword, page, size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -223,27 +247,18 @@ func FindHostsListHandler(ctx *gin.Context) {
 
 	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
 		return
 	}
 	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	hosts, total, err := commonservice.FindHostsList(page, size)
+	hosts, total, err := commonservice.FindHostsList(ctx.MustGet("user_id").(uint), page, size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -257,96 +272,89 @@ func FindHostsListHandler(ctx *gin.Context) {
 	})
 }
 
-// ExportHostsCSVHandler 导出主机记录为CSV文件
-func ExportHostsCSVHandler(ctx *gin.Context) {
-	filePath, err := commonservice.ExportHostsCSV()
+// RevealHostHandler 查看单条主机记录指定敏感字段的明文
+func RevealHostHandler(ctx *gin.Context) {
+	type reqType struct {
+		HostID   uint   `json:"host_id" binding:"required"`
+		Field    string `json:"field" binding:"required"`
+		Password string `json:"password"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_EXPORT,
-			Info: "export failed",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.HostID), 10))
+	ctx.Set("audit_detail", "field "+req.Field)
+
+	if !checkRevealReauth(ctx, req.Password, constant.PERM_HOSTS_READ_PLAINTEXT) {
 		return
 	}
-	defer os.Remove(filePath)
 
-	filename := filepath.Base(filePath)
-	ctx.Header("Content-Description", "File Transfer")
-	ctx.Header("Content-Transfer-Encoding", "binary")
-	ctx.Header("Content-Disposition", "attachment; filename="+filename)
-	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	value, err := commonservice.RevealHostField(ctx.MustGet("user_id").(uint), req.HostID, req.Field)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
 
-	ctx.File(filePath)
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_REVEAL,
+		Info: "reveal success",
+		Data: gin.H{
+			"field": req.Field,
+			"value": value,
+		},
+	})
+}
+
+// ExportHostsHandler 以CSV、JSON Lines或XLSX格式流式导出主机记录
+func ExportHostsHandler(ctx *gin.Context) {
+	streamExport(ctx, constant.RESOURCE_HOSTS, constant.PERM_HOSTS_READ_PLAINTEXT, commonservice.ExportHosts)
 }
 
 // ImportHostsCSVHandler 从CSV文件导入主机记录
 func ImportHostsCSVHandler(ctx *gin.Context) {
 	file, err := ctx.FormFile("file")
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "required"))
 		return
 	}
 
 	ext := filepath.Ext(file.Filename)
 	if ext != ".csv" {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "unsupported file type"))
+		return
+	}
+
+	options, err := readImportOptions(ctx)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
 	}
 
 	tempDir := "temp"
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
 	tempFilePath := filepath.Join(tempDir, file.Filename)
 	err = ctx.SaveUploadedFile(file, tempFilePath)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
 	defer os.Remove(tempFilePath)
 
-	result, err := commonservice.ImportHostsCSV(tempFilePath)
+	result, err := commonservice.ImportHostsCSV(ctx.MustGet("user_id").(uint), tempFilePath, options)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_IMPORT,
-			Info: "import failed",
-		})
+		errs.AbortWith(ctx, err, errs.ErrFailedToImport)
 		return
 	}
 
-	if result.FailedCount > 0 {
-		ctx.AbortWithStatusJSON(http.StatusOK, systemmodel.Response{
-			Code: constant.FAILED_TO_IMPORT,
-			Info: "import failed",
-			Data: gin.H{
-				"success_count": result.SuccessCount,
-				"failed_count":  result.FailedCount,
-			},
-		})
-		return
-	} else {
-		ctx.AbortWithStatusJSON(http.StatusOK, systemmodel.Response{
-			Code: constant.SUCCESSFUL_IMPORT,
-			Info: "import success",
-			Data: gin.H{
-				"success_count": result.SuccessCount,
-				"failed_count":  result.FailedCount,
-			},
-		})
-		return
-	}
+	respondImportResult(ctx, result)
 }
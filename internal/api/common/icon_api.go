@@ -2,6 +2,7 @@ package common
 
 import (
 	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
 	"github.com/gin-gonic/gin"
 	"net/http"
 
@@ -18,29 +19,20 @@ import (
 func UploadPlatformIconHandler(ctx *gin.Context) {
 	platform := ctx.PostForm("platform")
 	if platform == "" {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("platform", "required"))
 		return
 	}
 
 	file, err := ctx.FormFile("file")
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "required"))
 		return
 	}
 
 	iconsDir := "data/platform_icons"
 	filename, err := commonservice.UploadIcon(platform, iconsDir, file)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -59,10 +51,7 @@ func GetPlatformIconsListHandler(ctx *gin.Context) {
 
 	icons, err := commonservice.GetIconsList(iconsDir)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -79,29 +68,20 @@ func GetPlatformIconsListHandler(ctx *gin.Context) {
 func UploadOSIconHandler(ctx *gin.Context) {
 	osName := ctx.PostForm("os")
 	if osName == "" {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("os", "required"))
 		return
 	}
 
 	file, err := ctx.FormFile("file")
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "required"))
 		return
 	}
 
 	osIconsDir := "data/os_icons"
 	filename, err := commonservice.UploadIcon(osName, osIconsDir, file)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -120,10 +100,7 @@ func GetOSIconsListHandler(ctx *gin.Context) {
 
 	icons, err := commonservice.GetIconsList(osIconsDir)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -140,29 +117,20 @@ func GetOSIconsListHandler(ctx *gin.Context) {
 func UploadSiteIconHandler(ctx *gin.Context) {
 	siteName := ctx.PostForm("site")
 	if siteName == "" {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("site", "required"))
 		return
 	}
 
 	file, err := ctx.FormFile("file")
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "required"))
 		return
 	}
 
 	iconsDir := "data/site_icons"
 	filename, err := commonservice.UploadIcon(siteName, iconsDir, file)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -181,10 +149,7 @@ func GetSiteIconsListHandler(ctx *gin.Context) {
 
 	icons, err := commonservice.GetIconsList(iconsDir)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -0,0 +1,182 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/importer"
+	"cyber-life/pkg/kdbx"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+
+	commonmodel "cyber-life/internal/model/common"
+	systemmodel "cyber-life/internal/model/system"
+	commonservice "cyber-life/internal/service/common"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 09:20
+// @Desc:	第三方密码库导入的公共处理
+
+// maxImportFileSize 导入文件的大小上限
+const maxImportFileSize = 32 << 20
+
+// readImportFile 读取上传的导入文件，字段缺失、文件过大或无法读取时返回该字段的参数错误
+func readImportFile(ctx *gin.Context, field string) ([]byte, error) {
+	header, err := ctx.FormFile(field)
+	if err != nil {
+		return nil, errs.InvalidField(field, "required").Wrap(err)
+	}
+	if header.Size > maxImportFileSize {
+		return nil, errs.InvalidField(field, "the import file is too large")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, errs.InvalidField(field, "unreadable file").Wrap(err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		return nil, errs.InvalidField(field, "unreadable file").Wrap(err)
+	}
+	return data, nil
+}
+
+// readImportOptions 读取导入选项，表单字段：dry_run（仅校验不写入）、all_or_nothing（任一记录失败时不导入任何记录）、
+// conflict（与已有记录冲突时的处理方式：skip/overwrite/keep_both/merge，缺省为 skip）
+func readImportOptions(ctx *gin.Context) (commonmodel.ImportOptions, error) {
+	var (
+		options = commonmodel.ImportOptions{Conflict: ctx.DefaultPostForm("conflict", constant.IMPORT_CONFLICT_SKIP)}
+		err     error
+	)
+	if !constant.ImportConflictStrategies[options.Conflict] {
+		return options, errs.InvalidField("conflict", "invalid conflict strategy")
+	}
+	if value := ctx.PostForm("dry_run"); value != "" {
+		options.DryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return options, errs.InvalidField("dry_run", "invalid value").Wrap(err)
+		}
+	}
+	if value := ctx.PostForm("all_or_nothing"); value != "" {
+		options.AllOrNothing, err = strconv.ParseBool(value)
+		if err != nil {
+			return options, errs.InvalidField("all_or_nothing", "invalid value").Wrap(err)
+		}
+	}
+	return options, nil
+}
+
+// importPermissions 各导入类别所需的权限
+var importPermissions = map[importer.Kind]string{
+	importer.KindAccount: constant.PERM_ACCOUNTS_IMPORT,
+	importer.KindSecret:  constant.PERM_SECRETS_IMPORT,
+	importer.KindHost:    constant.PERM_HOSTS_IMPORT,
+}
+
+// ImportRecordsHandler 从第三方密码库的导出文件导入记录，登录项导入为账号，API密钥类条目导入为密钥，服务器条目导入为主机；
+// 表单字段：file、format（可选，缺省时自动识别）、password（加密导出的口令或主密码）、keyfile（可选）、dry_run、all_or_nothing、conflict
+func ImportRecordsHandler(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(uint)
+
+	// 至少具备一种记录的导入权限，无权导入的记录在明细中记为失败
+	allowed := make(map[importer.Kind]bool)
+	for kind, perm := range importPermissions {
+		granted, err := systemservice.UserHasPermissions(userID, perm)
+		if err != nil {
+			errs.Abort(ctx, err)
+			return
+		}
+		if scopes, ok := ctx.Get("access_token_scopes"); ok {
+			granted = granted && systemservice.AccessTokenScopesGrant(scopes.([]string), perm)
+		}
+		allowed[kind] = granted
+	}
+	if !allowed[importer.KindAccount] && !allowed[importer.KindSecret] && !allowed[importer.KindHost] {
+		errs.Abort(ctx, errs.ErrPermissionDenied)
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("file", "required"))
+		return
+	}
+	data, err := readImportFile(ctx, "file")
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	options, err := readImportOptions(ctx)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	parseOptions := importer.Options{Password: ctx.PostForm("password")}
+	if _, err = ctx.FormFile("keyfile"); err == nil {
+		parseOptions.KeyFile, err = readImportFile(ctx, "keyfile")
+		if err != nil {
+			errs.Abort(ctx, err)
+			return
+		}
+	}
+
+	result, err := commonservice.ImportRecords(userID, ctx.PostForm("format"), header.Filename, data, parseOptions, allowed, options)
+	if err != nil {
+		abortWithImportError(ctx, err)
+		return
+	}
+
+	ctx.Set("audit_detail", fmt.Sprintf("%s: %d imported, %d updated, %d failed, %d skipped", result.Format, result.SuccessCount, result.UpdatedCount, result.FailedCount, result.SkippedCount))
+	respondImportResult(ctx, result)
+}
+
+// abortWithImportError 按导入失败原因返回错误响应
+func abortWithImportError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, kdbx.ErrInvalidCredentials), errors.Is(err, kdbx.ErrInvalidKeyFile),
+		errors.Is(err, importer.ErrPasswordRequired), errors.Is(err, importer.ErrInvalidPassword):
+		errs.Abort(ctx, errs.ErrInvalidImportKey.Wrap(err))
+	case errors.Is(err, kdbx.ErrInvalidFile), errors.Is(err, kdbx.ErrUnsupportedVersion), errors.Is(err, kdbx.ErrUnsupportedCipher),
+		errors.Is(err, kdbx.ErrUnsupportedKdf), errors.Is(err, kdbx.ErrCorrupted),
+		errors.Is(err, importer.ErrUnknownFormat), errors.Is(err, importer.ErrInvalidFile), errors.Is(err, importer.ErrUnsupportedEncrypt):
+		errs.Abort(ctx, errs.ErrInvalidImportFile.WithData(gin.H{"reason": err.Error()}).Wrap(err))
+	default:
+		errs.AbortWith(ctx, err, errs.ErrFailedToImport)
+	}
+}
+
+// respondImportResult 返回导入结果，存在失败记录时返回 FAILED_TO_IMPORT
+func respondImportResult(ctx *gin.Context, result *commonmodel.ImportResult) {
+	code, info := constant.SUCCESSFUL_IMPORT, "import success"
+	if result.FailedCount > 0 {
+		code, info = constant.FAILED_TO_IMPORT, "import failed"
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: code,
+		Info: info,
+		Data: gin.H{
+			"format":         result.Format,
+			"dry_run":        result.DryRun,
+			"all_or_nothing": result.AllOrNothing,
+			"conflict":       result.Conflict,
+			"rolled_back":    result.RolledBack,
+			"total":          result.Total,
+			"success_count":  result.SuccessCount,
+			"updated_count":  result.UpdatedCount,
+			"failed_count":   result.FailedCount,
+			"skipped_count":  result.SkippedCount,
+			"items":          result.Items,
+		},
+	})
+}
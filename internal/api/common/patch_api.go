@@ -0,0 +1,60 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/patch"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 21:40
+// @Desc:	记录补丁更新的公共处理
+
+// bindRecordPatch 解析补丁请求：请求体为JSON对象，记录ID取自字段 idKey，其余字段作为补丁；解析失败时写入错误响应并返回false
+func bindRecordPatch(ctx *gin.Context, idKey string) (uint, map[string]json.RawMessage, bool) {
+	var document map[string]json.RawMessage
+	err := ctx.ShouldBindBodyWithJSON(&document)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return 0, nil, false
+	}
+
+	var recordID uint
+	err = json.Unmarshal(document[idKey], &recordID)
+	if err != nil || recordID == 0 {
+		errs.Abort(ctx, errs.InvalidField(idKey, "required"))
+		return 0, nil, false
+	}
+	delete(document, idKey)
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(recordID), 10))
+
+	return recordID, document, true
+}
+
+// respondRecordPatch 将补丁更新的结果写入响应，字段校验失败时附带每个字段的错误
+func respondRecordPatch(ctx *gin.Context, err error) {
+	var fieldErrors patch.Errors
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, systemmodel.Response{
+			Code: constant.SUCCESSFUL_UPDATE,
+			Info: "update success",
+		})
+	case errors.As(err, &fieldErrors):
+		fields := make([]systemmodel.FieldError, len(fieldErrors))
+		for index, fieldError := range fieldErrors {
+			fields[index] = errs.Field(fieldError.Field, fieldError.Error)
+		}
+		errs.Abort(ctx, errs.ErrInvalidParams.WithFields(fields...))
+	default:
+		errs.Abort(ctx, err)
+	}
+}
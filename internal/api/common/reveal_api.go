@@ -0,0 +1,65 @@
+package common
+
+import (
+	"cyber-life/internal/core/config"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 21:05
+// @Desc:	敏感字段查看公共逻辑
+
+// checkRevealReauth 按配置要求在查看明文前重新校验登录口令，perm 为查看明文所需的权限，校验未通过时写入错误响应并返回false
+func checkRevealReauth(ctx *gin.Context, password, perm string) bool {
+	if !config.Config.Reveal.RequirePassword {
+		return checkTokenPlaintextScope(ctx, perm)
+	}
+
+	return checkReauth(ctx, password, perm)
+}
+
+// checkReauth 重新校验当前用户的登录口令，perm 为查看明文所需的权限，校验未通过时写入错误响应并返回false
+func checkReauth(ctx *gin.Context, password, perm string) bool {
+	// 个人访问令牌供无人值守的自动化任务使用，无法输入口令，改为要求令牌的权限范围显式授予查看明文的权限
+	if _, ok := ctx.Get("access_token_scopes"); ok {
+		return checkTokenPlaintextScope(ctx, perm)
+	}
+
+	if password == "" {
+		errs.Abort(ctx, errs.ErrReauthRequired)
+		return false
+	}
+
+	user, err := systemservice.FindUserByID(ctx.MustGet("user_id").(uint))
+	if err != nil {
+		errs.Abort(ctx, err)
+		return false
+	}
+
+	ok, err := systemservice.VerifyUserPassword(user, password)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return false
+	}
+	if !ok {
+		errs.Abort(ctx, errs.ErrReauthFailed)
+		return false
+	}
+
+	return true
+}
+
+// checkTokenPlaintextScope 使用个人访问令牌时要求权限范围包含查看明文的权限，未包含时写入错误响应并返回false；非令牌请求直接通过
+func checkTokenPlaintextScope(ctx *gin.Context, perm string) bool {
+	scopes, ok := ctx.Get("access_token_scopes")
+	if !ok || systemservice.AccessTokenScopesGrant(scopes.([]string), perm) {
+		return true
+	}
+
+	errs.Abort(ctx, errs.ErrPermissionDenied)
+	return false
+}
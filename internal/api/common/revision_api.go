@@ -0,0 +1,70 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+
+	commonmodel "cyber-life/internal/model/common"
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 20:50
+// @Desc:	记录修订历史与回滚的公共处理
+
+// findRecordHistory 按查询参数中的记录ID（参数名为 idKey）及分页参数查询记录的修订历史并写入响应
+func findRecordHistory(ctx *gin.Context, idKey string, find func(userID, recordID uint, page, size int) ([]commonmodel.Revision, int64, error)) {
+	recordID, err := strconv.ParseUint(ctx.Query(idKey), 10, 0)
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField(idKey, "invalid value"))
+		return
+	}
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
+		return
+	}
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", "10"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(recordID, 10))
+
+	revisions, total, err := find(ctx.MustGet("user_id").(uint), uint(recordID), page, size)
+	if err != nil {
+		errs.AbortWith(ctx, err, errs.ErrFailedToFind)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"list":  revisions,
+			"total": total,
+		},
+	})
+}
+
+// rollbackRecord 将记录回滚到指定修订之前的状态并写入响应
+func rollbackRecord(ctx *gin.Context, recordID, revisionID uint, rollback func(userID, recordID, revisionID uint) error) {
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(recordID), 10))
+	ctx.Set("audit_detail", "revision "+strconv.FormatUint(uint64(revisionID), 10))
+
+	err := rollback(ctx.MustGet("user_id").(uint), recordID, revisionID)
+	if err != nil {
+		errs.AbortWith(ctx, err, errs.ErrFailedToRollback)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_ROLLBACK,
+		Info: "rollback success",
+	})
+}
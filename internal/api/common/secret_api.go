@@ -2,11 +2,14 @@ package common
 
 import (
 	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	systemmodel "cyber-life/internal/model/system"
 	commonservice "cyber-life/internal/service/common"
@@ -31,19 +34,13 @@ func CreateSecretHandler(ctx *gin.Context) {
 	var req reqType
 	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = commonservice.CreateSecret(req.Platform, req.PlatformURL, req.KeyID, req.KeySecret, req.Remark, req.Logo)
+	err = commonservice.CreateSecret(ctx.MustGet("user_id").(uint), req.Platform, req.PlatformURL, req.KeyID, req.KeySecret, req.Remark, req.Logo)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -53,89 +50,147 @@ func CreateSecretHandler(ctx *gin.Context) {
 	})
 }
 
-// DeleteSecretHandler 删除密钥记录
+// DeleteSecretHandler 删除密钥记录，默认移入回收站
 func DeleteSecretHandler(ctx *gin.Context) {
 	type reqType struct {
-		SecretIDs []uint `json:"secret_ids" binding:"required,min=1"`
+		SecretIDs  []uint `json:"secret_ids" binding:"required,min=1"`
+		HardDelete bool   `json:"hard_delete"` // 彻底删除，仅限回收站中的记录
 	}
 
 	var req reqType
 	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
 	// 批量删除密钥
-	var failedCount int
-	for _, id := range req.SecretIDs {
-		err = commonservice.DeleteSecret(id, false)
-		if err != nil {
-			failedCount++
-		}
-	}
-
-	if failedCount > 0 {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_DELETE,
-			Info: "delete failed",
-		})
-		return
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.SecretIDs), "[]"))
+
+	if req.HardDelete {
+		ctx.Set("audit_detail", "hard delete")
 	}
 
-	ctx.JSON(http.StatusOK, systemmodel.Response{
+	deleteSecret := func(userID, secretID uint) error {
+		return commonservice.DeleteSecret(userID, secretID, req.HardDelete)
+	}
+	applyRecordAction(ctx, req.SecretIDs, deleteSecret, systemmodel.Response{
 		Code: constant.SUCCESSFUL_DELETE,
 		Info: "delete success",
-	})
+	}, errs.ErrFailedToDelete)
 }
 
-// UpdateSecretHandler 更新密钥记录
-func UpdateSecretHandler(ctx *gin.Context) {
-	var rawData map[string]interface{}
-	err := ctx.ShouldBindBodyWithJSON(&rawData)
+// FindTrashedSecretsHandler 获取回收站中的密钥记录列表
+func FindTrashedSecretsHandler(ctx *gin.Context) {
+	var (
+		err  error
+		page int
+		size int
+	)
+
+	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
+		return
+	}
+	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	secretIDFloat, ok := rawData["secret_id"].(float64)
-	if !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+	secrets, total, err := commonservice.FindTrashedSecrets(ctx.MustGet("user_id").(uint), page, size)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
 	}
-	secretID := uint(secretIDFloat)
-	delete(rawData, "secret_id")
 
-	if len(rawData) == 0 {
-		ctx.JSON(http.StatusOK, systemmodel.Response{
-			Code: constant.SUCCESSFUL_DELETE,
-			Info: "delete success",
-		})
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"list":  secrets,
+			"total": total,
+		},
+	})
+}
+
+// RestoreSecretsHandler 恢复回收站中的密钥记录
+func RestoreSecretsHandler(ctx *gin.Context) {
+	type reqType struct {
+		SecretIDs []uint `json:"secret_ids" binding:"required,min=1"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = commonservice.UpdateSecretFields(secretID, rawData)
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.SecretIDs), "[]"))
+
+	applyRecordAction(ctx, req.SecretIDs, commonservice.RestoreSecret, systemmodel.Response{
+		Code: constant.SUCCESSFUL_RESTORE,
+		Info: "restore success",
+	}, errs.ErrFailedToRestore)
+}
+
+// PurgeSecretsHandler 彻底删除回收站中的密钥记录
+func PurgeSecretsHandler(ctx *gin.Context) {
+	type reqType struct {
+		SecretIDs []uint `json:"secret_ids" binding:"required,min=1"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, systemmodel.Response{
-		Code: constant.SUCCESSFUL_UPDATE,
-		Info: "update success",
-	})
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.SecretIDs), "[]"))
+
+	purgeSecret := func(userID, secretID uint) error {
+		return commonservice.DeleteSecret(userID, secretID, true)
+	}
+	applyRecordAction(ctx, req.SecretIDs, purgeSecret, systemmodel.Response{
+		Code: constant.SUCCESSFUL_PURGE,
+		Info: "purge success",
+	}, errs.ErrFailedToPurge)
+}
+
+// FindSecretHistoryHandler 获取密钥记录的修订历史
+func FindSecretHistoryHandler(ctx *gin.Context) {
+	findRecordHistory(ctx, "secret_id", commonservice.FindSecretHistory)
+}
+
+// RollbackSecretHandler 将密钥记录回滚到指定修订之前的状态
+func RollbackSecretHandler(ctx *gin.Context) {
+	type reqType struct {
+		SecretID   uint `json:"secret_id" binding:"required"`
+		RevisionID uint `json:"revision_id" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	rollbackRecord(ctx, req.SecretID, req.RevisionID, commonservice.RollbackSecret)
+}
+
+// UpdateSecretHandler 按JSON Merge Patch语义更新密钥记录，请求体中除 secret_id 外的字段均作为补丁
+func UpdateSecretHandler(ctx *gin.Context) {
+	secretID, document, ok := bindRecordPatch(ctx, "secret_id")
+	if !ok {
+		return
+	}
+
+	err := commonservice.PatchSecret(ctx.MustGet("user_id").(uint), secretID, document)
+	respondRecordPatch(ctx, err)
 }
 
 // FindSecretsHandler 搜索密钥记录
@@ -150,27 +205,18 @@ func FindSecretsHandler(ctx *gin.Context) {
 	keyword = ctx.DefaultQuery("keyword", "")
 	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
 		return
 	}
 	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	secrets, total, err := commonservice.FindSecrets(keyword, page, size)
+	secrets, total, err := commonservice.FindSecrets(ctx.MustGet("user_id").(uint), keyword, page, size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -194,27 +240,18 @@ func FindSecretsListHandler(ctx *gin.Context) {
 
 	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
 		return
 	}
 	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	secrets, total, err := commonservice.FindSecretsList(page, size)
+	secrets, total, err := commonservice.FindSecretsList(ctx.MustGet("user_id").(uint), page, size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -228,96 +265,89 @@ func FindSecretsListHandler(ctx *gin.Context) {
 	})
 }
 
-// ExportSecretsCSVHandler 导出密钥记录为CSV文件
-func ExportSecretsCSVHandler(ctx *gin.Context) {
-	filePath, err := commonservice.ExportSecretsCSV()
+// RevealSecretHandler 查看单条密钥记录指定敏感字段的明文
+func RevealSecretHandler(ctx *gin.Context) {
+	type reqType struct {
+		SecretID uint   `json:"secret_id" binding:"required"`
+		Field    string `json:"field" binding:"required"`
+		Password string `json:"password"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_EXPORT,
-			Info: "export failed",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.SecretID), 10))
+	ctx.Set("audit_detail", "field "+req.Field)
+
+	if !checkRevealReauth(ctx, req.Password, constant.PERM_SECRETS_READ_PLAINTEXT) {
 		return
 	}
-	defer os.Remove(filePath)
 
-	filename := filepath.Base(filePath)
-	ctx.Header("Content-Description", "File Transfer")
-	ctx.Header("Content-Transfer-Encoding", "binary")
-	ctx.Header("Content-Disposition", "attachment; filename="+filename)
-	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	value, err := commonservice.RevealSecretField(ctx.MustGet("user_id").(uint), req.SecretID, req.Field)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
 
-	ctx.File(filePath)
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_REVEAL,
+		Info: "reveal success",
+		Data: gin.H{
+			"field": req.Field,
+			"value": value,
+		},
+	})
+}
+
+// ExportSecretsHandler 以CSV、JSON Lines或XLSX格式流式导出密钥记录
+func ExportSecretsHandler(ctx *gin.Context) {
+	streamExport(ctx, constant.RESOURCE_SECRETS, constant.PERM_SECRETS_READ_PLAINTEXT, commonservice.ExportSecrets)
 }
 
 // ImportSecretsCSVHandler 从CSV文件导入密钥记录
 func ImportSecretsCSVHandler(ctx *gin.Context) {
 	file, err := ctx.FormFile("file")
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "required"))
 		return
 	}
 
 	ext := filepath.Ext(file.Filename)
 	if ext != ".csv" {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "unsupported file type"))
+		return
+	}
+
+	options, err := readImportOptions(ctx)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
 	}
 
 	tempDir := "temp"
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
 	tempFilePath := filepath.Join(tempDir, file.Filename)
 	err = ctx.SaveUploadedFile(file, tempFilePath)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
 	defer os.Remove(tempFilePath)
 
-	result, err := commonservice.ImportSecretsCSV(tempFilePath)
+	result, err := commonservice.ImportSecretsCSV(ctx.MustGet("user_id").(uint), tempFilePath, options)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_IMPORT,
-			Info: "import failed",
-		})
+		errs.AbortWith(ctx, err, errs.ErrFailedToImport)
 		return
 	}
 
-	if result.FailedCount > 0 {
-		ctx.AbortWithStatusJSON(http.StatusOK, systemmodel.Response{
-			Code: constant.FAILED_TO_IMPORT,
-			Info: "import failed",
-			Data: gin.H{
-				"success_count": result.SuccessCount,
-				"failed_count":  result.FailedCount,
-			},
-		})
-		return
-	} else {
-		ctx.AbortWithStatusJSON(http.StatusOK, systemmodel.Response{
-			Code: constant.SUCCESSFUL_IMPORT,
-			Info: "import success",
-			Data: gin.H{
-				"success_count": result.SuccessCount,
-				"failed_count":  result.FailedCount,
-			},
-		})
-		return
-	}
+	respondImportResult(ctx, result)
 }
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+
+	systemmodel "cyber-life/internal/model/system"
+	commonservice "cyber-life/internal/service/common"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 19:45
+// @Desc:	记录共享接口
+
+// CreateShareHandler 将记录共享给用户或用户组
+func CreateShareHandler(ctx *gin.Context) {
+	type reqType struct {
+		ResourceType string `json:"resource_type" binding:"required"`
+		ResourceID   uint   `json:"resource_id" binding:"required"`
+		GranteeType  string `json:"grantee_type" binding:"required"`
+		GranteeID    uint   `json:"grantee_id" binding:"required"`
+		Access       string `json:"access" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_detail", fmt.Sprintf("%s %d -> %s %d (%s)", req.ResourceType, req.ResourceID, req.GranteeType, req.GranteeID, req.Access))
+	share, err := commonservice.CreateShare(ctx.MustGet("user_id").(uint), req.ResourceType, req.ResourceID, req.GranteeType, req.GranteeID, req.Access)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(share.ID), 10))
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_CREATE,
+		Info: "create success",
+		Data: gin.H{
+			"share": share,
+		},
+	})
+}
+
+// DeleteShareHandler 撤销共享授权
+func DeleteShareHandler(ctx *gin.Context) {
+	type reqType struct {
+		ShareID uint `json:"share_id" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.ShareID), 10))
+	err = commonservice.DeleteShare(ctx.MustGet("user_id").(uint), req.ShareID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DELETE,
+		Info: "delete success",
+	})
+}
+
+// FindRecordSharesHandler 查询记录的全部共享授权
+func FindRecordSharesHandler(ctx *gin.Context) {
+	resourceID, err := strconv.Atoi(ctx.Query("resource_id"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("resource_id", "invalid value"))
+		return
+	}
+
+	shares, err := commonservice.FindRecordShares(ctx.MustGet("user_id").(uint), ctx.Query("resource_type"), uint(resourceID))
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"list":  shares,
+			"total": len(shares),
+		},
+	})
+}
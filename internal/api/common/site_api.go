@@ -2,11 +2,14 @@ package common
 
 import (
 	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	systemmodel "cyber-life/internal/model/system"
 	commonservice "cyber-life/internal/service/common"
@@ -28,19 +31,13 @@ func CreateSiteHandler(ctx *gin.Context) {
 	var req reqType
 	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = commonservice.CreateSite(req.Name, req.Logo, req.URL)
+	err = commonservice.CreateSite(ctx.MustGet("user_id").(uint), req.Name, req.Logo, req.URL)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -50,89 +47,124 @@ func CreateSiteHandler(ctx *gin.Context) {
 	})
 }
 
-// DeleteSiteHandler 删除站点记录
+// DeleteSiteHandler 删除站点记录，默认移入回收站
 func DeleteSiteHandler(ctx *gin.Context) {
 	type reqType struct {
-		SiteIDs []uint `json:"site_ids" binding:"required,min=1"`
+		SiteIDs    []uint `json:"site_ids" binding:"required,min=1"`
+		HardDelete bool   `json:"hard_delete"` // 彻底删除，仅限回收站中的记录
 	}
 
 	var req reqType
 	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	var failedCount int
-	for _, id := range req.SiteIDs {
-		err = commonservice.DeleteSite(id, false)
-		if err != nil {
-			failedCount++
-		}
-	}
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.SiteIDs), "[]"))
 
-	if failedCount > 0 {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_DELETE,
-			Info: "delete failed",
-		})
-		return
+	if req.HardDelete {
+		ctx.Set("audit_detail", "hard delete")
 	}
 
-	ctx.JSON(http.StatusOK, systemmodel.Response{
+	deleteSite := func(userID, siteID uint) error {
+		return commonservice.DeleteSite(userID, siteID, req.HardDelete)
+	}
+	applyRecordAction(ctx, req.SiteIDs, deleteSite, systemmodel.Response{
 		Code: constant.SUCCESSFUL_DELETE,
 		Info: "delete success",
-	})
+	}, errs.ErrFailedToDelete)
 }
 
-// UpdateSiteHandler 更新站点记录
-func UpdateSiteHandler(ctx *gin.Context) {
+// FindTrashedSitesHandler 获取回收站中的站点记录列表
+func FindTrashedSitesHandler(ctx *gin.Context) {
+	var (
+		err  error
+		page int
+		size int
+	)
 
-	var rawData map[string]interface{}
-	err := ctx.ShouldBindBodyWithJSON(&rawData)
+	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
+		return
+	}
+	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	siteIDFloat, ok := rawData["site_id"].(float64)
-	if !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+	sites, total, err := commonservice.FindTrashedSites(ctx.MustGet("user_id").(uint), page, size)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
 	}
-	siteID := uint(siteIDFloat)
-	delete(rawData, "site_id")
-
-	if len(rawData) == 0 {
-		ctx.JSON(http.StatusOK, systemmodel.Response{
-			Code: constant.SUCCESSFUL_DELETE,
-			Info: "delete success",
-		})
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"list":  sites,
+			"total": total,
+		},
+	})
+}
+
+// RestoreSitesHandler 恢复回收站中的站点记录
+func RestoreSitesHandler(ctx *gin.Context) {
+	type reqType struct {
+		SiteIDs []uint `json:"site_ids" binding:"required,min=1"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = commonservice.UpdateSiteFields(siteID, rawData)
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.SiteIDs), "[]"))
+
+	applyRecordAction(ctx, req.SiteIDs, commonservice.RestoreSite, systemmodel.Response{
+		Code: constant.SUCCESSFUL_RESTORE,
+		Info: "restore success",
+	}, errs.ErrFailedToRestore)
+}
+
+// PurgeSitesHandler 彻底删除回收站中的站点记录
+func PurgeSitesHandler(ctx *gin.Context) {
+	type reqType struct {
+		SiteIDs []uint `json:"site_ids" binding:"required,min=1"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, systemmodel.Response{
-		Code: constant.SUCCESSFUL_UPDATE,
-		Info: "update success",
-	})
+	ctx.Set("audit_resource_id", strings.Trim(fmt.Sprint(req.SiteIDs), "[]"))
+
+	purgeSite := func(userID, siteID uint) error {
+		return commonservice.DeleteSite(userID, siteID, true)
+	}
+	applyRecordAction(ctx, req.SiteIDs, purgeSite, systemmodel.Response{
+		Code: constant.SUCCESSFUL_PURGE,
+		Info: "purge success",
+	}, errs.ErrFailedToPurge)
+}
+
+// UpdateSiteHandler 按JSON Merge Patch语义更新站点记录，请求体中除 site_id 外的字段均作为补丁
+func UpdateSiteHandler(ctx *gin.Context) {
+	siteID, document, ok := bindRecordPatch(ctx, "site_id")
+	if !ok {
+		return
+	}
+
+	err := commonservice.PatchSite(ctx.MustGet("user_id").(uint), siteID, document)
+	respondRecordPatch(ctx, err)
 }
 
 // FindSitesHandler 搜索站点记录
@@ -147,27 +179,18 @@ func FindSitesHandler(ctx *gin.Context) {
 	keyword = ctx.DefaultQuery("keyword", "")
 	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
 		return
 	}
 	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	sites, total, err := commonservice.FindSites(keyword, page, size)
+	sites, total, err := commonservice.FindSites(ctx.MustGet("user_id").(uint), keyword, page, size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -191,27 +214,18 @@ func FindSitesListHandler(ctx *gin.Context) {
 
 	page, err = strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
 		return
 	}
 	size, err = strconv.Atoi(ctx.DefaultQuery("size", "10"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
-	sites, total, err := commonservice.FindSitesList(page, size)
+	sites, total, err := commonservice.FindSitesList(ctx.MustGet("user_id").(uint), page, size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -225,96 +239,51 @@ func FindSitesListHandler(ctx *gin.Context) {
 	})
 }
 
-// ExportSitesCSVHandler 导出站点记录为CSV文件
-func ExportSitesCSVHandler(ctx *gin.Context) {
-	filePath, err := commonservice.ExportSitesCSV()
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_EXPORT,
-			Info: "export failed",
-		})
-		return
-	}
-	defer os.Remove(filePath)
-
-	filename := filepath.Base(filePath)
-	ctx.Header("Content-Description", "File Transfer")
-	ctx.Header("Content-Transfer-Encoding", "binary")
-	ctx.Header("Content-Disposition", "attachment; filename="+filename)
-	ctx.Header("Content-Type", "text/csv; charset=utf-8")
-
-	ctx.File(filePath)
+// ExportSitesHandler 以CSV、JSON Lines或XLSX格式流式导出站点记录
+func ExportSitesHandler(ctx *gin.Context) {
+	streamExport(ctx, constant.RESOURCE_SITES, "", commonservice.ExportSites)
 }
 
 // ImportSitesCSVHandler 从CSV文件导入站点记录
 func ImportSitesCSVHandler(ctx *gin.Context) {
 	file, err := ctx.FormFile("file")
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "required"))
 		return
 	}
 
 	ext := filepath.Ext(file.Filename)
 	if ext != ".csv" {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("file", "unsupported file type"))
+		return
+	}
+
+	options, err := readImportOptions(ctx)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
 	}
 
 	tempDir := "temp"
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
 	tempFilePath := filepath.Join(tempDir, file.Filename)
 	err = ctx.SaveUploadedFile(file, tempFilePath)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
 	defer os.Remove(tempFilePath)
 
-	result, err := commonservice.ImportSitesCSV(tempFilePath)
+	result, err := commonservice.ImportSitesCSV(ctx.MustGet("user_id").(uint), tempFilePath, options)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.FAILED_TO_IMPORT,
-			Info: "import failed",
-		})
+		errs.AbortWith(ctx, err, errs.ErrFailedToImport)
 		return
 	}
 
-	if result.FailedCount > 0 {
-		ctx.AbortWithStatusJSON(http.StatusOK, systemmodel.Response{
-			Code: constant.FAILED_TO_IMPORT,
-			Info: "import failed",
-			Data: gin.H{
-				"success_count": result.SuccessCount,
-				"failed_count":  result.FailedCount,
-			},
-		})
-		return
-	} else {
-		ctx.AbortWithStatusJSON(http.StatusOK, systemmodel.Response{
-			Code: constant.SUCCESSFUL_IMPORT,
-			Info: "import success",
-			Data: gin.H{
-				"success_count": result.SuccessCount,
-				"failed_count":  result.FailedCount,
-			},
-		})
-		return
-	}
+	respondImportResult(ctx, result)
 }
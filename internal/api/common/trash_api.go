@@ -0,0 +1,59 @@
+package common
+
+import (
+	"cyber-life/internal/errs"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 18:50
+// @Desc:	回收站与批量记录操作的公共处理
+
+// recordActionFailure 批量操作中失败的记录及原因
+type recordActionFailure struct {
+	ID    uint   `json:"id"`
+	Error string `json:"error"`
+}
+
+// applyRecordAction 对每条记录执行操作并写入响应：全部成功时返回 success，
+// 否则按首个失败原因返回记录不存在、记录不在回收站中或 failure，并附带全部失败的记录
+func applyRecordAction(ctx *gin.Context, ids []uint, action func(userID, recordID uint) error, success systemmodel.Response, failure *errs.Error) {
+	userID := ctx.MustGet("user_id").(uint)
+
+	var (
+		failures []recordActionFailure
+		first    error
+	)
+	for _, id := range ids {
+		err := action(userID, id)
+		if err == nil {
+			continue
+		}
+
+		// 仅返回可预期的失败原因，其余错误不向客户端暴露细节
+		reason := failure.Info
+		if errors.Is(err, errs.ErrRecordNotFound) || errors.Is(err, errs.ErrRecordNotInTrash) {
+			reason = err.Error()
+		}
+		if first == nil {
+			first = err
+		}
+		failures = append(failures, recordActionFailure{ID: id, Error: reason})
+	}
+
+	if len(failures) == 0 {
+		ctx.JSON(http.StatusOK, success)
+		return
+	}
+
+	response := failure.Wrap(first)
+	if errors.Is(first, errs.ErrRecordNotFound) || errors.Is(first, errs.ErrRecordNotInTrash) {
+		response, _ = errs.As(first)
+	}
+	errs.AbortWith(ctx, response.WithData(gin.H{"failed": failures}), failure)
+}
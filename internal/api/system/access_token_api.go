@@ -0,0 +1,120 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 23:20
+// @Desc:	个人访问令牌接口实现
+
+// CreateAccessTokenHandler 为当前用户创建个人访问令牌，令牌明文仅在本次响应中返回
+func CreateAccessTokenHandler(ctx *gin.Context) {
+	type reqType struct {
+		Name          string   `json:"name" binding:"required,max=64"`
+		Scopes        []string `json:"scopes" binding:"required,min=1"`
+		ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	token, raw, err := systemservice.CreateAccessToken(ctx.MustGet("user_id").(uint), req.Name, req.Scopes, req.ExpiresInDays)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(token.ID), 10))
+	ctx.Set("audit_detail", token.Name)
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_CREATE,
+		Info: "create success",
+		Data: gin.H{
+			"token_id":   token.ID,
+			"token":      raw,
+			"name":       token.Name,
+			"scopes":     token.Scopes,
+			"expires_at": token.ExpiresAt,
+		},
+	})
+}
+
+// ListAccessTokensHandler 查询当前用户未吊销的个人访问令牌
+func ListAccessTokensHandler(ctx *gin.Context) {
+	tokens, err := systemservice.FindUserAccessTokens(ctx.MustGet("user_id").(uint))
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	list := make([]gin.H, 0, len(tokens))
+	for _, token := range tokens {
+		list = append(list, gin.H{
+			"token_id":     token.ID,
+			"name":         token.Name,
+			"hint":         token.Hint,
+			"scopes":       token.Scopes,
+			"created_at":   token.CreatedAt,
+			"last_used_at": token.LastUsedAt,
+			"last_used_ip": token.LastUsedIP,
+			"expires_at":   token.ExpiresAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"tokens": list,
+			"total":  len(list),
+		},
+	})
+}
+
+// RevokeAccessTokenHandler 吊销当前用户的指定个人访问令牌
+func RevokeAccessTokenHandler(ctx *gin.Context) {
+	type reqType struct {
+		TokenID uint `json:"token_id" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.TokenID), 10))
+	err = systemservice.RevokeUserAccessToken(ctx.MustGet("user_id").(uint), req.TokenID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DELETE,
+		Info: "delete success",
+	})
+}
+
+// accessTokenScopes 当前请求所用个人访问令牌的权限范围，未使用令牌时为nil
+func accessTokenScopes(ctx *gin.Context) []string {
+	if scopes, ok := ctx.Get("access_token_scopes"); ok {
+		return scopes.([]string)
+	}
+	return nil
+}
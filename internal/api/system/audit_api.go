@@ -0,0 +1,98 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 20:25
+// @Desc:	审计日志接口实现
+
+// ListAuditEventsHandler 按条件分页查询审计事件，时间范围参数使用RFC3339格式
+func ListAuditEventsHandler(ctx *gin.Context) {
+	var (
+		err    error
+		filter systemmodel.AuditEventFilter
+	)
+
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
+		return
+	}
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", "10"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
+		return
+	}
+
+	if actorID := ctx.Query("actor_id"); actorID != "" {
+		id, err := strconv.Atoi(actorID)
+		if err != nil {
+			errs.Abort(ctx, errs.InvalidField("actor_id", "invalid value"))
+			return
+		}
+		filter.ActorID = uint(id)
+	}
+	if since := ctx.Query("since"); since != "" {
+		filter.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			errs.Abort(ctx, errs.InvalidField("since", "invalid value"))
+			return
+		}
+	}
+	if until := ctx.Query("until"); until != "" {
+		filter.Until, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			errs.Abort(ctx, errs.InvalidField("until", "invalid value"))
+			return
+		}
+	}
+	filter.Action = ctx.Query("action")
+	filter.ResourceType = ctx.Query("resource_type")
+	filter.ResourceID = ctx.Query("resource_id")
+	filter.Outcome = ctx.Query("outcome")
+
+	events, total, err := systemservice.FindAuditEventsWithPage(filter, page, size)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"list":  events,
+			"total": total,
+		},
+	})
+}
+
+// VerifyAuditChainHandler 校验审计日志哈希链是否完整
+func VerifyAuditChainHandler(ctx *gin.Context) {
+	checked, brokenID, err := systemservice.VerifyAuditChain()
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"valid":     brokenID == 0,
+			"checked":   checked,
+			"broken_at": brokenID,
+		},
+	})
+}
@@ -0,0 +1,34 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 00:10
+// @Desc:	自动备份接口实现
+
+// BackupStatusHandler 查询最近一次自动备份的结果及现存快照
+func BackupStatusHandler(ctx *gin.Context) {
+	status, snapshots, err := systemservice.FindBackupStatus()
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"status":    status,
+			"snapshots": snapshots,
+		},
+	})
+}
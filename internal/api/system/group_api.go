@@ -0,0 +1,166 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 19:50
+// @Desc:	用户组接口实现
+
+// ListGroupsHandler 查询全部用户组及其成员
+func ListGroupsHandler(ctx *gin.Context) {
+	groups, err := systemservice.FindGroupList()
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"groups": groups,
+			"total":  len(groups),
+		},
+	})
+}
+
+// CreateGroupHandler 创建用户组
+func CreateGroupHandler(ctx *gin.Context) {
+	type reqType struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	err = systemservice.CreateGroup(req.Name, req.Description)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_CREATE,
+		Info: "create success",
+	})
+}
+
+// UpdateGroupHandler 更新用户组
+func UpdateGroupHandler(ctx *gin.Context) {
+	type reqType struct {
+		GroupID     uint   `json:"group_id" binding:"required"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.GroupID), 10))
+	err = systemservice.UpdateGroup(req.GroupID, req.Name, req.Description)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_UPDATE,
+		Info: "update success",
+	})
+}
+
+// DeleteGroupHandler 删除用户组
+func DeleteGroupHandler(ctx *gin.Context) {
+	groupID, err := strconv.Atoi(ctx.Query("group_id"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("group_id", "invalid value"))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.Itoa(groupID))
+	err = systemservice.DeleteGroup(uint(groupID))
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DELETE,
+		Info: "delete success",
+	})
+}
+
+// AddGroupMemberHandler 添加用户组成员
+func AddGroupMemberHandler(ctx *gin.Context) {
+	type reqType struct {
+		GroupID uint `json:"group_id" binding:"required"`
+		UserID  uint `json:"user_id" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.GroupID), 10))
+	ctx.Set("audit_detail", "user "+strconv.FormatUint(uint64(req.UserID), 10))
+	err = systemservice.AddGroupMember(req.GroupID, req.UserID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_CREATE,
+		Info: "create success",
+	})
+}
+
+// RemoveGroupMemberHandler 移除用户组成员
+func RemoveGroupMemberHandler(ctx *gin.Context) {
+	type reqType struct {
+		GroupID uint `json:"group_id" binding:"required"`
+		UserID  uint `json:"user_id" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.GroupID), 10))
+	ctx.Set("audit_detail", "user "+strconv.FormatUint(uint64(req.UserID), 10))
+	err = systemservice.RemoveGroupMember(req.GroupID, req.UserID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DELETE,
+		Info: "delete success",
+	})
+}
@@ -0,0 +1,61 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 21:40
+// @Desc:	登录锁定管理接口实现
+
+// ListLockoutsHandler 查询处于锁定或退避等待中的用户名与IP
+func ListLockoutsHandler(ctx *gin.Context) {
+	lockouts, err := systemservice.FindLoginLockouts()
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"list":  lockouts,
+			"total": len(lockouts),
+		},
+	})
+}
+
+// ClearLockoutHandler 解除指定的登录锁定
+func ClearLockoutHandler(ctx *gin.Context) {
+	type reqType struct {
+		LockoutID uint `json:"lockout_id" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.LockoutID), 10))
+	err = systemservice.ClearLoginLockout(req.LockoutID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DELETE,
+		Info: "delete success",
+	})
+}
@@ -0,0 +1,154 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 18:45
+// @Desc:	角色与权限接口实现
+
+// ListRolesHandler 查询全部角色及其权限
+func ListRolesHandler(ctx *gin.Context) {
+	roles, err := systemservice.FindRoleList()
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"roles": roles,
+			"total": len(roles),
+		},
+	})
+}
+
+// ListPermissionsHandler 查询全部权限
+func ListPermissionsHandler(ctx *gin.Context) {
+	permissions, err := systemservice.FindPermissionList()
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"permissions": permissions,
+			"total":       len(permissions),
+		},
+	})
+}
+
+// UserPermissionsHandler 查询当前用户的角色与权限
+func UserPermissionsHandler(ctx *gin.Context) {
+	user, err := systemservice.FindUserByID(ctx.MustGet("user_id").(uint))
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	permissions, err := systemservice.FindUserPermissions(user.ID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"role_id":     user.RoleID,
+			"permissions": permissions,
+		},
+	})
+}
+
+// CreateRoleHandler 创建自定义角色
+func CreateRoleHandler(ctx *gin.Context) {
+	type reqType struct {
+		Name        string   `json:"name" binding:"required"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	err = systemservice.CreateRole(req.Name, req.Description, req.Permissions)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_CREATE,
+		Info: "create success",
+	})
+}
+
+// UpdateRoleHandler 更新角色，permissions字段缺省时不修改权限
+func UpdateRoleHandler(ctx *gin.Context) {
+	type reqType struct {
+		RoleID      uint     `json:"role_id" binding:"required"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.RoleID), 10))
+	err = systemservice.UpdateRole(req.RoleID, req.Name, req.Description, req.Permissions)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_UPDATE,
+		Info: "update success",
+	})
+}
+
+// DeleteRoleHandler 删除自定义角色
+func DeleteRoleHandler(ctx *gin.Context) {
+	roleID, err := strconv.Atoi(ctx.Query("role_id"))
+	if err != nil {
+		errs.Abort(ctx, errs.InvalidField("role_id", "invalid value"))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.Itoa(roleID))
+	err = systemservice.DeleteRole(uint(roleID))
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DELETE,
+		Info: "delete success",
+	})
+}
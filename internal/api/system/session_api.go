@@ -0,0 +1,92 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 17:50
+// @Desc:	用户登录会话接口实现
+
+// ListSessionsHandler 查询当前用户的有效会话列表
+func ListSessionsHandler(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(uint)
+	currentID := ctx.MustGet("session_id").(uint)
+
+	sessions, err := systemservice.FindUserSessions(userID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	list := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, gin.H{
+			"session_id":   session.ID,
+			"device":       session.Device,
+			"ip":           session.IP,
+			"user_agent":   session.UserAgent,
+			"created_at":   session.CreatedAt,
+			"last_seen_at": session.LastSeenAt,
+			"expires_at":   session.ExpiresAt,
+			"current":      session.ID == currentID,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"sessions": list,
+			"total":    len(list),
+		},
+	})
+}
+
+// RevokeSessionHandler 吊销当前用户的指定会话
+func RevokeSessionHandler(ctx *gin.Context) {
+	type reqType struct {
+		SessionID uint `json:"session_id" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.SessionID), 10))
+	err = systemservice.RevokeUserSession(ctx.MustGet("user_id").(uint), req.SessionID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DELETE,
+		Info: "delete success",
+	})
+}
+
+// RevokeOtherSessionsHandler 吊销当前用户除本会话外的全部会话
+func RevokeOtherSessionsHandler(ctx *gin.Context) {
+	err := systemservice.RevokeOtherSessions(ctx.MustGet("user_id").(uint), ctx.MustGet("session_id").(uint))
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DELETE,
+		Info: "delete success",
+	})
+}
@@ -0,0 +1,161 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 16:20
+// @Desc:	双因素认证（TOTP）接口实现
+
+// TotpStatusHandler 查询当前用户的双因素认证状态
+func TotpStatusHandler(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(uint)
+	user, err := systemservice.FindUserByID(userID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	remaining, err := systemservice.CountUnusedRecoveryCodes(userID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"enabled":                  user.TotpEnabled,
+			"remaining_recovery_codes": remaining,
+		},
+	})
+}
+
+// SetupTotpHandler 生成TOTP密钥与扫码链接，需调用启用接口校验后才会生效
+func SetupTotpHandler(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(uint)
+	secret, uri, err := systemservice.SetupUserTotp(userID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_CREATE,
+		Info: "create success",
+		Data: gin.H{
+			"secret":           secret,
+			"provisioning_uri": uri,
+		},
+	})
+}
+
+// EnableTotpHandler 校验TOTP口令并启用双因素认证，返回仅展示一次的恢复码
+func EnableTotpHandler(ctx *gin.Context) {
+	type reqType struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	userID := ctx.MustGet("user_id").(uint)
+	recoveryCodes, err := systemservice.EnableUserTotp(userID, req.Code)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_ENABLE_TOTP,
+		Info: "enable totp success",
+		Data: gin.H{
+			"recovery_codes": recoveryCodes,
+		},
+	})
+}
+
+// DisableTotpHandler 校验登录口令与第二因素后停用双因素认证
+func DisableTotpHandler(ctx *gin.Context) {
+	type reqType struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	userID := ctx.MustGet("user_id").(uint)
+	user, err := systemservice.FindUserByID(userID)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ok, err := systemservice.VerifyUserPassword(user, req.Password)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+	if !ok {
+		errs.Abort(ctx, errs.ErrLoginFailed)
+		return
+	}
+
+	err = systemservice.DisableUserTotp(userID, req.Code)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_DISABLE_TOTP,
+		Info: "disable totp success",
+	})
+}
+
+// RegenerateRecoveryCodesHandler 重新生成恢复码，旧恢复码全部作废
+func RegenerateRecoveryCodesHandler(ctx *gin.Context) {
+	type reqType struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	userID := ctx.MustGet("user_id").(uint)
+	recoveryCodes, err := systemservice.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_CREATE,
+		Info: "create success",
+		Data: gin.H{
+			"recovery_codes": recoveryCodes,
+		},
+	})
+}
@@ -3,9 +3,12 @@ package system
 import (
 	"cyber-life/internal/constant"
 	"cyber-life/internal/core/config"
+	"cyber-life/internal/errs"
 	"cyber-life/pkg/auth"
-	"cyber-life/pkg/encrypt"
+	"cyber-life/pkg/logger"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"math"
 	"net/http"
 	"strconv"
 
@@ -23,87 +26,266 @@ func UserLoginHandler(ctx *gin.Context) {
 	type reqType struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
+		Device   string `json:"device"`
 	}
 
 	var req reqType
 	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	if !checkLoginAllowed(ctx, 0, req.Username) {
 		return
 	}
 
 	user, err := systemservice.FindUserByUsername(req.Username)
 	if err != nil {
-		if err.Error() == "record not found" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, systemmodel.Response{
-				Code: constant.FAILED_TO_LOGIN,
-				Info: "incorrect username or password",
-			})
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			// 不存在的用户名只计入来源IP的失败次数
+			recordLoginFailure(ctx, "")
+			recordLoginAudit(ctx, 0, req.Username, constant.AUDIT_OUTCOME_FAILURE, "unknown username")
+			errs.Abort(ctx, errs.ErrLoginFailed)
 			return
 		} else {
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-				Code: constant.INTERNAL_ERROR,
-				Info: "system internal error",
-			})
+			errs.Abort(ctx, err)
 			return
 		}
 	}
 
-	if user.Password != encrypt.Sha256String(req.Password, config.Config.SecretKey) {
-		ctx.AbortWithStatusJSON(http.StatusUnauthorized, systemmodel.Response{
-			Code: constant.FAILED_TO_LOGIN,
-			Info: "incorrect username or password",
+	ok, err := systemservice.VerifyUserPassword(user, req.Password)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+	if !ok {
+		recordLoginFailure(ctx, user.Username)
+		recordLoginAudit(ctx, user.ID, user.Username, constant.AUDIT_OUTCOME_FAILURE, "incorrect password")
+		errs.Abort(ctx, errs.ErrLoginFailed)
+		return
+	}
+
+	// 已停用的用户不允许登录，口令校验通过后再判断以免暴露账户状态
+	if !user.IsActive {
+		recordLoginAudit(ctx, user.ID, user.Username, constant.AUDIT_OUTCOME_DENIED, "user disabled")
+		errs.Abort(ctx, errs.ErrLoginFailed)
+		return
+	}
+
+	// 已启用双因素认证的用户需继续校验第二因素
+	if user.TotpEnabled {
+		mfaToken, err := auth.CreateMfaToken(user.ID, user.Username, config.Config.SecretKey)
+		if err != nil {
+			errs.Abort(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, systemmodel.Response{
+			Code: constant.TOTP_REQUIRED,
+			Info: "totp code required",
+			Data: gin.H{
+				"mfa_token": mfaToken,
+			},
 		})
 		return
 	}
 
-	jwtSign := encrypt.RandomString(32)
-	jwtToken, err := auth.CreateAccessToken(user.ID, user.Username, config.Config.SecretKey, jwtSign)
+	issueAccessToken(ctx, user, req.Device)
+}
+
+// UserTotpLoginHandler 系统用户两步登录：校验第二因素后签发访问令牌
+func UserTotpLoginHandler(ctx *gin.Context) {
+	type reqType struct {
+		MfaToken string `json:"mfa_token" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+		Device   string `json:"device"`
+	}
+
+	var req reqType
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	claims, err := auth.ParseMfaToken(req.MfaToken, config.Config.SecretKey)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, errs.ErrInvalidToken)
+		return
 	}
 
-	user.JwtSign = jwtSign
-	err = systemservice.UpdateUser(user.ID, "", "", "", "", "", "", jwtSign)
+	user, err := systemservice.FindUserByID(claims.UserID)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			err = errs.ErrInvalidToken.Wrap(err)
+		}
+		errs.Abort(ctx, err)
+		return
 	}
 
+	if !checkLoginAllowed(ctx, user.ID, user.Username) {
+		return
+	}
+
+	// 两步登录之间用户可能已被停用或关闭双因素认证，此时须重新登录
+	if !user.IsActive || !user.TotpEnabled {
+		recordLoginAudit(ctx, user.ID, user.Username, constant.AUDIT_OUTCOME_DENIED, "user state changed during login")
+		errs.Abort(ctx, errs.ErrLoginFailed)
+		return
+	}
+
+	ok, err := systemservice.VerifyUserSecondFactor(user, req.Code)
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+	if !ok {
+		recordLoginFailure(ctx, user.Username)
+		recordLoginAudit(ctx, user.ID, user.Username, constant.AUDIT_OUTCOME_DENIED, "invalid totp code")
+		errs.Abort(ctx, errs.ErrInvalidTotpCode)
+		return
+	}
+
+	issueAccessToken(ctx, user, req.Device)
+}
+
+// issueAccessToken 为通过认证的用户创建会话，并签发访问令牌与刷新令牌
+func issueAccessToken(ctx *gin.Context, user *systemmodel.User, device string) {
+	session, refreshToken, err := systemservice.CreateSession(user.ID, device, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	jwtToken, err := auth.CreateAccessToken(user.ID, user.Username, config.Config.SecretKey, session.ID, systemservice.AccessTokenTTL())
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	err = systemservice.ResetLoginFailures(user.Username)
+	if err != nil {
+		logger.Error("an error occurred while resetting login failures: ", err)
+	}
+
+	recordLoginAudit(ctx, user.ID, user.Username, constant.AUDIT_OUTCOME_SUCCESS, "device: "+device)
+
 	ctx.JSON(http.StatusOK, systemmodel.Response{
 		Code: constant.SUCCESSFUL_LOGIN,
 		Info: "login success",
 		Data: gin.H{
-			"jwt_token": jwtToken,
+			"jwt_token":     jwtToken,
+			"refresh_token": refreshToken,
+			"expires_in":    int(systemservice.AccessTokenTTL().Seconds()),
 		},
 	})
 }
 
-// UserLogoutHandler 系统用户登出
-func UserLogoutHandler(ctx *gin.Context) {
-	userID := ctx.MustGet("user_id")
-	user, err := systemservice.FindUserByID(userID.(uint))
+// recordLoginAudit 记录登录审计事件，写入失败不影响登录流程
+func recordLoginAudit(ctx *gin.Context, userID uint, username, outcome, detail string) {
+	err := systemservice.RecordAuditEvent(&systemmodel.AuditEvent{
+		ActorID:    userID,
+		Actor:      username,
+		Action:     "users.login",
+		ResourceID: strconv.FormatUint(uint64(userID), 10),
+		IP:         ctx.ClientIP(),
+		Outcome:    outcome,
+		Detail:     detail,
+	})
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		logger.Error("an error occurred while recording the audit event: ", err)
+	}
+}
+
+// checkLoginAllowed 校验用户名与来源IP是否处于锁定或退避等待中，被拒绝时写入错误响应并返回false
+func checkLoginAllowed(ctx *gin.Context, userID uint, username string) bool {
+	retryAfter, err := systemservice.CheckLoginAllowed(username, ctx.ClientIP())
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, errs.ErrAccountLocked) || errors.Is(err, errs.ErrTooManyLoginAttempts) {
+		detail := "account locked"
+		if errors.Is(err, errs.ErrTooManyLoginAttempts) {
+			detail = "too many login attempts"
+		}
+		recordLoginAudit(ctx, userID, username, constant.AUDIT_OUTCOME_DENIED, detail)
+
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		ctx.Header("Retry-After", strconv.Itoa(seconds))
+		denied, _ := errs.As(err)
+		err = denied.WithData(gin.H{"retry_after": seconds})
+	}
+
+	errs.Abort(ctx, err)
+	return false
+}
+
+// recordLoginFailure 记录登录失败计数，写入失败不影响登录流程
+func recordLoginFailure(ctx *gin.Context, username string) {
+	err := systemservice.RecordLoginFailure(username, ctx.ClientIP())
+	if err != nil {
+		logger.Error("an error occurred while recording the login failure: ", err)
+	}
+}
+
+// UserRefreshHandler 使用刷新令牌换取新的访问令牌，刷新令牌同时轮换
+func UserRefreshHandler(ctx *gin.Context) {
+	type reqType struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	var req reqType
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = systemservice.UpdateUser(user.ID, "", "", "", "", "", "", "-")
+	session, refreshToken, err := systemservice.RefreshSession(req.RefreshToken, ctx.ClientIP())
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		if errors.Is(err, errs.ErrInvalidRefreshToken) || errors.Is(err, errs.ErrRefreshTokenReused) {
+			err = errs.ErrInvalidToken.Wrap(err)
+		}
+		errs.Abort(ctx, err)
+		return
+	}
+
+	user, err := systemservice.FindUserByID(session.UserID)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			err = errs.ErrInvalidToken.Wrap(err)
+		}
+		errs.Abort(ctx, err)
+		return
+	}
+
+	if !user.IsActive {
+		errs.Abort(ctx, errs.ErrInvalidToken)
+		return
+	}
+
+	jwtToken, err := auth.CreateAccessToken(user.ID, user.Username, config.Config.SecretKey, session.ID, systemservice.AccessTokenTTL())
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_LOGIN,
+		Info: "login success",
+		Data: gin.H{
+			"jwt_token":     jwtToken,
+			"refresh_token": refreshToken,
+			"expires_in":    int(systemservice.AccessTokenTTL().Seconds()),
+		},
+	})
+}
+
+// UserLogoutHandler 系统用户登出，吊销当前会话
+func UserLogoutHandler(ctx *gin.Context) {
+	sessionID := ctx.MustGet("session_id").(uint)
+	err := systemservice.RevokeUserSession(ctx.MustGet("user_id").(uint), sessionID)
+	if err != nil {
+		errs.Abort(ctx, err)
 		return
 	}
 
@@ -122,6 +304,7 @@ func CreateUserHandler(ctx *gin.Context) {
 		Email    string `json:"email"`
 		Phone    string `json:"phone"`
 		Avatar   string `json:"avatar"`
+		RoleID   uint   `json:"role_id"`
 	}
 
 	var (
@@ -130,28 +313,14 @@ func CreateUserHandler(ctx *gin.Context) {
 	)
 	err = ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = systemservice.CreateUser(req.Username, req.Password, req.Name, req.Email, req.Phone, req.Avatar)
+	err = systemservice.CreateUser(ctx.MustGet("user_id").(uint), accessTokenScopes(ctx), req.Username, req.Password, req.Name, req.Email, req.Phone, req.Avatar, req.RoleID)
 	if err != nil {
-		if err.Error() == "the username already exists" {
-			ctx.AbortWithStatusJSON(http.StatusAlreadyReported, systemmodel.Response{
-				Code: constant.USERNAME_ALREADY_EXISTS,
-				Info: "username already exists",
-			})
-			return
-		} else {
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-				Code: constant.INTERNAL_ERROR,
-				Info: "system internal error",
-			})
-			return
-		}
+		errs.Abort(ctx, err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, systemmodel.Response{
@@ -164,28 +333,15 @@ func CreateUserHandler(ctx *gin.Context) {
 func DeleteUserHandler(ctx *gin.Context) {
 	userID, err := strconv.Atoi(ctx.Query("user_id"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("user_id", "invalid value"))
 		return
 	}
 
-	err = systemservice.DeleteUser(uint(userID))
+	ctx.Set("audit_resource_id", strconv.Itoa(userID))
+	err = systemservice.DeleteUser(ctx.MustGet("user_id").(uint), accessTokenScopes(ctx), uint(userID))
 	if err != nil {
-		if err.Error() == "record not found" {
-			ctx.AbortWithStatusJSON(http.StatusNotFound, systemmodel.Response{
-				Code: constant.RECORD_NOT_FOUND,
-				Info: "record not found",
-			})
-			return
-		} else {
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-				Code: constant.INTERNAL_ERROR,
-				Info: "system internal error",
-			})
-			return
-		}
+		errs.Abort(ctx, err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, systemmodel.Response{
@@ -204,6 +360,7 @@ func UpdateUserHandler(ctx *gin.Context) {
 		Email    string `json:"email"`
 		Phone    string `json:"phone"`
 		Avatar   string `json:"avatar"`
+		RoleID   uint   `json:"role_id"`
 	}
 
 	var (
@@ -213,34 +370,15 @@ func UpdateUserHandler(ctx *gin.Context) {
 
 	err = ctx.ShouldBindBodyWithJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.FromBinding(err))
 		return
 	}
 
-	err = systemservice.UpdateUser(req.UserID, req.Username, req.Password, req.Name, req.Email, req.Phone, req.Avatar, "")
+	ctx.Set("audit_resource_id", strconv.FormatUint(uint64(req.UserID), 10))
+	err = systemservice.UpdateUser(ctx.MustGet("user_id").(uint), accessTokenScopes(ctx), req.UserID, req.Username, req.Password, req.Name, req.Email, req.Phone, req.Avatar, req.RoleID)
 	if err != nil {
-		if err.Error() == "record not found" {
-			ctx.AbortWithStatusJSON(http.StatusNotFound, systemmodel.Response{
-				Code: constant.RECORD_NOT_FOUND,
-				Info: "record not found",
-			})
-			return
-		} else if err.Error() == "the username already exists" {
-			ctx.AbortWithStatusJSON(http.StatusAlreadyReported, systemmodel.Response{
-				Code: constant.USERNAME_ALREADY_EXISTS,
-				Info: "the username already exists",
-			})
-			return
-		} else {
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-				Code: constant.INTERNAL_ERROR,
-				Info: "system internal error",
-			})
-			return
-		}
+		errs.Abort(ctx, err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, systemmodel.Response{
@@ -255,27 +393,13 @@ func FindUserHandler(ctx *gin.Context) {
 	case "user_id":
 		userID, err := strconv.Atoi(ctx.Query("user_id"))
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-				Code: constant.INVALID_REQUEST_PARAMS,
-				Info: "invalid request params",
-			})
+			errs.Abort(ctx, errs.InvalidField("user_id", "invalid value"))
 			return
 		}
 		user, err := systemservice.FindUserByID(uint(userID))
 		if err != nil {
-			if err.Error() == "record not found" {
-				ctx.AbortWithStatusJSON(http.StatusNotFound, systemmodel.Response{
-					Code: constant.RECORD_NOT_FOUND,
-					Info: "record not found",
-				})
-				return
-			} else {
-				ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-					Code: constant.INTERNAL_ERROR,
-					Info: "system internal error",
-				})
-				return
-			}
+			errs.Abort(ctx, err)
+			return
 		}
 
 		ctx.JSON(http.StatusOK, systemmodel.Response{
@@ -292,19 +416,8 @@ func FindUserHandler(ctx *gin.Context) {
 		username := ctx.Query("username")
 		user, err := systemservice.FindUserByUsername(username)
 		if err != nil {
-			if err.Error() == "record not found" {
-				ctx.AbortWithStatusJSON(http.StatusNotFound, systemmodel.Response{
-					Code: constant.RECORD_NOT_FOUND,
-					Info: "record not found",
-				})
-				return
-			} else {
-				ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-					Code: constant.INTERNAL_ERROR,
-					Info: "system internal error",
-				})
-				return
-			}
+			errs.Abort(ctx, err)
+			return
 		}
 
 		ctx.JSON(http.StatusOK, systemmodel.Response{
@@ -321,17 +434,11 @@ func FindUserHandler(ctx *gin.Context) {
 		name := ctx.Query("name")
 		users, err := systemservice.FindUserByName(name)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-				Code: constant.INTERNAL_ERROR,
-				Info: "system internal error",
-			})
+			errs.Abort(ctx, err)
 			return
 		}
 		if len(users) == 0 {
-			ctx.AbortWithStatusJSON(http.StatusNotFound, systemmodel.Response{
-				Code: constant.RECORD_NOT_FOUND,
-				Info: "record not found",
-			})
+			errs.Abort(ctx, errs.ErrRecordNotFound)
 			return
 		}
 
@@ -346,10 +453,7 @@ func FindUserHandler(ctx *gin.Context) {
 		break
 
 	default:
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("type", "invalid value"))
 		return
 	}
 }
@@ -361,27 +465,18 @@ func ListUserHandler(ctx *gin.Context) {
 
 	page, err := strconv.Atoi(_page)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("page", "invalid value"))
 		return
 	}
 	size, err := strconv.Atoi(_size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, systemmodel.Response{
-			Code: constant.INVALID_REQUEST_PARAMS,
-			Info: "invalid request params",
-		})
+		errs.Abort(ctx, errs.InvalidField("size", "invalid value"))
 		return
 	}
 
 	users, total, err := systemservice.FindUserListWithPage(page, size)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-			Code: constant.INTERNAL_ERROR,
-			Info: "system internal error",
-		})
+		errs.Abort(ctx, err)
 		return
 	}
 
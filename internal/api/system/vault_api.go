@@ -0,0 +1,84 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/core/vault"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/logger"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+
+	systemmodel "cyber-life/internal/model/system"
+	commonservice "cyber-life/internal/service/common"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 13:52
+// @Desc:	凭据保险库接口实现
+
+// UnlockVaultHandler 使用主口令解封保险库（首次调用时以该口令初始化保险库）
+func UnlockVaultHandler(ctx *gin.Context) {
+	type reqType struct {
+		Passphrase string `json:"passphrase" binding:"required"`
+	}
+
+	var req reqType
+	err := ctx.ShouldBindBodyWithJSON(&req)
+	if err != nil {
+		errs.Abort(ctx, errs.FromBinding(err))
+		return
+	}
+
+	err = systemservice.UnsealVault(req.Passphrase)
+	if err != nil {
+		if errors.Is(err, vault.ErrInvalidPassphrase) {
+			err = errs.ErrIncorrectPassphrase.Wrap(err)
+		}
+		errs.Abort(ctx, err)
+		return
+	}
+
+	// 解封后加密历史遗留的明文凭据
+	count, err := commonservice.EncryptPlaintextCredentials()
+	if err != nil {
+		logger.Error("an error occurred while encrypting plaintext credentials: ", err)
+	} else if count > 0 {
+		logger.Infof("encrypted %d plaintext credential records", count)
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_UNLOCK,
+		Info: "unlock success",
+	})
+}
+
+// LockVaultHandler 立即封存保险库
+func LockVaultHandler(ctx *gin.Context) {
+	systemservice.SealVault()
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_LOCK,
+		Info: "lock success",
+	})
+}
+
+// VaultStatusHandler 查询保险库状态
+func VaultStatusHandler(ctx *gin.Context) {
+	initialized, sealed, err := systemservice.FindVaultStatus()
+	if err != nil {
+		errs.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, systemmodel.Response{
+		Code: constant.SUCCESSFUL_FIND,
+		Info: "find success",
+		Data: gin.H{
+			"initialized": initialized,
+			"sealed":      sealed,
+		},
+	})
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"cyber-life/internal/core/backup"
+	"cyber-life/internal/core/initialize"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:40
+// @Desc:	backup/restore 子命令：口令加密的全量备份与恢复
+
+const dataDir = "data"
+
+func runBackup(args []string) error {
+	fs := newFlagSet("backup", "backup [--out file] [--passphrase pass]")
+	out := fs.String("out", "", "备份文件路径，默认 backups/cyber-life_<时间>.clbak")
+	passphrase := fs.String("passphrase", "", "备份加密口令，未提供时从标准输入读取")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = filepath.Join("backups", fmt.Sprintf("cyber-life_%s.clbak", time.Now().Format("20060102_150405")))
+	}
+
+	*passphrase, err = readSecret(*passphrase, "backup passphrase")
+	if err != nil {
+		return err
+	}
+	if len(*passphrase) < backup.MinPassphraseLength {
+		return errors.New("the backup passphrase is too short")
+	}
+
+	err = loadConfig()
+	if err != nil {
+		return err
+	}
+	db, err := initialize.OpenDatabase()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(*out), 0700)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(*out, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := backup.Create(db, dataDir, *passphrase, file)
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		os.Remove(*out)
+		return err
+	}
+
+	fmt.Printf("backup written to %s (schema version %d, %d tables, %d icons)\n", *out, manifest.SchemaVersion, len(manifest.Tables), len(manifest.Icons))
+	return nil
+}
+
+func runRestore(args []string) error {
+	fs := newFlagSet("restore", "restore --in file [--passphrase pass] [--check | --yes]")
+	in := fs.String("in", "", "备份文件路径")
+	passphrase := fs.String("passphrase", "", "备份加密口令，未提供时从标准输入读取")
+	check := fs.Bool("check", false, "仅解密并校验备份完整性，不修改数据")
+	yes := fs.Bool("yes", false, "确认使用备份内容替换当前全部数据")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *in == "" {
+		return errors.New("--in is required")
+	}
+	if !*check && !*yes {
+		return errors.New("restoring replaces all existing data, stop the server and re-run with --yes")
+	}
+
+	*passphrase, err = readSecret(*passphrase, "backup passphrase")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if *check {
+		manifest, err := backup.Verify(*passphrase, file)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("backup created at %s is intact (schema version %d, %d tables, %d icons)\n", manifest.CreatedAt.Format(time.RFC3339), manifest.SchemaVersion, len(manifest.Tables), len(manifest.Icons))
+		return nil
+	}
+
+	err = loadConfig()
+	if err != nil {
+		return err
+	}
+	db, err := initialize.OpenDatabase()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := backup.Restore(db, dataDir, *passphrase, file)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("restored backup created at %s (%d tables, %d icons)\n", manifest.CreatedAt.Format(time.RFC3339), len(manifest.Tables), len(manifest.Icons))
+	return nil
+}
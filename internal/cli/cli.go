@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bufio"
+	"cyber-life/internal/core/initialize"
+	"cyber-life/internal/repository"
+	"cyber-life/pkg/logger"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:40
+// @Desc:	命令行入口：解析全局参数并分发子命令
+
+const defaultConfigPath = "config.toml"
+
+// command 子命令
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"serve", "启动Web服务（默认）", runServe},
+	{"migrate", "数据库迁移：up/down/status", runMigrate},
+	{"user", "系统用户管理：create/reset-password/disable", runUser},
+	{"backup", "导出口令加密的全量备份", runBackup},
+	{"restore", "校验或从全量备份恢复", runRestore},
+	{"export", "导出指定用户可见的记录为CSV、JSON Lines或XLSX文件", runExport},
+}
+
+// configPath 配置文件路径，全局参数与各子命令的 --config 共用
+var configPath = defaultConfigPath
+
+// Run 解析命令行并执行子命令，返回进程退出码
+func Run(args []string) int {
+	fs := flag.NewFlagSet("cyber-life", flag.ContinueOnError)
+	fs.StringVar(&configPath, "config", defaultConfigPath, "配置文件路径")
+	fs.Usage = func() { printUsage(fs) }
+
+	err := fs.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	name := "serve"
+	rest := fs.Args()
+	if len(rest) > 0 {
+		name, rest = rest[0], rest[1:]
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		err = cmd.run(rest)
+		if err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	printUsage(fs)
+	return 2
+}
+
+// printUsage 打印命令行用法
+func printUsage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintln(out, "usage: cyber-life [--config path] <command> [arguments]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "run 'cyber-life <command> -h' for the arguments of a command")
+}
+
+// newFlagSet 创建子命令参数集，并注册共用的 --config 参数
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&configPath, "config", configPath, "配置文件路径")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: cyber-life "+usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// loadConfig 加载配置文件并初始化日志
+func loadConfig() error {
+	err := initialize.InitGlobalConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("an error occurred while loading the configuration file: %w", err)
+	}
+
+	logger.InitLogger("app.log", "debug")
+	return nil
+}
+
+// openRepository 加载配置、连接数据库并执行待执行的迁移，初始化数据仓储层与内置角色
+func openRepository() error {
+	err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := initialize.InitDatabase()
+	if err != nil {
+		return err
+	}
+
+	err = repository.InitRepository(db)
+	if err != nil {
+		return err
+	}
+
+	return initialize.InitRoles()
+}
+
+// readSecret 参数未提供时从标准输入读取一行作为口令
+func readSecret(value, prompt string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt+": ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New(prompt + " is required")
+	}
+	return line, nil
+}
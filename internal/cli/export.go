@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/pkg/exporter"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	commonservice "cyber-life/internal/service/common"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:40
+// @Desc:	export 子命令：以指定用户的可见范围导出记录为CSV、JSON Lines或XLSX文件
+
+func runExport(args []string) error {
+	fs := newFlagSet("export", "export --user name --type accounts|secrets|hosts|sites --out file [--format csv|jsonl|xlsx] [--keyword text] [--archive-password pass] [--passphrase pass]")
+	username := fs.String("user", "", "以该用户的可见范围导出")
+	resourceType := fs.String("type", "", "记录类型：accounts/secrets/hosts/sites")
+	out := fs.String("out", "", "导出文件路径")
+	format := fs.String("format", exporter.FormatCSV, "导出格式：csv/jsonl/xlsx")
+	keyword := fs.String("keyword", "", "仅导出匹配关键字的记录，匹配字段与列表查询一致")
+	archivePassword := fs.String("archive-password", "", "非空时导出为以该口令AES-256加密的ZIP压缩包")
+	passphrase := fs.String("passphrase", "", "保险库主口令，导出含敏感字段的记录时需要，未提供时从标准输入读取")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *username == "" || *out == "" {
+		return errors.New("--user and --out are required")
+	}
+
+	var export func(userID uint, keyword string, writer exporter.Writer) (int, error)
+	switch *resourceType {
+	case constant.RESOURCE_ACCOUNTS:
+		export = commonservice.ExportAccounts
+	case constant.RESOURCE_SECRETS:
+		export = commonservice.ExportSecrets
+	case constant.RESOURCE_HOSTS:
+		export = commonservice.ExportHosts
+	case constant.RESOURCE_SITES:
+		export = commonservice.ExportSites
+	default:
+		return fmt.Errorf("unknown record type %q", *resourceType)
+	}
+	extension, ok := exporter.Extension(*format)
+	if !ok {
+		return fmt.Errorf("%w %q", exporter.ErrUnknownFormat, *format)
+	}
+
+	// 站点记录不含加密字段，其余记录需先解封保险库
+	if *resourceType != constant.RESOURCE_SITES {
+		*passphrase, err = readSecret(*passphrase, "vault passphrase")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = openRepository()
+	if err != nil {
+		return err
+	}
+
+	user, err := systemservice.FindUserByUsername(*username)
+	if err != nil {
+		return fmt.Errorf("user %s: %w", *username, err)
+	}
+
+	if *resourceType != constant.RESOURCE_SITES {
+		err = systemservice.UnsealVault(*passphrase)
+		if err != nil {
+			return err
+		}
+		defer systemservice.SealVault()
+	}
+
+	file, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var output io.Writer = file
+	var archive io.WriteCloser
+	if *archivePassword != "" {
+		archive, err = exporter.NewEncryptedArchive(file, *resourceType+"."+extension, *archivePassword)
+		if err != nil {
+			return err
+		}
+		output = archive
+	}
+
+	writer, err := exporter.NewWriter(*format, output)
+	if err != nil {
+		return err
+	}
+	count, err := export(user.ID, *keyword, writer)
+	if err != nil {
+		return err
+	}
+	if archive != nil {
+		err = archive.Close()
+		if err != nil {
+			return err
+		}
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d %s of user %s exported to %s\n", count, *resourceType, user.Username, *out)
+	return nil
+}
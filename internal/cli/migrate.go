@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"cyber-life/internal/core/initialize"
+	"cyber-life/internal/core/migration"
+	"errors"
+	"fmt"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:40
+// @Desc:	migrate 子命令：升级、回滚数据库或查看迁移状态
+
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate", "migrate <up|down|status> [--to version] [--dry-run]")
+	target := fs.Int("to", -1, "目标版本，默认up升级至最新版本、down回滚一个版本")
+	dryRun := fs.Bool("dry-run", false, "仅列出待执行的迁移，不实际执行")
+
+	if len(args) == 0 {
+		fs.Usage()
+		return errors.New("missing migrate action")
+	}
+	action := args[0]
+	err := fs.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+
+	err = loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if action == "status" {
+		db, err := initialize.OpenDatabase()
+		if err != nil {
+			return err
+		}
+		current, err := migration.CurrentVersion(db)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("current version: %d, latest version: %d\n", current, migration.Latest())
+		for _, m := range migration.All() {
+			state := "pending"
+			if m.Version <= current {
+				state = "applied"
+			}
+			fmt.Printf("  %-8s %s\n", state, m)
+		}
+		return migration.Check(db)
+	}
+
+	current, pending, err := initialize.MigrateDatabase(action, *target, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version before migration: %d\n", current)
+	for _, m := range pending {
+		if *dryRun {
+			fmt.Printf("  [dry-run] would %s %s\n", action, m)
+		} else {
+			fmt.Printf("  %s %s done\n", action, m)
+		}
+	}
+	if len(pending) == 0 {
+		fmt.Println("the database is already at the target version")
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"cyber-life/internal/core"
+	"cyber-life/pkg/logger"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:40
+// @Desc:	serve 子命令：启动Web服务
+
+func runServe(args []string) error {
+	fs := newFlagSet("serve", "serve [--config path]")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	err = loadConfig()
+	if err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	core.Start()
+	return nil
+}
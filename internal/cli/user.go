@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:40
+// @Desc:	user 子命令：创建用户、重置口令（同时解除登录锁定）、停用用户
+
+func runUser(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing user action, expected create/reset-password/disable")
+	}
+
+	switch args[0] {
+	case "create":
+		return runUserCreate(args[1:])
+	case "reset-password":
+		return runUserResetPassword(args[1:])
+	case "disable":
+		return runUserDisable(args[1:])
+	default:
+		return fmt.Errorf("unknown user action %q", args[0])
+	}
+}
+
+func runUserCreate(args []string) error {
+	fs := newFlagSet("user create", "user create --username name [--password pass] [--role role] [--name n] [--email e] [--phone p]")
+	username := fs.String("username", "", "用户名")
+	password := fs.String("password", "", "登录口令，未提供时从标准输入读取")
+	roleName := fs.String("role", "", "角色名称，默认访客角色")
+	name := fs.String("name", "", "姓名")
+	email := fs.String("email", "", "邮箱")
+	phone := fs.String("phone", "", "电话")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *username == "" {
+		return errors.New("--username is required")
+	}
+
+	*password, err = readSecret(*password, "password")
+	if err != nil {
+		return err
+	}
+
+	err = openRepository()
+	if err != nil {
+		return err
+	}
+
+	var roleID uint
+	if *roleName != "" {
+		role, err := systemservice.FindRoleByName(*roleName)
+		if err != nil {
+			return fmt.Errorf("role %s: %w", *roleName, err)
+		}
+		roleID = role.ID
+	}
+
+	err = systemservice.CreateUser(0, nil, *username, *password, *name, *email, *phone, "", roleID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("user %s created\n", *username)
+	return nil
+}
+
+func runUserResetPassword(args []string) error {
+	fs := newFlagSet("user reset-password", "user reset-password --username name [--password pass]")
+	username := fs.String("username", "", "用户名")
+	password := fs.String("password", "", "新的登录口令，未提供时从标准输入读取")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *username == "" {
+		return errors.New("--username is required")
+	}
+
+	*password, err = readSecret(*password, "password")
+	if err != nil {
+		return err
+	}
+
+	err = openRepository()
+	if err != nil {
+		return err
+	}
+
+	user, err := systemservice.FindUserByUsername(*username)
+	if err != nil {
+		return fmt.Errorf("user %s: %w", *username, err)
+	}
+
+	// 修改口令会吊销该用户的全部会话
+	err = systemservice.UpdateUser(0, nil, user.ID, "", *password, "", "", "", "", 0)
+	if err != nil {
+		return err
+	}
+	err = systemservice.ResetLoginFailures(user.Username)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("the password of user %s has been reset and its login lockout cleared\n", user.Username)
+	return nil
+}
+
+func runUserDisable(args []string) error {
+	fs := newFlagSet("user disable", "user disable --username name")
+	username := fs.String("username", "", "用户名")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *username == "" {
+		return errors.New("--username is required")
+	}
+
+	err = openRepository()
+	if err != nil {
+		return err
+	}
+
+	user, err := systemservice.FindUserByUsername(*username)
+	if err != nil {
+		return fmt.Errorf("user %s: %w", *username, err)
+	}
+
+	err = systemservice.DisableUser(0, nil, user.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("user %s disabled\n", user.Username)
+	return nil
+}
@@ -0,0 +1,14 @@
+package constant
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 23:00
+// @Desc:	个人访问令牌
+
+// ACCESS_TOKEN_PREFIX 个人访问令牌的固定前缀，便于密钥扫描工具识别泄露的令牌，也用于鉴权时区分访问令牌与JWT
+const ACCESS_TOKEN_PREFIX = "cl_pat_"
+
+const (
+	ACCESS_TOKEN_DEFAULT_DAYS = 90  // 未指定有效期时的默认有效天数
+	ACCESS_TOKEN_MAX_DAYS     = 365 // 有效期上限
+)
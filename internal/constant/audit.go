@@ -0,0 +1,12 @@
+package constant
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 20:12
+// @Desc:	审计事件结果编码
+
+const (
+	AUDIT_OUTCOME_SUCCESS = "success"
+	AUDIT_OUTCOME_FAILURE = "failure"
+	AUDIT_OUTCOME_DENIED  = "denied"
+)
@@ -0,0 +1,32 @@
+package constant
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 09:20
+// @Desc:	导入明细结果编码
+
+const (
+	IMPORT_STATUS_SUCCESS     = "success"
+	IMPORT_STATUS_VALID       = "valid"   // 试运行时校验通过
+	IMPORT_STATUS_UPDATED     = "updated" // 覆盖或合并了已有记录
+	IMPORT_STATUS_FAILED      = "failed"
+	IMPORT_STATUS_SKIPPED     = "skipped"
+	IMPORT_STATUS_ROLLED_BACK = "rolled_back" // 全部或不导入时因其它记录失败而未导入
+)
+
+const (
+	/* 导入记录与已有记录冲突时的处理方式 */
+
+	IMPORT_CONFLICT_SKIP      = "skip"      // 跳过导入记录
+	IMPORT_CONFLICT_OVERWRITE = "overwrite" // 以导入记录覆盖已有记录
+	IMPORT_CONFLICT_KEEP_BOTH = "keep_both" // 保留两者，导入记录另行创建
+	IMPORT_CONFLICT_MERGE     = "merge"     // 以导入记录中的非空字段覆盖已有记录
+)
+
+// ImportConflictStrategies 全部冲突处理方式
+var ImportConflictStrategies = map[string]bool{
+	IMPORT_CONFLICT_SKIP:      true,
+	IMPORT_CONFLICT_OVERWRITE: true,
+	IMPORT_CONFLICT_KEEP_BOTH: true,
+	IMPORT_CONFLICT_MERGE:     true,
+}
@@ -0,0 +1,11 @@
+package constant
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 21:40
+// @Desc:	登录失败计数维度
+
+const (
+	LOGIN_SCOPE_USERNAME = "username"
+	LOGIN_SCOPE_IP       = "ip"
+)
@@ -0,0 +1,9 @@
+package constant
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 21:05
+// @Desc:	敏感字段掩码常量
+
+// MASKED_VALUE 列表与搜索结果中敏感字段的掩码值，明文需经查看接口单独获取
+const MASKED_VALUE = "******"
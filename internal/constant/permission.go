@@ -0,0 +1,99 @@
+package constant
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 18:10
+// @Desc:	系统权限编码表
+
+const (
+	/* 账号记录 */
+
+	PERM_ACCOUNTS_READ           = "accounts:read"
+	PERM_ACCOUNTS_READ_PLAINTEXT = "accounts:read_plaintext"
+	PERM_ACCOUNTS_WRITE          = "accounts:write"
+	PERM_ACCOUNTS_IMPORT         = "accounts:import"
+	PERM_ACCOUNTS_EXPORT         = "accounts:export"
+
+	/* 密钥记录 */
+
+	PERM_SECRETS_READ           = "secrets:read"
+	PERM_SECRETS_READ_PLAINTEXT = "secrets:read_plaintext"
+	PERM_SECRETS_WRITE          = "secrets:write"
+	PERM_SECRETS_IMPORT         = "secrets:import"
+	PERM_SECRETS_EXPORT         = "secrets:export"
+
+	/* 主机记录 */
+
+	PERM_HOSTS_READ           = "hosts:read"
+	PERM_HOSTS_READ_PLAINTEXT = "hosts:read_plaintext"
+	PERM_HOSTS_WRITE          = "hosts:write"
+	PERM_HOSTS_IMPORT         = "hosts:import"
+	PERM_HOSTS_EXPORT         = "hosts:export"
+
+	/* 站点记录 */
+
+	PERM_SITES_READ   = "sites:read"
+	PERM_SITES_WRITE  = "sites:write"
+	PERM_SITES_IMPORT = "sites:import"
+	PERM_SITES_EXPORT = "sites:export"
+
+	/* 系统管理 */
+
+	PERM_ICONS_UPLOAD = "icons:upload"
+	PERM_USERS_READ   = "users:read"
+	PERM_USERS_MANAGE = "users:manage"
+	PERM_ROLES_MANAGE = "roles:manage"
+	PERM_VAULT_MANAGE = "vault:manage"
+	PERM_AUDIT_READ   = "audit:read"
+	PERM_BACKUPS_READ = "backups:read"
+)
+
+const (
+	ROLE_ADMIN  = "admin"
+	ROLE_EDITOR = "editor"
+	ROLE_VIEWER = "viewer"
+)
+
+// Permissions 全部权限编码及说明
+var Permissions = map[string]string{
+	PERM_ACCOUNTS_READ:           "查看账号记录",
+	PERM_ACCOUNTS_READ_PLAINTEXT: "查看账号口令明文",
+	PERM_ACCOUNTS_WRITE:          "创建、修改、删除账号记录",
+	PERM_ACCOUNTS_IMPORT:         "导入账号记录",
+	PERM_ACCOUNTS_EXPORT:         "导出账号记录",
+	PERM_SECRETS_READ:            "查看密钥记录",
+	PERM_SECRETS_READ_PLAINTEXT:  "查看密钥Secret明文",
+	PERM_SECRETS_WRITE:           "创建、修改、删除密钥记录",
+	PERM_SECRETS_IMPORT:          "导入密钥记录",
+	PERM_SECRETS_EXPORT:          "导出密钥记录",
+	PERM_HOSTS_READ:              "查看主机记录",
+	PERM_HOSTS_READ_PLAINTEXT:    "查看主机口令明文",
+	PERM_HOSTS_WRITE:             "创建、修改、删除主机记录",
+	PERM_HOSTS_IMPORT:            "导入主机记录",
+	PERM_HOSTS_EXPORT:            "导出主机记录",
+	PERM_SITES_READ:              "查看站点记录",
+	PERM_SITES_WRITE:             "创建、修改、删除站点记录",
+	PERM_SITES_IMPORT:            "导入站点记录",
+	PERM_SITES_EXPORT:            "导出站点记录",
+	PERM_ICONS_UPLOAD:            "上传图标",
+	PERM_USERS_READ:              "查看系统用户",
+	PERM_USERS_MANAGE:            "管理系统用户",
+	PERM_ROLES_MANAGE:            "管理角色与权限",
+	PERM_VAULT_MANAGE:            "解锁、锁定凭据保险库",
+	PERM_AUDIT_READ:              "查看与校验审计日志",
+	PERM_BACKUPS_READ:            "查看自动备份状态",
+}
+
+// BuiltinRoles 内置角色及其默认权限，管理员角色始终拥有全部权限
+var BuiltinRoles = map[string][]string{
+	ROLE_EDITOR: {
+		PERM_ACCOUNTS_READ, PERM_ACCOUNTS_READ_PLAINTEXT, PERM_ACCOUNTS_WRITE, PERM_ACCOUNTS_IMPORT, PERM_ACCOUNTS_EXPORT,
+		PERM_SECRETS_READ, PERM_SECRETS_READ_PLAINTEXT, PERM_SECRETS_WRITE, PERM_SECRETS_IMPORT, PERM_SECRETS_EXPORT,
+		PERM_HOSTS_READ, PERM_HOSTS_READ_PLAINTEXT, PERM_HOSTS_WRITE, PERM_HOSTS_IMPORT, PERM_HOSTS_EXPORT,
+		PERM_SITES_READ, PERM_SITES_WRITE, PERM_SITES_IMPORT, PERM_SITES_EXPORT,
+		PERM_ICONS_UPLOAD, PERM_VAULT_MANAGE,
+	},
+	ROLE_VIEWER: {
+		PERM_ACCOUNTS_READ, PERM_SECRETS_READ, PERM_HOSTS_READ, PERM_SITES_READ,
+	},
+}
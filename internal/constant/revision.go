@@ -0,0 +1,21 @@
+package constant
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 20:10
+// @Desc:	记录修订历史相关常量
+
+const (
+	/* 修订产生的方式 */
+
+	REVISION_ACTION_UPDATE   = "update"
+	REVISION_ACTION_ROLLBACK = "rollback"
+	REVISION_ACTION_IMPORT   = "import" // 导入时覆盖或合并已有记录
+)
+
+// RevisionSensitiveFields 各记录类型中须加密保存修订值、且在修订历史中以掩码返回的字段
+var RevisionSensitiveFields = map[string]map[string]bool{
+	RESOURCE_ACCOUNTS: {"password": true},
+	RESOURCE_SECRETS:  {"key_secret": true},
+	RESOURCE_HOSTS:    {"password": true},
+}
@@ -0,0 +1,33 @@
+package constant
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 19:05
+// @Desc:	记录共享相关编码
+
+const (
+	/* 可共享的记录类型，与数据表名一致 */
+
+	RESOURCE_ACCOUNTS = "accounts"
+	RESOURCE_SECRETS  = "secrets"
+	RESOURCE_HOSTS    = "hosts"
+	RESOURCE_SITES    = "sites"
+
+	/* 被授权方类型 */
+
+	GRANTEE_USER  = "user"
+	GRANTEE_GROUP = "group"
+
+	/* 授权级别 */
+
+	SHARE_ACCESS_READ       = "read"
+	SHARE_ACCESS_READ_WRITE = "read_write"
+)
+
+// ShareResources 全部可共享的记录类型
+var ShareResources = map[string]bool{
+	RESOURCE_ACCOUNTS: true,
+	RESOURCE_SECRETS:  true,
+	RESOURCE_HOSTS:    true,
+	RESOURCE_SITES:    true,
+}
@@ -0,0 +1,408 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"cyber-life/internal/core/migration"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:40
+// @Desc:	全量备份与恢复：导出全部数据表的原始行（敏感字段保持密文）及 data/*_icons 图标目录，归档整体以口令加密
+
+const (
+	FormatName    = "cyber-life-backup"
+	FormatVersion = 2
+
+	manifestEntry = "manifest.json"
+	tablesPrefix  = "tables/"
+	iconsPrefix   = "icons/"
+
+	migrationsTable  = "schema_migrations"
+	restoreBatchSize = 200
+)
+
+// Manifest 备份清单
+type Manifest struct {
+	Format        string            `json:"format"`
+	Version       int               `json:"version"`
+	SchemaVersion int               `json:"schema_version"` // 备份时数据库的迁移版本
+	CreatedAt     time.Time         `json:"created_at"`
+	Tables        []ManifestTable   `json:"tables"`
+	Icons         []string          `json:"icons"`     // 图标文件相对 data 目录的路径
+	Checksums     map[string]string `json:"checksums"` // 各条目的SHA-256摘要
+}
+
+// ManifestTable 备份清单中的数据表
+type ManifestTable struct {
+	Name string `json:"name"`
+	Rows int    `json:"rows"`
+}
+
+// Create 将数据库全部数据表及图标目录写入 tar.gz 归档，使用口令加密后写出
+func Create(db *gorm.DB, dataDir, passphrase string, w io.Writer) (*Manifest, error) {
+	if len(passphrase) < MinPassphraseLength {
+		return nil, errors.New("the backup passphrase is too short")
+	}
+
+	schemaVersion, err := migration.CurrentVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tables)
+
+	manifest := &Manifest{
+		Format:        FormatName,
+		Version:       FormatVersion,
+		SchemaVersion: schemaVersion,
+		CreatedAt:     time.Now(),
+		Checksums:     make(map[string]string),
+	}
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+
+	for _, table := range tables {
+		// 迁移记录由清单中的 SchemaVersion 表示，不随数据恢复
+		if strings.HasPrefix(table, "sqlite_") || table == migrationsTable {
+			continue
+		}
+
+		var rows []map[string]interface{}
+		err = db.Table(table).Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			for column, value := range row {
+				if raw, ok := value.([]byte); ok {
+					row[column] = string(raw)
+				}
+			}
+		}
+
+		payload, err := json.Marshal(rows)
+		if err != nil {
+			return nil, err
+		}
+		err = writeEntry(tw, manifest, tablesPrefix+table+".json", payload)
+		if err != nil {
+			return nil, err
+		}
+
+		manifest.Tables = append(manifest.Tables, ManifestTable{Name: table, Rows: len(rows)})
+	}
+
+	iconDirs, err := filepath.Glob(filepath.Join(dataDir, "*_icons"))
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range iconDirs {
+		err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+
+			rel, err := filepath.Rel(dataDir, file)
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			rel = filepath.ToSlash(rel)
+			manifest.Icons = append(manifest.Icons, rel)
+			return writeEntry(tw, manifest, iconsPrefix+rel, content)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = writeEntry(tw, nil, manifestEntry, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gz.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := seal(passphrase, archive.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Verify 使用口令解密备份并校验清单与各条目摘要，不访问数据库
+func Verify(passphrase string, r io.Reader) (*Manifest, error) {
+	manifest, _, err := readArchive(passphrase, r)
+	return manifest, err
+}
+
+// Restore 解密并校验归档后在同一事务中清空并写回全部数据表，最后还原图标文件；
+// 口令错误、归档被篡改或不完整、格式不符或版本高于当前程序时不修改数据库
+func Restore(db *gorm.DB, dataDir, passphrase string, r io.Reader) (*Manifest, error) {
+	manifest, entries, err := readArchive(passphrase, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.SchemaVersion > migration.Latest() {
+		return nil, fmt.Errorf("the backup schema version %d is newer than this binary supports (%d)", manifest.SchemaVersion, migration.Latest())
+	}
+
+	// 先将数据库升级到最新结构，备份中缺少的新增列保持默认值
+	_, err = migration.Up(db, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tableRows := make(map[string][]map[string]interface{}, len(manifest.Tables))
+	for _, table := range manifest.Tables {
+		if table.Name == migrationsTable || !db.Migrator().HasTable(table.Name) {
+			return nil, fmt.Errorf("unknown table %s in the backup", table.Name)
+		}
+
+		rows, err := decodeRows(db, table.Name, entries[tablesPrefix+table.Name+".json"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rows of table %s: %w", table.Name, err)
+		}
+		if len(rows) != table.Rows {
+			return nil, fmt.Errorf("row count mismatch in table %s", table.Name)
+		}
+		tableRows[table.Name] = rows
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range manifest.Tables {
+			// 使用原始语句清空数据表，绕过模型钩子（如审计日志的只追加约束）
+			err := tx.Exec("DELETE FROM " + tx.Statement.Quote(table.Name)).Error
+			if err != nil {
+				return err
+			}
+
+			rows := tableRows[table.Name]
+			if len(rows) == 0 {
+				continue
+			}
+			err = tx.Table(table.Name).CreateInBatches(rows, restoreBatchSize).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, icon := range manifest.Icons {
+		target := filepath.Join(dataDir, filepath.FromSlash(icon))
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(target, entries[iconsPrefix+icon], 0644)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return manifest, nil
+}
+
+// writeEntry 向归档写入一个文件，并将其摘要记录到清单
+func writeEntry(tw *tar.Writer, manifest *Manifest, name string, content []byte) error {
+	if manifest != nil {
+		manifest.Checksums[name] = checksum(content)
+	}
+
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tw.Write(content)
+	return err
+}
+
+// checksum 计算条目内容的SHA-256摘要
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// readArchive 解密归档并读取全部条目，校验清单、条目摘要与条目一致
+func readArchive(passphrase string, r io.Reader) (*Manifest, map[string][]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	archive, err := open(passphrase, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gz.Close()
+
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name := path.Clean(header.Name)
+		if strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			return nil, nil, fmt.Errorf("invalid entry %s in the backup", header.Name)
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries[name] = content
+	}
+
+	payload, ok := entries[manifestEntry]
+	if !ok {
+		return nil, nil, errors.New("the backup manifest is missing")
+	}
+	var manifest Manifest
+	err = json.Unmarshal(payload, &manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if manifest.Format != FormatName || manifest.Version != FormatVersion {
+		return nil, nil, errors.New("unsupported backup format")
+	}
+
+	// 归档中的每个条目都必须登记在清单中且摘要一致
+	for name, content := range entries {
+		if name == manifestEntry {
+			continue
+		}
+		sum, ok := manifest.Checksums[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unexpected entry %s in the backup", name)
+		}
+		if sum != checksum(content) {
+			return nil, nil, fmt.Errorf("checksum mismatch of entry %s", name)
+		}
+	}
+	if len(manifest.Checksums) != len(entries)-1 {
+		return nil, nil, errors.New("the backup is incomplete")
+	}
+
+	for _, table := range manifest.Tables {
+		if _, ok := entries[tablesPrefix+table.Name+".json"]; !ok {
+			return nil, nil, fmt.Errorf("the rows of table %s are missing", table.Name)
+		}
+	}
+	for _, icon := range manifest.Icons {
+		if _, ok := entries[iconsPrefix+icon]; !ok {
+			return nil, nil, fmt.Errorf("the icon %s is missing", icon)
+		}
+	}
+
+	return &manifest, entries, nil
+}
+
+// decodeRows 解析数据表的行，并按列类型还原时间与数值
+func decodeRows(db *gorm.DB, table string, payload []byte) ([]map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var rows []map[string]interface{}
+	err := decoder.Decode(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, err
+	}
+	timeColumns := make(map[string]bool)
+	for _, columnType := range columnTypes {
+		typeName := strings.ToLower(columnType.DatabaseTypeName())
+		if strings.Contains(typeName, "date") || strings.Contains(typeName, "time") {
+			timeColumns[columnType.Name()] = true
+		}
+	}
+
+	for _, row := range rows {
+		for column, value := range row {
+			switch v := value.(type) {
+			case json.Number:
+				if i, err := v.Int64(); err == nil {
+					row[column] = i
+				} else if f, err := v.Float64(); err == nil {
+					row[column] = f
+				}
+			case string:
+				if timeColumns[column] {
+					t, err := time.Parse(time.RFC3339Nano, v)
+					if err != nil {
+						return nil, fmt.Errorf("invalid time value in column %s", column)
+					}
+					row[column] = t
+				}
+			}
+		}
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"bytes"
+	"cyber-life/pkg/encrypt"
+	"errors"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 23:30
+// @Desc:	备份文件的口令加密：scrypt派生密钥，AES-GCM加密整个归档，文件头作为附加数据参与认证
+
+const (
+	containerMagic   = "CLBACKUP"
+	containerVersion = 1
+	saltSize         = 16
+
+	// headerSize 魔数、容器版本、scrypt参数(logN, r, p)与盐
+	headerSize = len(containerMagic) + 4 + saltSize
+
+	MinPassphraseLength = 8
+)
+
+var (
+	ErrNotBackup         = errors.New("the file is not an encrypted backup")
+	ErrInvalidPassphrase = errors.New("invalid backup passphrase or the backup is corrupted")
+)
+
+// seal 使用口令加密归档内容
+func seal(passphrase string, archive []byte) ([]byte, error) {
+	salt, err := encrypt.RandomBytes(saltSize)
+	if err != nil {
+		return nil, err
+	}
+
+	params := encrypt.DefaultScryptParams
+	header := make([]byte, 0, headerSize)
+	header = append(header, containerMagic...)
+	header = append(header, containerVersion, params.LogN, params.R, params.P)
+	header = append(header, salt...)
+
+	key, err := encrypt.ScryptKey([]byte(passphrase), salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := encrypt.AesGcmEncryptWithAD(key, archive, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, data...), nil
+}
+
+// open 解析文件头并使用口令解密归档内容，口令错误或内容被篡改时返回 ErrInvalidPassphrase
+func open(passphrase string, data []byte) ([]byte, error) {
+	if len(data) < headerSize || !bytes.HasPrefix(data, []byte(containerMagic)) {
+		return nil, ErrNotBackup
+	}
+
+	header := data[:headerSize]
+	offset := len(containerMagic)
+	if header[offset] != containerVersion {
+		return nil, errors.New("unsupported backup container version")
+	}
+
+	params := encrypt.ScryptParams{
+		LogN:   header[offset+1],
+		R:      header[offset+2],
+		P:      header[offset+3],
+		KeyLen: encrypt.DefaultScryptParams.KeyLen,
+	}
+	// 限制派生参数，避免构造的文件头耗尽内存
+	if params.LogN < 10 || params.LogN > 20 || params.R == 0 || params.R > 32 || params.P == 0 || params.P > 16 {
+		return nil, errors.New("invalid backup key derivation parameters")
+	}
+	salt := header[offset+4:]
+
+	key, err := encrypt.ScryptKey([]byte(passphrase), salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := encrypt.AesGcmDecryptWithAD(key, data[headerSize:], header)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	return archive, nil
+}
@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 00:10
+// @Desc:	快照保留策略：按天、周、月分别保留最近若干份快照（祖父-父-子轮换）
+
+// Retention 快照保留策略
+type Retention struct {
+	KeepDaily   int // 保留最近多少天的每日快照（每天最新的一份）
+	KeepWeekly  int // 保留最近多少周的每周快照（每周最新的一份）
+	KeepMonthly int // 保留最近多少月的每月快照（每月最新的一份）
+}
+
+// Prune 按保留策略删除目录下多余的快照，最新的一份始终保留；返回被删除的快照文件名
+func Prune(dir string, policy Retention) ([]string, error) {
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := retainedSnapshots(snapshots, policy)
+
+	var removed []string
+	for _, snapshot := range snapshots {
+		if keep[snapshot.Name] {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(dir, snapshot.Name))
+		if err != nil {
+			return removed, err
+		}
+		removed = append(removed, snapshot.Name)
+	}
+
+	return removed, nil
+}
+
+// retainedSnapshots 计算需要保留的快照，snapshots 须按创建时间由新到旧排序
+func retainedSnapshots(snapshots []SnapshotFile, policy Retention) map[string]bool {
+	keep := make(map[string]bool)
+	if len(snapshots) == 0 {
+		return keep
+	}
+	keep[snapshots[0].Name] = true
+
+	buckets := []struct {
+		limit int
+		key   func(snapshot SnapshotFile) string
+	}{
+		{policy.KeepDaily, func(snapshot SnapshotFile) string {
+			return snapshot.CreatedAt.Format("2006-01-02")
+		}},
+		{policy.KeepWeekly, func(snapshot SnapshotFile) string {
+			year, week := snapshot.CreatedAt.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{policy.KeepMonthly, func(snapshot SnapshotFile) string {
+			return snapshot.CreatedAt.Format("2006-01")
+		}},
+	}
+
+	for _, bucket := range buckets {
+		seen := make(map[string]bool)
+		for _, snapshot := range snapshots {
+			if len(seen) >= bucket.limit {
+				break
+			}
+
+			key := bucket.key(snapshot)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			keep[snapshot.Name] = true
+		}
+	}
+
+	return keep
+}
@@ -0,0 +1,139 @@
+package backup
+
+import (
+	"cyber-life/pkg/logger"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 00:10
+// @Desc:	定时自动备份：按固定间隔生成数据库快照并执行保留策略，记录最近一次备份的结果
+
+// Status 自动备份状态
+type Status struct {
+	Enabled        bool       `json:"enabled"`
+	Dir            string     `json:"dir"`
+	Interval       string     `json:"interval"`
+	Running        bool       `json:"running"`
+	LastStartedAt  *time.Time `json:"last_started_at"`
+	LastFinishedAt *time.Time `json:"last_finished_at"`
+	LastSuccessAt  *time.Time `json:"last_success_at"`
+	LastFile       string     `json:"last_file"`
+	LastSize       int64      `json:"last_size"`
+	LastError      string     `json:"last_error"`
+	NextRunAt      *time.Time `json:"next_run_at"`
+}
+
+type scheduler struct {
+	mu     sync.Mutex
+	status Status
+}
+
+var sched scheduler
+
+// StartScheduler 启动后台协程定时生成快照；若最近一份快照已超过备份间隔则立即备份一次
+func StartScheduler(db *gorm.DB, dir string, interval time.Duration, policy Retention) {
+	sched.mu.Lock()
+	sched.status.Enabled = true
+	sched.status.Dir = dir
+	sched.status.Interval = interval.String()
+	sched.mu.Unlock()
+
+	delay := time.Duration(0)
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		logger.Error("an error occurred while listing the backup snapshots: ", err)
+	} else if len(snapshots) > 0 {
+		delay = max(interval-time.Since(snapshots[0].CreatedAt), 0)
+	}
+
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		sched.setNextRun(time.Now().Add(delay))
+		for range timer.C {
+			runScheduledBackup(db, dir, policy)
+
+			timer.Reset(interval)
+			sched.setNextRun(time.Now().Add(interval))
+		}
+	}()
+}
+
+// CurrentStatus 查询自动备份状态
+func CurrentStatus() Status {
+	sched.mu.Lock()
+	defer sched.mu.Unlock()
+
+	return sched.status
+}
+
+// runScheduledBackup 执行一次快照与保留策略，并记录结果
+func runScheduledBackup(db *gorm.DB, dir string, policy Retention) {
+	startedAt := time.Now()
+	sched.mu.Lock()
+	sched.status.Running = true
+	sched.status.LastStartedAt = &startedAt
+	sched.mu.Unlock()
+
+	file, size, err := takeSnapshot(db, dir, policy)
+
+	finishedAt := time.Now()
+	sched.mu.Lock()
+	sched.status.Running = false
+	sched.status.LastFinishedAt = &finishedAt
+	if err != nil {
+		sched.status.LastError = err.Error()
+	} else {
+		sched.status.LastSuccessAt = &finishedAt
+		sched.status.LastFile = file
+		sched.status.LastSize = size
+		sched.status.LastError = ""
+	}
+	sched.mu.Unlock()
+
+	if err != nil {
+		logger.Error("an error occurred while taking the scheduled backup: ", err)
+		return
+	}
+	logger.Info("the scheduled backup has been written to ", file, " (", size, " bytes) in ", finishedAt.Sub(startedAt))
+}
+
+// takeSnapshot 生成快照后执行保留策略，返回快照文件名与大小
+func takeSnapshot(db *gorm.DB, dir string, policy Retention) (string, int64, error) {
+	path, err := Snapshot(db, dir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	name := filepath.Base(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return name, 0, err
+	}
+	size := info.Size()
+
+	removed, err := Prune(dir, policy)
+	if err != nil {
+		return name, size, err
+	}
+	for _, old := range removed {
+		logger.Info("removed the expired backup snapshot ", old)
+	}
+
+	return name, size, nil
+}
+
+// setNextRun 记录下一次备份时间
+func (s *scheduler) setNextRun(at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status.NextRunAt = &at
+}
@@ -0,0 +1,301 @@
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 00:10
+// @Desc:	数据库快照：sqlite 使用 VACUUM INTO 在线生成一致的数据库副本，mysql 生成逻辑SQL转储
+
+const (
+	snapshotPrefix     = "cyber-life_"
+	snapshotTimeLayout = "20060102_150405"
+	sqliteSnapshotExt  = ".db"
+	mysqlSnapshotExt   = ".sql.gz"
+	dumpBatchSize      = 100
+)
+
+// SnapshotFile 快照文件
+type SnapshotFile struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Snapshot 在指定目录下生成数据库快照，返回快照文件路径
+func Snapshot(db *gorm.DB, dir string) (string, error) {
+	var ext string
+	switch db.Dialector.Name() {
+	case "sqlite":
+		ext = sqliteSnapshotExt
+	case "mysql":
+		ext = mysqlSnapshotExt
+	default:
+		return "", errors.New("invalid database type")
+	}
+
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(dir, snapshotPrefix+time.Now().Format(snapshotTimeLayout)+ext)
+	if _, err = os.Stat(target); err == nil {
+		return "", fmt.Errorf("the snapshot %s already exists", target)
+	}
+
+	// 先写入临时文件，完成后再重命名，避免留下不完整的快照
+	temp := target + ".tmp"
+	os.Remove(temp)
+
+	if ext == sqliteSnapshotExt {
+		err = db.Exec("VACUUM INTO ?", temp).Error
+	} else {
+		err = dumpToFile(db, temp)
+	}
+	if err == nil {
+		err = os.Chmod(temp, 0600)
+	}
+	if err == nil {
+		err = os.Rename(temp, target)
+	}
+	if err != nil {
+		os.Remove(temp)
+		return "", err
+	}
+
+	return target, nil
+}
+
+// ListSnapshots 列出目录下的快照文件，按创建时间由新到旧排序
+func ListSnapshots(dir string) ([]SnapshotFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SnapshotFile{}, nil
+		}
+		return nil, err
+	}
+
+	snapshots := make([]SnapshotFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		createdAt, ok := parseSnapshotName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, SnapshotFile{
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// parseSnapshotName 从快照文件名解析创建时间，非快照文件返回false
+func parseSnapshotName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, snapshotPrefix) {
+		return time.Time{}, false
+	}
+
+	stamp := strings.TrimPrefix(name, snapshotPrefix)
+	switch {
+	case strings.HasSuffix(stamp, sqliteSnapshotExt):
+		stamp = strings.TrimSuffix(stamp, sqliteSnapshotExt)
+	case strings.HasSuffix(stamp, mysqlSnapshotExt):
+		stamp = strings.TrimSuffix(stamp, mysqlSnapshotExt)
+	default:
+		return time.Time{}, false
+	}
+
+	createdAt, err := time.ParseInLocation(snapshotTimeLayout, stamp, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
+
+// dumpToFile 将mysql数据库的结构与数据转储为gzip压缩的SQL文件
+func dumpToFile(db *gorm.DB, path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(file)
+	err = dumpSQL(db, gz)
+	if err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+
+	return err
+}
+
+// dumpSQL 逐表写出建表语句与批量插入语句
+func dumpSQL(db *gorm.DB, w io.Writer) error {
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+	sort.Strings(tables)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "-- cyber-life logical dump, created at %s\n\n", time.Now().Format(time.RFC3339))
+	fmt.Fprint(bw, "SET NAMES utf8mb4;\nSET FOREIGN_KEY_CHECKS = 0;\n\n")
+
+	for _, table := range tables {
+		var name, ddl string
+		err = db.Raw("SHOW CREATE TABLE "+quoteIdentifier(table)).Row().Scan(&name, &ddl)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, "DROP TABLE IF EXISTS %s;\n%s;\n\n", quoteIdentifier(table), ddl)
+
+		err = dumpRows(db, bw, table)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprint(bw, "SET FOREIGN_KEY_CHECKS = 1;\n")
+	return bw.Flush()
+}
+
+// dumpRows 写出数据表的全部行，每批生成一条插入语句
+func dumpRows(db *gorm.DB, w io.Writer, table string) error {
+	rows, err := db.Table(table).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", quoteIdentifier(table), strings.Join(quoted, ", "))
+
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	count := 0
+	for rows.Next() {
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return err
+		}
+
+		if count%dumpBatchSize == 0 {
+			if count > 0 {
+				fmt.Fprint(w, ";\n")
+			}
+			fmt.Fprint(w, insert)
+		} else {
+			fmt.Fprint(w, ",\n")
+		}
+
+		literals := make([]string, len(values))
+		for i, value := range values {
+			literals[i] = sqlLiteral(value)
+		}
+		fmt.Fprintf(w, "(%s)", strings.Join(literals, ", "))
+		count++
+	}
+	if count > 0 {
+		fmt.Fprint(w, ";\n\n")
+	}
+
+	return rows.Err()
+}
+
+// quoteIdentifier 以反引号包裹mysql标识符
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// sqlLiteral 将扫描得到的列值转换为mysql字面量
+func sqlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int64, int32, int, uint64, uint32, uint, float64, float32:
+		return fmt.Sprint(v)
+	case time.Time:
+		return "'" + v.Format("2006-01-02 15:04:05.999999") + "'"
+	case []byte:
+		return quoteString(string(v))
+	case string:
+		return quoteString(v)
+	default:
+		return quoteString(fmt.Sprint(v))
+	}
+}
+
+// quoteString 转义并以单引号包裹字符串
+func quoteString(value string) string {
+	var builder strings.Builder
+	builder.Grow(len(value) + 2)
+	builder.WriteByte('\'')
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case 0:
+			builder.WriteString(`\0`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\x1a':
+			builder.WriteString(`\Z`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\'':
+			builder.WriteString(`\'`)
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	builder.WriteByte('\'')
+	return builder.String()
+}
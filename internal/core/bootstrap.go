@@ -1,11 +1,19 @@
 package core
 
 import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/core/backup"
 	"cyber-life/internal/core/config"
 	"cyber-life/internal/core/initialize"
+	"cyber-life/internal/core/vault"
 	"cyber-life/internal/repository"
 	"cyber-life/pkg/logger"
 	"fmt"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+	commonservice "cyber-life/internal/service/common"
+	systemservice "cyber-life/internal/service/system"
 )
 
 // @Author: yv1ing
@@ -30,6 +38,32 @@ func Start() {
 		return
 	}
 
+	// 凭据保险库以封存状态启动，需通过接口解封；闲置超时后自动封存
+	if config.Config.Vault.AutoLockMinutes > 0 {
+		idle := time.Duration(config.Config.Vault.AutoLockMinutes) * time.Minute
+		vault.StartAutoSeal(idle, func() {
+			logger.Info("the vault has been sealed after being idle for ", idle)
+
+			err := systemservice.RecordAuditEvent(&systemmodel.AuditEvent{
+				Actor:   "system",
+				Action:  "vault.lock",
+				Outcome: constant.AUDIT_OUTCOME_SUCCESS,
+				Detail:  "sealed after being idle for " + idle.String(),
+			})
+			if err != nil {
+				logger.Error("an error occurred while recording the audit event: ", err)
+			}
+		})
+	}
+	logger.Info("the vault is sealed, unlock it via /api/vault/unlock")
+
+	// 初始化角色与权限
+	err = initialize.InitRoles()
+	if err != nil {
+		logger.Error("an error occurred while initializing the roles: ", err)
+		return
+	}
+
 	// 初始化系统用户
 	err = initialize.InitSystemUser()
 	if err != nil {
@@ -37,8 +71,48 @@ func Start() {
 		return
 	}
 
+	// 启动定时自动备份
+	if config.Config.Backup.Enabled {
+		interval := time.Duration(config.Config.Backup.IntervalHours) * time.Hour
+		if interval <= 0 {
+			interval = 24 * time.Hour
+		}
+		backup.StartScheduler(db, config.Config.Backup.Dir, interval, backup.Retention{
+			KeepDaily:   config.Config.Backup.KeepDaily,
+			KeepWeekly:  config.Config.Backup.KeepWeekly,
+			KeepMonthly: config.Config.Backup.KeepMonthly,
+		})
+		logger.Info("scheduled backups are enabled, writing snapshots to ", config.Config.Backup.Dir, " every ", interval)
+	}
+
+	// 定时清理回收站中超过保留期限的记录
+	if config.Config.Trash.RetentionDays > 0 {
+		retention := time.Duration(config.Config.Trash.RetentionDays) * 24 * time.Hour
+		commonservice.StartTrashPurge(retention, func(resourceType string, count int64, err error) {
+			if err != nil {
+				logger.Error("an error occurred while purging the expired "+resourceType+" in the trash: ", err)
+				return
+			}
+			logger.Info("purged ", count, " ", resourceType, " kept in the trash for more than ", retention)
+
+			err = systemservice.RecordAuditEvent(&systemmodel.AuditEvent{
+				Actor:   "system",
+				Action:  resourceType + ".purge",
+				Outcome: constant.AUDIT_OUTCOME_SUCCESS,
+				Detail:  fmt.Sprintf("%d expired records purged from the trash", count),
+			})
+			if err != nil {
+				logger.Error("an error occurred while recording the audit event: ", err)
+			}
+		})
+	}
+
 	// 启动Web服务引擎
-	eng := initialize.InitWebEngine()
+	eng, err := initialize.InitWebEngine()
+	if err != nil {
+		logger.Error("an error occurred while initializing the web service engine: ", err)
+		return
+	}
 	listenAddr := fmt.Sprintf("%s:%d", config.Config.ListenAddr, config.Config.ListenPort)
 
 	logger.Info("starting the web service engine, listening on ", listenAddr)
@@ -0,0 +1,15 @@
+package config
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 00:10
+// @Desc:	自动备份配置
+
+type backupConfig struct {
+	Enabled       bool   // 是否启用定时自动备份
+	Dir           string // 快照存放目录
+	IntervalHours int    // 备份间隔（小时）
+	KeepDaily     int    // 保留最近多少天的每日快照
+	KeepWeekly    int    // 保留最近多少周的每周快照
+	KeepMonthly   int    // 保留最近多少月的每月快照
+}
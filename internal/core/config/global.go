@@ -6,12 +6,19 @@ package config
 // @Desc:	系统全局配置
 
 type globalConfig struct {
-	Mode       string
-	SecretKey  string
-	ListenAddr string
-	ListenPort int
-	User       userConfig
-	Database   databaseConfig
+	Mode           string
+	SecretKey      string
+	ListenAddr     string
+	ListenPort     int
+	TrustedProxies []string // 受信任的反向代理地址或网段，缺省不信任任何代理
+	User           userConfig
+	Database       databaseConfig
+	Vault          vaultConfig
+	Session        sessionConfig
+	Reveal         revealConfig
+	Login          loginConfig
+	Backup         backupConfig
+	Trash          trashConfig
 }
 
 var Config globalConfig
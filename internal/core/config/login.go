@@ -0,0 +1,14 @@
+package config
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 21:40
+// @Desc:	登录防爆破配置
+
+type loginConfig struct {
+	MaxFailures        int // 同一用户名连续失败多少次后临时锁定账户
+	IPMaxFailures      int // 同一IP连续失败多少次后临时封禁该IP
+	LockoutMinutes     int // 临时锁定时长（分钟），同时作为连续失败的统计窗口
+	BackoffBaseSeconds int // 首次失败后的退避等待（秒），此后每次失败翻倍
+	BackoffMaxSeconds  int // 退避等待上限（秒）
+}
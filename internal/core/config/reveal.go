@@ -0,0 +1,10 @@
+package config
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 21:05
+// @Desc:	敏感字段查看配置
+
+type revealConfig struct {
+	RequirePassword bool // 查看敏感字段明文前是否要求重新输入登录口令
+}
@@ -0,0 +1,11 @@
+package config
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 17:16
+// @Desc:	登录会话配置
+
+type sessionConfig struct {
+	AccessTokenMinutes int // 访问令牌有效期（分钟）
+	RefreshTokenDays   int // 刷新令牌有效期（天），每次刷新后重新计算
+}
@@ -0,0 +1,10 @@
+package config
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 19:10
+// @Desc:	回收站配置
+
+type trashConfig struct {
+	RetentionDays int // 回收站中的记录保留天数，超过后由后台任务彻底删除，0表示不自动清理
+}
@@ -0,0 +1,10 @@
+package config
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 11:32
+// @Desc:	凭据保险库配置
+
+type vaultConfig struct {
+	AutoLockMinutes int // 闲置多少分钟后自动封存保险库，0表示不自动封存
+}
@@ -2,6 +2,7 @@ package initialize
 
 import (
 	"cyber-life/internal/core/config"
+	"cyber-life/internal/core/migration"
 	"errors"
 	"fmt"
 	"github.com/glebarez/sqlite"
@@ -11,9 +12,6 @@ import (
 	"log"
 	"os"
 	"time"
-
-	commonmodel "cyber-life/internal/model/common"
-	systemmodel "cyber-life/internal/model/system"
 )
 
 // @Author: yv1ing
@@ -21,7 +19,55 @@ import (
 // @Date:   2025/10/28 11:36
 // @Desc:	初始化数据库连接
 
+// InitDatabase 连接数据库并执行全部待执行的迁移，数据库版本高于程序时拒绝启动
 func InitDatabase() (*gorm.DB, error) {
+	db, err := OpenDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = migration.Up(db, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// MigrateDatabase 手动升级或回滚数据库，target小于0时升级至最新版本或回滚一个版本；
+// 返回执行前的数据库版本及已执行（dryRun时为待执行）的迁移
+func MigrateDatabase(direction string, target int, dryRun bool) (int, []migration.Migration, error) {
+	db, err := OpenDatabase()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	current, err := migration.CurrentVersion(db)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var pending []migration.Migration
+	switch direction {
+	case "up":
+		if target < 0 {
+			target = 0
+		}
+		pending, err = migration.Up(db, target, dryRun)
+	case "down":
+		if target < 0 {
+			target = max(current-1, 0)
+		}
+		pending, err = migration.Down(db, target, dryRun)
+	default:
+		err = errors.New("invalid migration direction")
+	}
+
+	return current, pending, err
+}
+
+// OpenDatabase 根据配置建立数据库连接，不执行迁移
+func OpenDatabase() (*gorm.DB, error) {
 	var (
 		db  *gorm.DB
 		dsn string
@@ -73,38 +119,5 @@ func InitDatabase() (*gorm.DB, error) {
 		return nil, errors.New("invalid database type")
 	}
 
-	// 创建数据表
-	// TODO: 根据实际情况确定是否需要重建表
-	err = recreateTables(
-		db,
-		&systemmodel.User{},
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	err = createTables(
-		db,
-		&commonmodel.Account{},
-		&commonmodel.Secret{},
-		&commonmodel.Host{},
-		&commonmodel.Site{},
-	)
-	if err != nil {
-		return nil, err
-	}
-
 	return db, nil
 }
-
-func recreateTables(db *gorm.DB, models ...interface{}) error {
-	err := db.Migrator().DropTable(models...)
-	if err != nil {
-		return err
-	}
-	return createTables(db, models...)
-}
-
-func createTables(db *gorm.DB, models ...interface{}) error {
-	return db.AutoMigrate(models...)
-}
@@ -11,11 +11,18 @@ import (
 // @Date:   2025/10/28 11:27
 // @Desc:	初始化Web服务引擎
 
-func InitWebEngine() *gin.Engine {
+func InitWebEngine() (*gin.Engine, error) {
 	gin.SetMode(config.Config.Mode)
 
 	eng := gin.New()
+
+	// 登录防护按客户端IP计数，未配置受信任代理时一律使用连接的对端地址，避免伪造请求头绕过或嫁祸
+	err := eng.SetTrustedProxies(config.Config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	router.InitRouter(eng)
 
-	return eng
+	return eng, nil
 }
@@ -0,0 +1,14 @@
+package initialize
+
+import (
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 18:35
+// @Desc:	初始化角色与权限
+
+func InitRoles() error {
+	return systemservice.InitRoles()
+}
@@ -1,8 +1,12 @@
 package initialize
 
 import (
+	"cyber-life/internal/constant"
 	"cyber-life/internal/core/config"
+	"cyber-life/internal/errs"
+	"errors"
 
+	commonservice "cyber-life/internal/service/common"
 	systemservice "cyber-life/internal/service/system"
 )
 
@@ -11,13 +15,54 @@ import (
 // @Date:   2025/10/28 14:50
 // @Desc:	初始化系统用户
 
+// InitSystemUser 系统用户不存在时按配置文件创建并授予管理员角色，已存在但没有角色时补授管理员角色，并接管没有所有者的历史记录
 func InitSystemUser() error {
-	return systemservice.CreateUser(
+	adminRole, err := systemservice.FindRoleByName(constant.ROLE_ADMIN)
+	if err != nil {
+		return err
+	}
+
+	// 系统用户已存在时保持口令与资料不变，避免重启覆盖在系统中修改过的内容；
+	// 引入角色之前创建的系统用户没有角色，补授管理员角色，否则升级后无任何权限且无法再分配角色
+	user, err := systemservice.FindUserByUsername(config.Config.User.Username)
+	if err == nil {
+		if user.RoleID == 0 {
+			err = systemservice.UpdateUser(0, nil, user.ID, "", "", "", "", "", "", adminRole.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return claimUnownedRecords()
+	}
+	if !errors.Is(err, errs.ErrRecordNotFound) {
+		return err
+	}
+
+	err = systemservice.CreateUser(
+		0,
+		nil,
 		config.Config.User.Username,
 		config.Config.User.Password,
 		config.Config.User.Name,
 		config.Config.User.Email,
 		config.Config.User.Phone,
 		config.Config.User.Avatar,
+		adminRole.ID,
 	)
+	if err != nil {
+		return err
+	}
+
+	return claimUnownedRecords()
+}
+
+// claimUnownedRecords 没有所有者的历史记录归属给系统用户
+func claimUnownedRecords() error {
+	user, err := systemservice.FindUserByUsername(config.Config.User.Username)
+	if err != nil {
+		return err
+	}
+
+	_, err = commonservice.ClaimUnownedRecords(user.ID)
+	return err
 }
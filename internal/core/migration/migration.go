@@ -0,0 +1,160 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:10
+// @Desc:	数据库版本化迁移：按版本号顺序执行升级/回滚，执行记录保存在schema_migrations表中
+
+// Migration 单个迁移版本，Up/Down 在同一事务中与迁移记录一起提交
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// String 迁移的展示名称，例如 0001_create_initial_schema
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Latest 当前程序支持的最新迁移版本
+func Latest() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// All 全部迁移版本，按版本号升序
+func All() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// CurrentVersion 数据库当前的迁移版本，未执行过任何迁移时为0
+func CurrentVersion(db *gorm.DB) (int, error) {
+	err := db.AutoMigrate(&systemmodel.SchemaMigration{})
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = db.Model(&systemmodel.SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// Check 校验数据库版本不高于程序支持的最新版本，避免旧程序操作新结构的数据库
+func Check(db *gorm.DB) error {
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > Latest() {
+		return fmt.Errorf("the database schema version %d is newer than this binary supports (%d)", current, Latest())
+	}
+
+	return nil
+}
+
+// Up 依次执行高于当前版本且不高于target的迁移，target为0表示升级至最新版本；dryRun时只返回待执行的迁移
+func Up(db *gorm.DB, target int, dryRun bool) ([]Migration, error) {
+	err := Check(db)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if target == 0 {
+		target = Latest()
+	}
+	if target < current {
+		return nil, errors.New("the target version is lower than the current version")
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > current && m.Version <= target {
+			pending = append(pending, m)
+		}
+	}
+	if dryRun {
+		return pending, nil
+	}
+
+	for _, m := range pending {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			err := m.Up(tx)
+			if err != nil {
+				return err
+			}
+			return tx.Create(&systemmodel.SchemaMigration{
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return nil, fmt.Errorf("migration %s failed: %w", m, err)
+		}
+	}
+
+	return pending, nil
+}
+
+// Down 按版本倒序回滚高于target的迁移；dryRun时只返回待回滚的迁移
+func Down(db *gorm.DB, target int, dryRun bool) ([]Migration, error) {
+	err := Check(db)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if target < 0 || target > current {
+		return nil, errors.New("the target version is out of range")
+	}
+
+	var pending []Migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > target && m.Version <= current {
+			pending = append(pending, m)
+		}
+	}
+	if dryRun {
+		return pending, nil
+	}
+
+	for _, m := range pending {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			err := m.Down(tx)
+			if err != nil {
+				return err
+			}
+			return tx.Delete(&systemmodel.SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return nil, fmt.Errorf("rollback of migration %s failed: %w", m, err)
+		}
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,338 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 23:10
+// @Desc:	已发布迁移的表结构快照：迁移内声明与发布时一致的结构体，不引用会随版本演进的业务模型
+
+// 快照结构体的类型名决定表名、关联表的外键列与约束名，须与发布时的模型同名，因此声明在各迁移函数内部
+
+// createInitialSchema 版本1的初始结构
+func createInitialSchema(tx *gorm.DB) error {
+	type User struct {
+		gorm.Model
+
+		Username string `gorm:"index"`
+		Password string
+
+		Name   string `gorm:"index"`
+		Email  string
+		Phone  string
+		Avatar string
+
+		IsActive bool
+		RoleID   uint `gorm:"index"`
+
+		TotpEnabled  bool
+		TotpSecret   string
+		TotpLastStep int64
+	}
+
+	type Account struct {
+		gorm.Model
+
+		OwnerID uint `gorm:"index"`
+
+		Type          string `gorm:"index"`
+		Platform      string `gorm:"index"`
+		PlatformURL   string `gorm:"index"`
+		Username      string `gorm:"index"`
+		Password      string
+		SecurityEmail string
+		SecurityPhone string
+		Remark        string
+		Logo          string
+	}
+
+	type Secret struct {
+		gorm.Model
+
+		OwnerID uint `gorm:"index"`
+
+		Platform    string `gorm:"index"`
+		PlatformURL string `gorm:"index"`
+		KeyID       string
+		KeySecret   string
+		Remark      string
+		Logo        string
+	}
+
+	type Host struct {
+		gorm.Model
+
+		OwnerID uint `gorm:"index"`
+
+		Provider       string            `gorm:"index"`
+		ProviderURL    string            `gorm:"index"`
+		Hostname       string            `gorm:"index"`
+		Address        string            `gorm:"index"`
+		Ports          map[string]string `gorm:"serializer:json"`
+		Username       string            `gorm:"index"`
+		Password       string
+		OS             string
+		Logo           string
+		CpuNum         int
+		RamSize        int
+		DiskSize       int
+		ExpirationTime int64
+	}
+
+	type Site struct {
+		gorm.Model
+
+		OwnerID uint `gorm:"index"`
+
+		Name string `gorm:"index"`
+		Logo string
+		URL  string
+	}
+
+	type VaultKey struct {
+		gorm.Model
+
+		Salt       string
+		Time       uint32
+		Memory     uint32
+		Threads    uint8
+		WrappedKey string
+	}
+
+	type RecoveryCode struct {
+		gorm.Model
+
+		UserID   uint   `gorm:"index"`
+		CodeHash string `gorm:"index"`
+		UsedAt   *time.Time
+	}
+
+	type Session struct {
+		gorm.Model
+
+		UserID    uint `gorm:"index"`
+		Device    string
+		IP        string
+		UserAgent string
+
+		RefreshHash     string `gorm:"index"`
+		PrevRefreshHash string `gorm:"index"`
+
+		LastSeenAt time.Time
+		ExpiresAt  time.Time
+		RevokedAt  *time.Time
+	}
+
+	type Permission struct {
+		gorm.Model
+
+		Code        string `gorm:"size:64;uniqueIndex"`
+		Description string
+	}
+
+	type Role struct {
+		gorm.Model
+
+		Name        string `gorm:"size:64;uniqueIndex"`
+		Description string
+		BuiltIn     bool
+
+		Permissions []Permission `gorm:"many2many:role_permissions"`
+	}
+
+	type GroupMember struct {
+		gorm.Model
+
+		GroupID uint `gorm:"index"`
+		UserID  uint `gorm:"index"`
+	}
+
+	type Group struct {
+		gorm.Model
+
+		Name        string `gorm:"size:64;uniqueIndex"`
+		Description string
+
+		Members []GroupMember
+	}
+
+	type Share struct {
+		gorm.Model
+
+		ResourceType string `gorm:"size:32;index:idx_share_resource"`
+		ResourceID   uint   `gorm:"index:idx_share_resource"`
+		GranteeType  string `gorm:"size:16;index:idx_share_grantee"`
+		GranteeID    uint   `gorm:"index:idx_share_grantee"`
+		Access       string `gorm:"size:16"`
+		GrantedBy    uint
+	}
+
+	type AuditEvent struct {
+		ID        uint      `gorm:"primarykey"`
+		CreatedAt time.Time `gorm:"index"`
+
+		ActorID      uint `gorm:"index"`
+		Actor        string
+		Action       string `gorm:"size:64;index"`
+		ResourceType string `gorm:"size:32;index"`
+		ResourceID   string `gorm:"size:255"`
+		IP           string
+		Outcome      string `gorm:"size:16;index"`
+		Detail       string
+
+		PrevHash string `gorm:"size:64"`
+		Hash     string `gorm:"size:64"`
+	}
+
+	type LoginAttempt struct {
+		ID        uint `gorm:"primarykey"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+
+		Scope   string `gorm:"size:16;uniqueIndex:idx_login_attempt_subject"`
+		Subject string `gorm:"size:191;uniqueIndex:idx_login_attempt_subject"`
+
+		Failures     int
+		LastFailedAt time.Time
+		BlockedUntil time.Time
+		LockedUntil  *time.Time
+	}
+
+	return tx.AutoMigrate(
+		&User{},
+		&Account{},
+		&Secret{},
+		&Host{},
+		&Site{},
+		&VaultKey{},
+		&RecoveryCode{},
+		&Session{},
+		&Role{},
+		&Permission{},
+		&Group{},
+		&GroupMember{},
+		&Share{},
+		&AuditEvent{},
+		&LoginAttempt{},
+	)
+}
+
+// createRevisions 版本2的修订历史表
+func createRevisions(tx *gorm.DB) error {
+	type Revision struct {
+		ID        uint `gorm:"primarykey"`
+		CreatedAt time.Time
+
+		ResourceType string `gorm:"size:32;index:idx_revision_resource"`
+		ResourceID   uint   `gorm:"index:idx_revision_resource"`
+		ActorID      uint
+		Action       string `gorm:"size:16"`
+
+		Data string `gorm:"type:text"`
+	}
+
+	return tx.AutoMigrate(&Revision{})
+}
+
+// createAccessTokens 版本3的个人访问令牌表
+func createAccessTokens(tx *gorm.DB) error {
+	type AccessToken struct {
+		gorm.Model
+
+		UserID    uint     `gorm:"index"`
+		Name      string   `gorm:"size:64"`
+		Hint      string   `gorm:"size:32"`
+		TokenHash string   `gorm:"size:64;uniqueIndex"`
+		Scopes    []string `gorm:"serializer:json;type:text"`
+
+		LastUsedAt *time.Time
+		LastUsedIP string
+		ExpiresAt  time.Time
+		RevokedAt  *time.Time
+	}
+
+	return tx.AutoMigrate(&AccessToken{})
+}
+
+// vaultKeySingletonSchema 版本4的保险库密钥结构
+func vaultKeySingletonSchema() interface{} {
+	type VaultKey struct {
+		gorm.Model
+
+		Salt       string
+		Time       uint32
+		Memory     uint32
+		Threads    uint8
+		WrappedKey string
+		Singleton  *bool `gorm:"uniqueIndex"`
+	}
+
+	return &VaultKey{}
+}
+
+// addVaultKeySingleton 版本4为保险库密钥增加唯一的单例标记，已有多份密钥时标记最早的一份
+func addVaultKeySingleton(tx *gorm.DB) error {
+	vaultKey := vaultKeySingletonSchema()
+	err := tx.AutoMigrate(vaultKey)
+	if err != nil {
+		return err
+	}
+
+	var firstID uint
+	err = tx.Model(vaultKey).Select("COALESCE(MIN(id), 0)").Scan(&firstID).Error
+	if err != nil || firstID == 0 {
+		return err
+	}
+
+	return tx.Model(vaultKey).Where("id = ?", firstID).Update("singleton", true).Error
+}
+
+// dropVaultKeySingleton 回滚版本4，移除单例标记
+func dropVaultKeySingleton(tx *gorm.DB) error {
+	vaultKey := vaultKeySingletonSchema()
+	err := tx.Migrator().DropIndex(vaultKey, "idx_vault_keys_singleton")
+	if err != nil {
+		return err
+	}
+
+	err = tx.Migrator().DropColumn(vaultKey, "Singleton")
+	if err != nil {
+		return err
+	}
+
+	// SQLite删除列时会重建数据表，重建后补回其余索引
+	if !tx.Migrator().HasIndex(vaultKey, "idx_vault_keys_deleted_at") {
+		return tx.Migrator().CreateIndex(vaultKey, "idx_vault_keys_deleted_at")
+	}
+	return nil
+}
+
+// widenAuditResourceID 版本5将审计事件的资源ID改为不限长度的文本，批量操作记录的ID列表可能超过255个字符；
+// SQLite中该列本就是不限长度的text，修改列类型会重建数据表，因此跳过
+func widenAuditResourceID(tx *gorm.DB) error {
+	if tx.Dialector.Name() == "sqlite" {
+		return nil
+	}
+
+	type AuditEvent struct {
+		ResourceID string `gorm:"type:text"`
+	}
+
+	return tx.Migrator().AlterColumn(&AuditEvent{}, "ResourceID")
+}
+
+// narrowAuditResourceID 回滚版本5，恢复资源ID的长度限制
+func narrowAuditResourceID(tx *gorm.DB) error {
+	if tx.Dialector.Name() == "sqlite" {
+		return nil
+	}
+
+	type AuditEvent struct {
+		ResourceID string `gorm:"size:255"`
+	}
+
+	return tx.Migrator().AlterColumn(&AuditEvent{}, "ResourceID")
+}
@@ -0,0 +1,67 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:10
+// @Desc:	迁移版本清单，新增迁移只能追加在末尾且版本号递增，已发布的迁移不可修改
+
+var migrations = []Migration{
+	{
+		// 初始结构：对引入版本化迁移之前由AutoMigrate创建的数据库同样适用
+		Version: 1,
+		Name:    "create_initial_schema",
+		Up:      createInitialSchema,
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(
+				"login_attempts",
+				"audit_events",
+				"shares",
+				"group_members",
+				"groups",
+				"role_permissions",
+				"permissions",
+				"roles",
+				"sessions",
+				"recovery_codes",
+				"vault_keys",
+				"sites",
+				"hosts",
+				"secrets",
+				"accounts",
+				"users",
+			)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_revisions",
+		Up:      createRevisions,
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("revisions")
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_access_tokens",
+		Up:      createAccessTokens,
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("access_tokens")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add_vault_key_singleton",
+		Up:      addVaultKeySingleton,
+		Down:    dropVaultKeySingleton,
+	},
+	{
+		Version: 5,
+		Name:    "widen_audit_resource_id",
+		Up:      widenAuditResourceID,
+		Down:    narrowAuditResourceID,
+	},
+}
@@ -0,0 +1,37 @@
+package vault
+
+import (
+	"cyber-life/pkg/encrypt"
+	"errors"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 10:50
+// @Desc:	数据密钥的信封加密：由主口令派生密钥加密密钥(KEK)，再由KEK包裹数据密钥
+
+const dataKeySize = 32
+
+var ErrInvalidPassphrase = errors.New("invalid vault passphrase")
+
+// NewDataKey 生成新的随机数据密钥
+func NewDataKey() ([]byte, error) {
+	return encrypt.RandomBytes(dataKeySize)
+}
+
+// WrapDataKey 使用主口令派生的KEK包裹数据密钥
+func WrapDataKey(passphrase string, salt []byte, params encrypt.Argon2Params, dataKey []byte) ([]byte, error) {
+	kek := encrypt.Argon2idKey([]byte(passphrase), salt, params)
+	return encrypt.AesGcmEncrypt(kek, dataKey)
+}
+
+// UnwrapDataKey 使用主口令派生的KEK解开数据密钥，口令错误时返回 ErrInvalidPassphrase
+func UnwrapDataKey(passphrase string, salt []byte, params encrypt.Argon2Params, wrappedKey []byte) ([]byte, error) {
+	kek := encrypt.Argon2idKey([]byte(passphrase), salt, params)
+	dataKey, err := encrypt.AesGcmDecrypt(kek, wrappedKey)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	return dataKey, nil
+}
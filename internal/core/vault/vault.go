@@ -0,0 +1,178 @@
+package vault
+
+import (
+	"cyber-life/pkg/encrypt"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 10:35
+// @Desc:	凭据保险库，持有数据密钥并负责敏感字段的加解密
+
+// CipherPrefix 密文前缀，用于区分历史遗留的明文数据
+const CipherPrefix = "enc:v1:"
+
+var ErrVaultSealed = errors.New("the vault is sealed")
+
+type keyring struct {
+	mu         sync.RWMutex
+	dataKey    []byte
+	lastAccess atomic.Int64 // 最近一次使用数据密钥的时间（Unix秒）
+}
+
+var ring keyring
+
+// Unseal 装载数据密钥，解封保险库
+func Unseal(dataKey []byte) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	ring.dataKey = append([]byte(nil), dataKey...)
+	ring.touch()
+}
+
+// Seal 清除内存中的数据密钥，封存保险库
+func Seal() {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	for i := range ring.dataKey {
+		ring.dataKey[i] = 0
+	}
+	ring.dataKey = nil
+}
+
+// StartAutoSeal 启动后台协程，保险库闲置超过指定时长后自动封存
+func StartAutoSeal(idle time.Duration, onSeal func()) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if IsSealed() {
+				continue
+			}
+
+			lastAccess := time.Unix(ring.lastAccess.Load(), 0)
+			if time.Since(lastAccess) >= idle {
+				Seal()
+				if onSeal != nil {
+					onSeal()
+				}
+			}
+		}
+	}()
+}
+
+// IsSealed 保险库是否处于封存状态
+func IsSealed() bool {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	return ring.dataKey == nil
+}
+
+// IsEncrypted 判断字段值是否已被加密
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, CipherPrefix)
+}
+
+// EncryptString 加密字段值，空值与已加密的值保持原样
+func EncryptString(plaintext string) (string, error) {
+	if plaintext == "" || IsEncrypted(plaintext) {
+		return plaintext, nil
+	}
+
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	if ring.dataKey == nil {
+		return "", ErrVaultSealed
+	}
+	ring.touch()
+
+	data, err := encrypt.AesGcmEncrypt(ring.dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return CipherPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecryptString 解密字段值，未加密的历史数据原样返回
+func DecryptString(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, CipherPrefix))
+	if err != nil {
+		return "", err
+	}
+
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+
+	if ring.dataKey == nil {
+		return "", ErrVaultSealed
+	}
+	ring.touch()
+
+	plaintext, err := encrypt.AesGcmDecrypt(ring.dataKey, data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// touch 记录数据密钥的使用时间
+func (r *keyring) touch() {
+	r.lastAccess.Store(time.Now().Unix())
+}
+
+// SealColumn 写库前加密敏感字段，供模型的 BeforeSave 钩子调用
+// 同时兼容结构体写入与 map 形式的部分字段更新
+func SealColumn(tx *gorm.DB, field *string, column string) error {
+	if fields, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+		value, ok := fields[column].(string)
+		if !ok {
+			return nil
+		}
+
+		ciphertext, err := EncryptString(value)
+		if err != nil {
+			return err
+		}
+		fields[column] = ciphertext
+
+		return nil
+	}
+
+	ciphertext, err := EncryptString(*field)
+	if err != nil {
+		return err
+	}
+	*field = ciphertext
+
+	return nil
+}
+
+// OpenColumn 读库后解密敏感字段，供模型的 AfterFind/AfterSave 钩子调用
+func OpenColumn(field *string) error {
+	plaintext, err := DecryptString(*field)
+	if err != nil {
+		return err
+	}
+	*field = plaintext
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package errs
+
+import "cyber-life/internal/constant"
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 22:05
+// @Desc:	各业务模块的错误定义，服务与数据操作层直接返回这些错误，接口层据此生成响应
+
+var (
+	/* 通用错误 */
+
+	ErrInternal         = New(Internal, constant.INTERNAL_ERROR, "system internal error")
+	ErrInvalidParams    = New(Validation, constant.INVALID_REQUEST_PARAMS, "invalid request params")
+	ErrRecordNotFound   = New(NotFound, constant.RECORD_NOT_FOUND, "record not found")
+	ErrPermissionDenied = New(Forbidden, constant.PERMISSION_DENIED, "permission denied")
+
+	/* 操作失败，用于未归类错误的响应 */
+
+	ErrFailedToImport   = New(Internal, constant.FAILED_TO_IMPORT, "import failed")
+	ErrFailedToExport   = New(Internal, constant.FAILED_TO_EXPORT, "export failed")
+	ErrFailedToDelete   = New(Internal, constant.FAILED_TO_DELETE, "delete failed")
+	ErrFailedToFind     = New(Internal, constant.FAILED_TO_FIND, "find failed")
+	ErrFailedToRestore  = New(Internal, constant.FAILED_TO_RESTORE, "restore failed")
+	ErrFailedToPurge    = New(Internal, constant.FAILED_TO_PURGE, "purge failed")
+	ErrFailedToRollback = New(Internal, constant.FAILED_TO_ROLLBACK, "rollback failed")
+
+	/* 认证与会话 */
+
+	ErrInvalidHeader        = New(Unauthorized, constant.INVALID_REQUEST_HEADER, "invalid request header")
+	ErrInvalidToken         = New(Unauthorized, constant.INVALID_TOKEN, "token is invalid")
+	ErrExpiredToken         = New(Unauthorized, constant.EXPIRED_TOKEN, "token has expired")
+	ErrLoginFailed          = New(Unauthorized, constant.FAILED_TO_LOGIN, "incorrect username or password")
+	ErrInvalidRefreshToken  = New(Unauthorized, constant.INVALID_TOKEN, "invalid refresh token")
+	ErrRefreshTokenReused   = New(Unauthorized, constant.INVALID_TOKEN, "refresh token reused")
+	ErrSessionRevoked       = New(Unauthorized, constant.EXPIRED_TOKEN, "the session has been revoked")
+	ErrAccountLocked        = New(Locked, constant.ACCOUNT_LOCKED, "the account is locked")
+	ErrTooManyLoginAttempts = New(TooManyRequests, constant.TOO_MANY_LOGIN_ATTEMPTS, "too many login attempts")
+	ErrReauthRequired       = New(Forbidden, constant.REAUTH_REQUIRED, "re-authentication required")
+	ErrReauthFailed         = New(Forbidden, constant.REAUTH_FAILED, "incorrect password")
+	ErrSessionRequired      = New(Forbidden, constant.SESSION_REQUIRED, "a login session is required")
+	ErrInvalidTokenScope    = New(Validation, constant.INVALID_TOKEN_SCOPE, "invalid access token scope")
+
+	/* 双因素认证 */
+
+	ErrInvalidTotpCode    = New(Forbidden, constant.INVALID_TOTP_CODE, "invalid totp code")
+	ErrTotpAlreadyEnabled = New(Conflict, constant.TOTP_ALREADY_ENABLED, "totp is already enabled")
+	ErrTotpNotEnabled     = New(Conflict, constant.TOTP_NOT_ENABLED, "totp is not enabled")
+	ErrTotpNotSetUp       = New(Conflict, constant.TOTP_NOT_ENABLED, "totp has not been set up")
+
+	/* 凭据保险库 */
+
+	ErrVaultSealed         = New(Locked, constant.VAULT_SEALED, "the vault is sealed")
+	ErrIncorrectPassphrase = New(Forbidden, constant.FAILED_TO_UNLOCK, "incorrect vault passphrase")
+	ErrPassphraseTooShort  = New(Validation, constant.FAILED_TO_UNLOCK, "the vault passphrase is too short")
+
+	/* 用户、角色与用户组 */
+
+	ErrUsernameExists    = New(Conflict, constant.USERNAME_ALREADY_EXISTS, "the username already exists")
+	ErrGroupExists       = New(Conflict, constant.GROUP_ALREADY_EXISTS, "the group already exists")
+	ErrRoleExists        = New(Conflict, constant.ROLE_ALREADY_EXISTS, "the role already exists")
+	ErrRoleInUse         = New(Conflict, constant.ROLE_IN_USE, "the role is in use")
+	ErrRoleImmutable     = New(Forbidden, constant.ROLE_IMMUTABLE, "the role is immutable")
+	ErrUnknownPermission = New(Validation, constant.UNKNOWN_PERMISSION, "unknown permission")
+	ErrLastAdminRequired = New(Conflict, constant.LAST_ADMIN_REQUIRED, "cannot remove the last administrator")
+
+	/* 记录操作 */
+
+	ErrInvalidShareParams = New(Validation, constant.INVALID_REQUEST_PARAMS, "invalid share params")
+	ErrInvalidRevealField = New(Validation, constant.INVALID_REQUEST_PARAMS, "invalid reveal field").WithFields(Field("field", "unsupported reveal field"))
+	ErrRecordNotInTrash   = New(Conflict, constant.RECORD_NOT_IN_TRASH, "record not in trash")
+	ErrRevisionNotFound   = New(NotFound, constant.REVISION_NOT_FOUND, "revision not found")
+	ErrInvalidImportFile  = New(Validation, constant.INVALID_IMPORT_FILE, "invalid import file")
+	ErrInvalidImportKey   = New(Validation, constant.INVALID_IMPORT_CREDENTIALS, "invalid import credentials")
+)
@@ -0,0 +1,167 @@
+package errs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"reflect"
+	"strings"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 22:00
+// @Desc:	类型化的业务错误：按错误类别映射HTTP状态码，并携带信息编码与出错的字段
+
+// Kind 错误类别，决定响应的HTTP状态码
+type Kind int
+
+const (
+	Internal        Kind = iota // 系统内部错误
+	Validation                  // 请求参数不合法
+	Unauthorized                // 请求本身未认证或认证失败，前端据此退出登录；已登录请求中的口令、验证码校验失败不属于此类
+	Forbidden                   // 无权执行操作
+	NotFound                    // 记录不存在
+	Conflict                    // 与现有数据冲突
+	Locked                      // 资源被锁定
+	TooManyRequests             // 请求过于频繁
+)
+
+// Status 错误类别对应的HTTP状态码
+func (k Kind) Status() int {
+	switch k {
+	case Validation:
+		return http.StatusBadRequest
+	case Unauthorized:
+		return http.StatusUnauthorized
+	case Forbidden:
+		return http.StatusForbidden
+	case NotFound:
+		return http.StatusNotFound
+	case Conflict:
+		return http.StatusConflict
+	case Locked:
+		return http.StatusLocked
+	case TooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error 业务错误，Code 为 constant 中的信息编码，Info 为返回给客户端的说明
+type Error struct {
+	Kind   Kind
+	Code   int
+	Info   string
+	Fields []systemmodel.FieldError
+	Data   gin.H
+
+	cause error
+}
+
+// New 创建业务错误
+func New(kind Kind, code int, info string) *Error {
+	return &Error{Kind: kind, Code: code, Info: info}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Info + ": " + e.cause.Error()
+	}
+	return e.Info
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 信息编码与说明相同的业务错误视为同一错误，附加的字段、数据与原因不参与比较
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code && t.Info == e.Info
+}
+
+// WithFields 返回附带出错字段的副本
+func (e *Error) WithFields(fields ...systemmodel.FieldError) *Error {
+	c := *e
+	c.Fields = append(append([]systemmodel.FieldError(nil), e.Fields...), fields...)
+	return &c
+}
+
+// WithData 返回附带响应数据的副本
+func (e *Error) WithData(data gin.H) *Error {
+	c := *e
+	c.Data = data
+	return &c
+}
+
+// Wrap 返回以 cause 为原因的副本，原因仅写入日志，不返回给客户端
+func (e *Error) Wrap(cause error) *Error {
+	c := *e
+	c.cause = cause
+	return &c
+}
+
+// Field 构造单个字段的错误
+func Field(field, message string) systemmodel.FieldError {
+	return systemmodel.FieldError{Field: field, Error: message}
+}
+
+// InvalidField 单个请求字段不合法的错误
+func InvalidField(field, message string) *Error {
+	return ErrInvalidParams.WithFields(Field(field, message))
+}
+
+// As 取出错误链中的业务错误
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// FromBinding 将请求绑定或校验失败的错误转换为参数错误，尽可能列出出错的字段
+func FromBinding(err error) *Error {
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &validationErrors):
+		fields := make([]systemmodel.FieldError, len(validationErrors))
+		for index, validationError := range validationErrors {
+			fields[index] = Field(validationError.Field(), fmt.Sprintf("failed on the '%s' rule", validationError.Tag()))
+		}
+		return ErrInvalidParams.WithFields(fields...).Wrap(err)
+	case errors.As(err, &typeError) && typeError.Field != "":
+		return InvalidField(typeError.Field, "invalid type, expected "+typeError.Type.String()).Wrap(err)
+	default:
+		return ErrInvalidParams.Wrap(err)
+	}
+}
+
+// RegisterJSONFieldNames 令 gin 的参数校验错误以JSON字段名报告字段
+func RegisterJSONFieldNames() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
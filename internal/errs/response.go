@@ -0,0 +1,39 @@
+package errs
+
+import (
+	"cyber-life/pkg/logger"
+	"github.com/gin-gonic/gin"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 22:10
+// @Desc:	将业务错误写入统一格式的错误响应
+
+// Abort 将错误写入响应并中止请求：业务错误按其类别与编码响应，其余错误视为系统内部错误
+func Abort(ctx *gin.Context, err error) {
+	AbortWith(ctx, err, ErrInternal)
+}
+
+// AbortWith 将错误写入响应并中止请求，非业务错误以 fallback 响应；系统内部错误的原因连同请求ID写入日志
+func AbortWith(ctx *gin.Context, err error, fallback *Error) {
+	e, ok := As(err)
+	if !ok {
+		e = fallback.Wrap(err)
+	}
+
+	requestID := ctx.GetString("request_id")
+	if e.Kind == Internal && err != nil {
+		logger.Errorf("request %s %s %s failed: %v", requestID, ctx.Request.Method, ctx.Request.URL.Path, err)
+	}
+
+	ctx.AbortWithStatusJSON(e.Kind.Status(), systemmodel.Response{
+		Code:      e.Code,
+		Info:      e.Info,
+		Data:      e.Data,
+		Errors:    e.Fields,
+		RequestID: requestID,
+	})
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 20:20
+// @Desc:	审计中间件
+
+// auditOutcome 根据响应状态码判断操作结果
+func auditOutcome(status int) string {
+	switch {
+	case status < http.StatusBadRequest:
+		return constant.AUDIT_OUTCOME_SUCCESS
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusLocked || status == http.StatusTooManyRequests:
+		return constant.AUDIT_OUTCOME_DENIED
+	default:
+		return constant.AUDIT_OUTCOME_FAILURE
+	}
+}
+
+// AuditMiddleware 请求处理完成后记录审计事件，需在JwtAuthMiddleware之后、RequirePermission之前执行以便记录被拒绝的访问；
+// 处理函数可通过上下文的 audit_resource_id、audit_detail 补充资源ID与说明
+func AuditMiddleware(action string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		event := &systemmodel.AuditEvent{
+			Actor:      ctx.GetString("username"),
+			Action:     action,
+			ResourceID: ctx.GetString("audit_resource_id"),
+			IP:         ctx.ClientIP(),
+			Outcome:    auditOutcome(ctx.Writer.Status()),
+			Detail:     ctx.GetString("audit_detail"),
+		}
+		if userID, ok := ctx.Get("user_id"); ok {
+			event.ActorID = userID.(uint)
+		}
+
+		err := systemservice.RecordAuditEvent(event)
+		if err != nil {
+			logger.Error("an error occurred while recording the audit event: ", err)
+		}
+	}
+}
@@ -3,15 +3,14 @@ package middleware
 import (
 	"cyber-life/internal/constant"
 	"cyber-life/internal/core/config"
+	"cyber-life/internal/errs"
 	"cyber-life/pkg/auth"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"net/http"
 	"regexp"
 	"strings"
 
-	systemmodel "cyber-life/internal/model/system"
 	systemservice "cyber-life/internal/service/system"
 )
 
@@ -54,55 +53,60 @@ func JwtAuthMiddleware(whitelist []string) gin.HandlerFunc {
 
 		tokenStr := extractBearerToken(ctx)
 		if tokenStr == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, systemmodel.Response{
-				Code: constant.INVALID_REQUEST_HEADER,
-				Info: "invalid request header",
-			})
+			errs.Abort(ctx, errs.ErrInvalidHeader)
+			return
+		}
+
+		// 带有固定前缀的是个人访问令牌，其余按登录JWT校验
+		if strings.HasPrefix(tokenStr, constant.ACCESS_TOKEN_PREFIX) {
+			token, user, err := systemservice.AuthenticateAccessToken(tokenStr, ctx.ClientIP())
+			if err != nil {
+				errs.Abort(ctx, err)
+				return
+			}
+
+			ctx.Set("user_id", user.ID)
+			ctx.Set("username", user.Username)
+			ctx.Set("access_token_id", token.ID)
+			ctx.Set("access_token_scopes", token.Scopes)
+			ctx.Next()
 			return
 		}
 
 		claims, err := auth.ParseAccessToken(tokenStr, config.Config.SecretKey)
 		if err != nil {
 			if errors.Is(err, jwt.ErrTokenExpired) {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, systemmodel.Response{
-					Code: constant.EXPIRED_TOKEN,
-					Info: "token has expired",
-				})
+				errs.Abort(ctx, errs.ErrExpiredToken)
 			} else {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, systemmodel.Response{
-					Code: constant.INVALID_TOKEN,
-					Info: "token is invalid",
-				})
+				errs.Abort(ctx, errs.ErrInvalidToken)
 			}
 			return
 		}
 
-		user, err := systemservice.FindUserByUsername(claims.Username)
+		_, err = systemservice.ValidateSession(claims.SessionID, claims.UserID, ctx.ClientIP())
 		if err != nil {
-			if err.Error() == "record not found" {
-				ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-					Code: constant.INVALID_TOKEN,
-					Info: "token is invalid",
-				})
-				return
-			} else {
-				ctx.AbortWithStatusJSON(http.StatusInternalServerError, systemmodel.Response{
-					Code: constant.INTERNAL_ERROR,
-					Info: "system internal error",
-				})
-				return
+			if errors.Is(err, errs.ErrRecordNotFound) || errors.Is(err, errs.ErrSessionRevoked) {
+				err = errs.ErrExpiredToken.Wrap(err)
 			}
-		}
-		if claims.JwtSign != user.JwtSign {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, systemmodel.Response{
-				Code: constant.EXPIRED_TOKEN,
-				Info: "token has expired",
-			})
+			errs.Abort(ctx, err)
 			return
 		}
 
 		ctx.Set("user_id", claims.UserID)
 		ctx.Set("username", claims.Username)
+		ctx.Set("session_id", claims.SessionID)
+		ctx.Next()
+	}
+}
+
+// RequireSession 要求请求经登录会话认证，拒绝个人访问令牌，用于会话、双因素认证与访问令牌自身的管理接口
+func RequireSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := ctx.Get("session_id"); !ok {
+			errs.Abort(ctx, errs.ErrSessionRequired)
+			return
+		}
+
 		ctx.Next()
 	}
 }
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+
+	systemservice "cyber-life/internal/service/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 18:40
+// @Desc:	权限校验中间件
+
+// RequirePermission 要求当前用户同时持有全部指定权限，使用个人访问令牌时令牌的权限范围也须包含这些权限，需在JwtAuthMiddleware之后执行
+func RequirePermission(perms ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, ok := ctx.Get("user_id")
+		if !ok {
+			errs.Abort(ctx, errs.ErrInvalidToken)
+			return
+		}
+
+		granted, err := systemservice.UserHasPermissions(userID.(uint), perms...)
+		if err != nil {
+			errs.Abort(ctx, err)
+			return
+		}
+		if !granted {
+			errs.Abort(ctx, errs.ErrPermissionDenied)
+			return
+		}
+		if scopes, ok := ctx.Get("access_token_scopes"); ok && !systemservice.AccessTokenScopesGrant(scopes.([]string), perms...) {
+			errs.Abort(ctx, errs.ErrPermissionDenied)
+			return
+		}
+
+		ctx.Next()
+	}
+}
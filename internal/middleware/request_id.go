@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"regexp"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 22:40
+// @Desc:	请求ID中间件
+
+// requestIDHeader 携带请求ID的请求头与响应头
+const requestIDHeader = "X-Request-ID"
+
+// requestIDPattern 可沿用的客户端请求ID格式，不符合时重新生成，避免日志被注入任意内容
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware 为每个请求分配请求ID：沿用格式合法的 X-Request-ID 请求头，否则随机生成；
+// 请求ID写入上下文的 request_id 与 X-Request-ID 响应头，错误响应与日志据此关联同一请求
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		ctx.Set("request_id", requestID)
+		ctx.Header(requestIDHeader, requestID)
+		ctx.Next()
+	}
+}
+
+// newRequestID 生成16字节随机数的十六进制请求ID
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
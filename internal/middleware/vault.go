@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"cyber-life/internal/core/vault"
+	"cyber-life/internal/errs"
+	"github.com/gin-gonic/gin"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 13:40
+// @Desc:	保险库状态中间件
+
+// VaultUnsealedMiddleware 保险库封存时拒绝访问涉及凭据字段的接口
+func VaultUnsealedMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if vault.IsSealed() {
+			errs.Abort(ctx, errs.ErrVaultSealed)
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -1,6 +1,9 @@
 package common
 
-import "gorm.io/gorm"
+import (
+	"cyber-life/internal/core/vault"
+	"gorm.io/gorm"
+)
 
 // @Author: yv1ing
 // @Email:  me@yvling.cn
@@ -10,6 +13,8 @@ import "gorm.io/gorm"
 type Account struct {
 	gorm.Model
 
+	OwnerID uint `json:"owner_id" gorm:"index"` // 记录所有者的用户ID
+
 	Type          string `json:"type" gorm:"index" binding:"required"`
 	Platform      string `json:"platform" gorm:"index" binding:"required"`
 	PlatformURL   string `json:"platform_url" gorm:"index" binding:"required"`
@@ -20,3 +25,33 @@ type Account struct {
 	Remark        string `json:"remark"`
 	Logo          string `json:"logo"`
 }
+
+// BeforeSave 写库前加密Password字段
+func (a *Account) BeforeSave(tx *gorm.DB) error {
+	return vault.SealColumn(tx, &a.Password, "password")
+}
+
+// AfterSave 写库后还原Password字段明文
+func (a *Account) AfterSave(tx *gorm.DB) error {
+	return vault.OpenColumn(&a.Password)
+}
+
+// AfterFind 读库后解密Password字段
+func (a *Account) AfterFind(tx *gorm.DB) error {
+	return vault.OpenColumn(&a.Password)
+}
+
+// RevisionValues 参与修订比较的字段值
+func (a *Account) RevisionValues() map[string]interface{} {
+	return map[string]interface{}{
+		"type":           a.Type,
+		"platform":       a.Platform,
+		"platform_url":   a.PlatformURL,
+		"username":       a.Username,
+		"password":       a.Password,
+		"security_email": a.SecurityEmail,
+		"security_phone": a.SecurityPhone,
+		"remark":         a.Remark,
+		"logo":           a.Logo,
+	}
+}
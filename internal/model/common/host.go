@@ -1,6 +1,9 @@
 package common
 
-import "gorm.io/gorm"
+import (
+	"cyber-life/internal/core/vault"
+	"gorm.io/gorm"
+)
 
 // @Author: yv1ing
 // @Email:  me@yvling.cn
@@ -10,17 +13,53 @@ import "gorm.io/gorm"
 type Host struct {
 	gorm.Model
 
-	Provider       string         `json:"provider" gorm:"index" binding:"required"`
-	ProviderURL    string         `json:"provider_url" gorm:"index" binding:"required"`
-	Hostname       string         `json:"hostname" gorm:"index" binding:"required"`
-	Address        string         `json:"address" gorm:"index" binding:"required"`
+	OwnerID uint `json:"owner_id" gorm:"index"` // 记录所有者的用户ID
+
+	Provider       string            `json:"provider" gorm:"index" binding:"required"`
+	ProviderURL    string            `json:"provider_url" gorm:"index" binding:"required"`
+	Hostname       string            `json:"hostname" gorm:"index" binding:"required"`
+	Address        string            `json:"address" gorm:"index" binding:"required"`
 	Ports          map[string]string `json:"ports" gorm:"serializer:json" binding:"required"`
-	Username       string         `json:"username" gorm:"index" binding:"required"`
-	Password       string         `json:"password" binding:"required"`
-	OS             string         `json:"os"`              // 操作系统
-	Logo           string         `json:"logo"`            // 操作系统Logo文件名
-	CpuNum         int            `json:"cpu_num"`         // CPU核心数
-	RamSize        int            `json:"ram_size"`        // 内存大小（单位MB）
-	DiskSize       int            `json:"disk_size"`       // 磁盘大小（单位MB）
-	ExpirationTime int64          `json:"expiration_time"` // 到期时间（秒级时间戳）
+	Username       string            `json:"username" gorm:"index" binding:"required"`
+	Password       string            `json:"password" binding:"required"`
+	OS             string            `json:"os"`              // 操作系统
+	Logo           string            `json:"logo"`            // 操作系统Logo文件名
+	CpuNum         int               `json:"cpu_num"`         // CPU核心数
+	RamSize        int               `json:"ram_size"`        // 内存大小（单位MB）
+	DiskSize       int               `json:"disk_size"`       // 磁盘大小（单位MB）
+	ExpirationTime int64             `json:"expiration_time"` // 到期时间（秒级时间戳）
+}
+
+// BeforeSave 写库前加密Password字段
+func (h *Host) BeforeSave(tx *gorm.DB) error {
+	return vault.SealColumn(tx, &h.Password, "password")
+}
+
+// AfterSave 写库后还原Password字段明文
+func (h *Host) AfterSave(tx *gorm.DB) error {
+	return vault.OpenColumn(&h.Password)
+}
+
+// AfterFind 读库后解密Password字段
+func (h *Host) AfterFind(tx *gorm.DB) error {
+	return vault.OpenColumn(&h.Password)
+}
+
+// RevisionValues 参与修订比较的字段值
+func (h *Host) RevisionValues() map[string]interface{} {
+	return map[string]interface{}{
+		"provider":        h.Provider,
+		"provider_url":    h.ProviderURL,
+		"hostname":        h.Hostname,
+		"address":         h.Address,
+		"ports":           h.Ports,
+		"username":        h.Username,
+		"password":        h.Password,
+		"os":              h.OS,
+		"logo":            h.Logo,
+		"cpu_num":         h.CpuNum,
+		"ram_size":        h.RamSize,
+		"disk_size":       h.DiskSize,
+		"expiration_time": h.ExpirationTime,
+	}
 }
@@ -5,8 +5,38 @@ package common
 // @Date:   2025/10/31
 // @Desc:   导入相关模型
 
+// ImportOptions 导入选项，查重的自然标识：账号为平台+账号，密钥为平台+密钥ID，主机为地址+用户名
+type ImportOptions struct {
+	DryRun       bool   // 仅解析与校验，不写入任何记录
+	AllOrNothing bool   // 任一记录失败时不导入任何记录
+	Conflict     string // 与已有记录冲突时的处理方式：skip/overwrite/keep_both/merge
+}
+
 // ImportResult 导入结果
 type ImportResult struct {
-	SuccessCount int `json:"success_count"` // 成功导入数量
-	FailedCount  int `json:"failed_count"`  // 失败导入数量
+	Format       string       `json:"format,omitempty"`      // 导入文件格式
+	DryRun       bool         `json:"dry_run"`               // 是否为试运行，试运行时成功数量为校验通过的数量
+	AllOrNothing bool         `json:"all_or_nothing"`        // 是否要求全部导入成功
+	Conflict     string       `json:"conflict"`              // 与已有记录冲突时的处理方式
+	RolledBack   bool         `json:"rolled_back,omitempty"` // 是否因存在失败记录而未导入任何记录
+	Total        int          `json:"total"`                 // 源文件中的记录数量
+	SuccessCount int          `json:"success_count"`         // 成功导入数量
+	UpdatedCount int          `json:"updated_count"`         // 覆盖或合并已有记录的数量
+	FailedCount  int          `json:"failed_count"`          // 失败导入数量
+	SkippedCount int          `json:"skipped_count"`         // 跳过数量
+	Items        []ImportItem `json:"items,omitempty"`       // 逐条导入明细
+}
+
+// ImportItem 单条记录的导入明细
+type ImportItem struct {
+	Index       int         `json:"index"`                  // 记录在源文件中的序号，从1开始
+	Line        int         `json:"line,omitempty"`         // 记录在源文件中的起始行号，仅CSV文件提供
+	Title       string      `json:"title"`                  // 记录标题
+	Group       string      `json:"group,omitempty"`        // 记录在源文件中的分组
+	Resource    string      `json:"resource,omitempty"`     // 导入的记录类型：accounts/secrets/hosts/sites
+	Status      string      `json:"status"`                 // 导入结果：success/valid/updated/failed/skipped/rolled_back
+	Error       string      `json:"error,omitempty"`        // 失败或跳过的原因
+	RecordID    uint        `json:"record_id,omitempty"`    // 导入后生成或更新的记录ID
+	DuplicateOf uint        `json:"duplicate_of,omitempty"` // 自然标识相同的已有记录ID
+	Record      interface{} `json:"record,omitempty"`       // 解析得到的记录，敏感字段以掩码返回
 }
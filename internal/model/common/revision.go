@@ -0,0 +1,114 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/core/vault"
+	"encoding/json"
+	"gorm.io/gorm"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 20:15
+// @Desc:	记录修订历史数据模型，每次修改记录时保存被修改字段修改前后的值，敏感字段的值单独加密保存
+
+type Revision struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	ResourceType string `json:"resource_type" gorm:"size:32;index:idx_revision_resource"` // accounts、secrets、hosts
+	ResourceID   uint   `json:"resource_id" gorm:"index:idx_revision_resource"`
+	ActorID      uint   `json:"actor_id"`
+	Actor        string `json:"actor" gorm:"->;-:migration"` // 查询时关联用户表获取的用户名
+	Action       string `json:"action" gorm:"size:16"`       // update、rollback
+
+	Changes []RevisionChange `json:"changes" gorm:"-"`
+	Data    string           `json:"-" gorm:"type:text"` // Changes 的JSON编码
+}
+
+// RevisionChange 单个字段的修订
+type RevisionChange struct {
+	Field     string      `json:"field"`
+	Old       interface{} `json:"old"`
+	New       interface{} `json:"new"`
+	Sensitive bool        `json:"sensitive,omitempty"`
+}
+
+// Revisioned 支持修订历史的记录，按列名返回参与修订比较的字段值
+type Revisioned interface {
+	RevisionValues() map[string]interface{}
+}
+
+// BeforeSave 写库前编码Changes，敏感字段的值加密后保存
+func (r *Revision) BeforeSave(tx *gorm.DB) error {
+	changes := make([]RevisionChange, len(r.Changes))
+	for index, change := range r.Changes {
+		change.Sensitive = constant.RevisionSensitiveFields[r.ResourceType][change.Field]
+		if change.Sensitive {
+			var err error
+			if change.Old, err = sealRevisionValue(change.Old); err != nil {
+				return err
+			}
+			if change.New, err = sealRevisionValue(change.New); err != nil {
+				return err
+			}
+		}
+		changes[index] = change
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	r.Data = string(data)
+
+	return nil
+}
+
+// AfterFind 读库后解码Changes并解密敏感字段的值
+func (r *Revision) AfterFind(tx *gorm.DB) error {
+	r.Changes = nil
+	if r.Data == "" {
+		return nil
+	}
+
+	err := json.Unmarshal([]byte(r.Data), &r.Changes)
+	if err != nil {
+		return err
+	}
+
+	for index := range r.Changes {
+		change := &r.Changes[index]
+		change.Sensitive = constant.RevisionSensitiveFields[r.ResourceType][change.Field]
+		if !change.Sensitive {
+			continue
+		}
+		if change.Old, err = openRevisionValue(change.Old); err != nil {
+			return err
+		}
+		if change.New, err = openRevisionValue(change.New); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sealRevisionValue 加密非空的字符串值
+func sealRevisionValue(value interface{}) (interface{}, error) {
+	text, ok := value.(string)
+	if !ok || text == "" {
+		return value, nil
+	}
+	return vault.EncryptString(text)
+}
+
+// openRevisionValue 解密字符串值
+func openRevisionValue(value interface{}) (interface{}, error) {
+	text, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	return vault.DecryptString(text)
+}
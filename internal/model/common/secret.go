@@ -1,6 +1,9 @@
 package common
 
-import "gorm.io/gorm"
+import (
+	"cyber-life/internal/core/vault"
+	"gorm.io/gorm"
+)
 
 // @Author: yv1ing
 // @Email:  me@yvling.cn
@@ -10,6 +13,8 @@ import "gorm.io/gorm"
 type Secret struct {
 	gorm.Model
 
+	OwnerID uint `json:"owner_id" gorm:"index"` // 记录所有者的用户ID
+
 	Platform    string `json:"platform" gorm:"index" binding:"required"`
 	PlatformURL string `json:"platform_url" gorm:"index" binding:"required"`
 	KeyID       string `json:"key_id" binding:"required"`
@@ -17,3 +22,30 @@ type Secret struct {
 	Remark      string `json:"remark"`
 	Logo        string `json:"logo"`
 }
+
+// BeforeSave 写库前加密KeySecret字段
+func (s *Secret) BeforeSave(tx *gorm.DB) error {
+	return vault.SealColumn(tx, &s.KeySecret, "key_secret")
+}
+
+// AfterSave 写库后还原KeySecret字段明文
+func (s *Secret) AfterSave(tx *gorm.DB) error {
+	return vault.OpenColumn(&s.KeySecret)
+}
+
+// AfterFind 读库后解密KeySecret字段
+func (s *Secret) AfterFind(tx *gorm.DB) error {
+	return vault.OpenColumn(&s.KeySecret)
+}
+
+// RevisionValues 参与修订比较的字段值
+func (s *Secret) RevisionValues() map[string]interface{} {
+	return map[string]interface{}{
+		"platform":     s.Platform,
+		"platform_url": s.PlatformURL,
+		"key_id":       s.KeyID,
+		"key_secret":   s.KeySecret,
+		"remark":       s.Remark,
+		"logo":         s.Logo,
+	}
+}
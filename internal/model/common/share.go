@@ -0,0 +1,19 @@
+package common
+
+import "gorm.io/gorm"
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 19:08
+// @Desc:	记录共享授权数据模型，记录所有者可将单条记录以只读或读写方式共享给其他用户或用户组
+
+type Share struct {
+	gorm.Model
+
+	ResourceType string `json:"resource_type" gorm:"size:32;index:idx_share_resource"` // accounts、secrets、hosts、sites
+	ResourceID   uint   `json:"resource_id" gorm:"index:idx_share_resource"`
+	GranteeType  string `json:"grantee_type" gorm:"size:16;index:idx_share_grantee"` // user、group
+	GranteeID    uint   `json:"grantee_id" gorm:"index:idx_share_grantee"`
+	Access       string `json:"access" gorm:"size:16"` // read、read_write
+	GrantedBy    uint   `json:"granted_by"`
+}
@@ -10,6 +10,8 @@ import "gorm.io/gorm"
 type Site struct {
 	gorm.Model
 
+	OwnerID uint `json:"owner_id" gorm:"index"` // 记录所有者的用户ID
+
 	Name string `json:"name" gorm:"index"`
 	Logo string `json:"logo"`
 	URL  string `json:"url"`
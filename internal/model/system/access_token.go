@@ -0,0 +1,26 @@
+package system
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 23:00
+// @Desc:	个人访问令牌数据模型，供脚本与自动化任务代替登录会话调用接口，数据库中仅保存令牌哈希
+
+type AccessToken struct {
+	gorm.Model
+
+	UserID    uint     `json:"user_id" gorm:"index"`
+	Name      string   `json:"name" gorm:"size:64"`
+	Hint      string   `json:"hint" gorm:"size:32"`                     // 令牌开头的若干字符，用于辨认令牌
+	TokenHash string   `json:"-" gorm:"size:64;uniqueIndex"`            // 令牌的哈希
+	Scopes    []string `json:"scopes" gorm:"serializer:json;type:text"` // 令牌可使用的权限，不超出所属用户的权限
+
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
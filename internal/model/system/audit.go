@@ -0,0 +1,50 @@
+package system
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 20:05
+// @Desc:	审计事件数据模型，仅允许追加写入，每条事件携带前一条事件的哈希形成哈希链
+
+type AuditEvent struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	ActorID      uint   `json:"actor_id" gorm:"index"`
+	Actor        string `json:"actor"`
+	Action       string `json:"action" gorm:"size:64;index"` // 形如 secrets.export、users.login
+	ResourceType string `json:"resource_type" gorm:"size:32;index"`
+	ResourceID   string `json:"resource_id" gorm:"type:text"` // 批量操作时为空格分隔的多个ID
+	IP           string `json:"ip"`
+	Outcome      string `json:"outcome" gorm:"size:16;index"` // success、failure、denied
+	Detail       string `json:"detail"`
+
+	PrevHash string `json:"prev_hash" gorm:"size:64"`
+	Hash     string `json:"hash" gorm:"size:64"`
+}
+
+// AuditEventFilter 审计事件查询条件，零值字段不参与过滤
+type AuditEventFilter struct {
+	ActorID      uint
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Outcome      string
+	Since        time.Time
+	Until        time.Time
+}
+
+// BeforeUpdate 审计事件不可修改
+func (e *AuditEvent) BeforeUpdate(tx *gorm.DB) error {
+	return errors.New("audit events are append-only")
+}
+
+// BeforeDelete 审计事件不可删除
+func (e *AuditEvent) BeforeDelete(tx *gorm.DB) error {
+	return errors.New("audit events are append-only")
+}
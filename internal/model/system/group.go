@@ -0,0 +1,24 @@
+package system
+
+import "gorm.io/gorm"
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 19:10
+// @Desc:	用户组数据模型，用于向团队成员批量共享记录
+
+type Group struct {
+	gorm.Model
+
+	Name        string `json:"name" gorm:"size:64;uniqueIndex"`
+	Description string `json:"description"`
+
+	Members []GroupMember `json:"members"`
+}
+
+type GroupMember struct {
+	gorm.Model
+
+	GroupID uint `json:"group_id" gorm:"index"`
+	UserID  uint `json:"user_id" gorm:"index"`
+}
@@ -8,7 +8,15 @@ import "github.com/gin-gonic/gin"
 // @Desc:	系统Http响应统一格式
 
 type Response struct {
-	Code int    `json:"code"`
-	Info string `json:"info"`
-	Data gin.H  `json:"data"`
+	Code      int          `json:"code"`
+	Info      string       `json:"info"`
+	Data      gin.H        `json:"data"`
+	Errors    []FieldError `json:"errors,omitempty"`     // 请求参数校验失败的字段
+	RequestID string       `json:"request_id,omitempty"` // 错误响应携带的请求ID，便于关联日志
+}
+
+// FieldError 单个请求字段的错误
+type FieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
 }
@@ -0,0 +1,24 @@
+package system
+
+import (
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 21:40
+// @Desc:	登录失败计数数据模型，按用户名与来源IP分别计数
+
+type LoginAttempt struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Scope   string `json:"scope" gorm:"size:16;uniqueIndex:idx_login_attempt_subject"`    // 计数维度：username/ip
+	Subject string `json:"subject" gorm:"size:191;uniqueIndex:idx_login_attempt_subject"` // 用户名或IP
+
+	Failures     int        `json:"failures"`       // 连续失败次数
+	LastFailedAt time.Time  `json:"last_failed_at"` // 最近一次失败时间
+	BlockedUntil time.Time  `json:"blocked_until"`  // 退避等待截止时间，此前的登录请求直接拒绝
+	LockedUntil  *time.Time `json:"locked_until"`   // 临时锁定截止时间
+}
@@ -0,0 +1,19 @@
+package system
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 15:30
+// @Desc:	双因素认证恢复码数据模型
+
+type RecoveryCode struct {
+	gorm.Model
+
+	UserID   uint       `json:"user_id" gorm:"index"`
+	CodeHash string     `json:"-" gorm:"index"`
+	UsedAt   *time.Time `json:"used_at"`
+}
@@ -0,0 +1,25 @@
+package system
+
+import "gorm.io/gorm"
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 18:15
+// @Desc:	角色与权限数据模型，角色通过多对多关联持有权限，系统用户通过RoleID关联角色
+
+type Role struct {
+	gorm.Model
+
+	Name        string `json:"name" gorm:"size:64;uniqueIndex"`
+	Description string `json:"description"`
+	BuiltIn     bool   `json:"built_in"` // 内置角色不可删除或重命名
+
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions"`
+}
+
+type Permission struct {
+	gorm.Model
+
+	Code        string `json:"code" gorm:"size:64;uniqueIndex"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,21 @@
+package system
+
+import (
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 22:10
+// @Desc:	数据库迁移记录数据模型，每条记录对应一个已执行的迁移版本
+
+type SchemaMigration struct {
+	Version   int       `json:"version" gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `json:"name" gorm:"size:128"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// TableName 迁移记录表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
@@ -0,0 +1,27 @@
+package system
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 17:05
+// @Desc:	用户登录会话数据模型，每次登录创建一个会话，访问令牌与刷新令牌均绑定会话
+
+type Session struct {
+	gorm.Model
+
+	UserID    uint   `json:"user_id" gorm:"index"`
+	Device    string `json:"device"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+
+	RefreshHash     string `json:"-" gorm:"index"` // 当前刷新令牌的哈希
+	PrevRefreshHash string `json:"-" gorm:"index"` // 上一个刷新令牌的哈希，用于发现令牌被重复使用
+
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"` // 刷新令牌过期时间
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
@@ -18,6 +18,10 @@ type User struct {
 	Phone  string `json:"phone"`
 	Avatar string `json:"avatar"`
 
-	JwtSign  string `json:"jwt_sign"`
-	IsActive bool   `json:"is_active"`
+	IsActive bool `json:"is_active"`
+	RoleID   uint `json:"role_id" gorm:"index"`
+
+	TotpEnabled  bool   `json:"totp_enabled"`
+	TotpSecret   string `json:"-"` // 加密存储的TOTP密钥
+	TotpLastStep int64  `json:"-"` // 最近一次通过校验的时间步，用于拒绝重放
 }
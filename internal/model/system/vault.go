@@ -0,0 +1,19 @@
+package system
+
+import "gorm.io/gorm"
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 11:02
+// @Desc:	保险库密钥数据模型，保存被主口令派生密钥包裹后的数据密钥
+
+type VaultKey struct {
+	gorm.Model
+
+	Salt       string `json:"-"` // Base64编码的Argon2id盐值
+	Time       uint32 `json:"-"`
+	Memory     uint32 `json:"-"`
+	Threads    uint8  `json:"-"`
+	WrappedKey string `json:"-"`                    // Base64编码的被包裹数据密钥
+	Singleton  *bool  `json:"-" gorm:"uniqueIndex"` // 恒为true，唯一索引保证只能保存一份数据密钥
+}
@@ -1,7 +1,12 @@
 package common
 
 import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/core/vault"
+	"cyber-life/internal/errs"
 	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
 
 	commonmodel "cyber-life/internal/model/common"
 )
@@ -26,18 +31,22 @@ func HardDeleteAccount(account *commonmodel.Account) error {
 	return repository.Repo.DB.Unscoped().Delete(account).Error
 }
 
-// UpdateAccount 更新账号记录
-func UpdateAccount(account *commonmodel.Account) error {
-	return repository.Repo.DB.Model(account).Updates(account).Error
+// UpdateAccount 更新账号记录，并以操作者身份保存修订
+func UpdateAccount(actorID uint, account *commonmodel.Account) error {
+	return updateWithRevision(constant.RESOURCE_ACCOUNTS, account.ID, actorID, constant.REVISION_ACTION_UPDATE, &commonmodel.Account{}, &commonmodel.Account{}, func(tx *gorm.DB) error {
+		return tx.Model(account).Updates(account).Error
+	})
 }
 
-// UpdateAccountFields 更新账号记录（只更新指定字段）
-func UpdateAccountFields(accountID uint, fields map[string]interface{}) error {
-	return repository.Repo.DB.Model(&commonmodel.Account{}).Where("id = ?", accountID).Updates(fields).Error
+// UpdateAccountFields 更新账号记录（只更新指定字段），并以操作者身份保存指定方式的修订
+func UpdateAccountFields(actorID, accountID uint, action string, fields map[string]interface{}) error {
+	return updateWithRevision(constant.RESOURCE_ACCOUNTS, accountID, actorID, action, &commonmodel.Account{}, &commonmodel.Account{}, func(tx *gorm.DB) error {
+		return tx.Model(&commonmodel.Account{}).Where("id = ?", accountID).Updates(fields).Error
+	})
 }
 
-// FindAccounts 查询账号记录
-func FindAccounts(keyword string, page, size int) ([]commonmodel.Account, int64, error) {
+// FindAccounts 查询账号记录（仅限用户拥有或被共享的记录）
+func FindAccounts(userID uint, keyword string, page, size int) ([]commonmodel.Account, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -49,7 +58,7 @@ func FindAccounts(keyword string, page, size int) ([]commonmodel.Account, int64,
 	var total int64
 
 	// 构建查询条件
-	query := repository.Repo.DB.Model(&commonmodel.Account{}).Where("platform LIKE ? OR username LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	query := repository.Repo.DB.Model(&commonmodel.Account{}).Scopes(visibleScope(constant.RESOURCE_ACCOUNTS, userID), accountKeywordScope(keyword))
 
 	// 获取总数
 	err := query.Count(&total).Error
@@ -67,8 +76,35 @@ func FindAccounts(keyword string, page, size int) ([]commonmodel.Account, int64,
 	return accounts, total, nil
 }
 
-// FindAccountsList 获取账号记录列表
-func FindAccountsList(page, size int) ([]commonmodel.Account, int64, error) {
+// accountKeywordScope 查询范围：平台或账号包含关键字的记录，关键字为空时不过滤
+func accountKeywordScope(keyword string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if keyword == "" {
+			return db
+		}
+		return db.Where("platform LIKE ? OR username LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+}
+
+// FindAccountsAfter 按ID升序查询ID大于 afterID 的一批账号记录，用于按游标分批遍历（仅限用户拥有或被共享且匹配关键字的记录）
+func FindAccountsAfter(userID uint, keyword string, afterID uint, size int) ([]commonmodel.Account, error) {
+	var accounts []commonmodel.Account
+
+	err := repository.Repo.DB.Model(&commonmodel.Account{}).
+		Scopes(visibleScope(constant.RESOURCE_ACCOUNTS, userID), accountKeywordScope(keyword)).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(size).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// FindAccountsList 获取账号记录列表（仅限用户拥有或被共享的记录）
+func FindAccountsList(userID uint, page, size int) ([]commonmodel.Account, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -80,7 +116,7 @@ func FindAccountsList(page, size int) ([]commonmodel.Account, int64, error) {
 	var total int64
 	var err error
 
-	query := repository.Repo.DB.Model(&commonmodel.Account{})
+	query := repository.Repo.DB.Model(&commonmodel.Account{}).Scopes(visibleScope(constant.RESOURCE_ACCOUNTS, userID))
 	err = query.Count(&total).Error
 	if err != nil {
 		return nil, 0, err
@@ -94,3 +130,69 @@ func FindAccountsList(page, size int) ([]commonmodel.Account, int64, error) {
 
 	return accounts, total, nil
 }
+
+// FindTrashedAccounts 查询回收站中的账号记录（仅限用户拥有的记录），按删除时间倒序排列
+func FindTrashedAccounts(userID uint, page, size int) ([]commonmodel.Account, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	var accounts []commonmodel.Account
+	var total int64
+
+	query := repository.Repo.DB.Unscoped().Model(&commonmodel.Account{}).Where("owner_id = ? AND deleted_at IS NOT NULL", userID)
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	err = query.Order("deleted_at DESC, id DESC").Offset(offset).Limit(size).Find(&accounts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return accounts, total, nil
+}
+
+// FindAccountByID 根据ID查询账号记录（仅限用户拥有或被共享的记录）
+func FindAccountByID(userID, accountID uint) (*commonmodel.Account, error) {
+	var account commonmodel.Account
+
+	err := repository.Repo.DB.Scopes(visibleScope(constant.RESOURCE_ACCOUNTS, userID)).Where("id = ?", accountID).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// EncryptPlaintextAccounts 加密历史遗留的明文密码（包含已软删除的记录），返回处理的记录数
+func EncryptPlaintextAccounts() (int, error) {
+	var accounts []commonmodel.Account
+
+	err := repository.Repo.DB.Unscoped().Where("password <> '' AND password NOT LIKE ?", vault.CipherPrefix+"%").Find(&accounts).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, account := range accounts {
+		ciphertext, err := vault.EncryptString(account.Password)
+		if err != nil {
+			return 0, err
+		}
+
+		err = repository.Repo.DB.Unscoped().Model(&commonmodel.Account{}).Where("id = ?", account.ID).UpdateColumn("password", ciphertext).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(accounts), nil
+}
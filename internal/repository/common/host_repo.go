@@ -1,7 +1,12 @@
 package common
 
 import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/core/vault"
+	"cyber-life/internal/errs"
 	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
 
 	commonmodel "cyber-life/internal/model/common"
 )
@@ -26,18 +31,22 @@ func HardDeleteHost(host *commonmodel.Host) error {
 	return repository.Repo.DB.Unscoped().Delete(host).Error
 }
 
-// UpdateHost 更新主机记录
-func UpdateHost(host *commonmodel.Host) error {
-	return repository.Repo.DB.Model(host).Updates(host).Error
+// UpdateHost 更新主机记录，并以操作者身份保存修订
+func UpdateHost(actorID uint, host *commonmodel.Host) error {
+	return updateWithRevision(constant.RESOURCE_HOSTS, host.ID, actorID, constant.REVISION_ACTION_UPDATE, &commonmodel.Host{}, &commonmodel.Host{}, func(tx *gorm.DB) error {
+		return tx.Model(host).Updates(host).Error
+	})
 }
 
-// UpdateHostFields 更新主机记录（只更新指定字段）
-func UpdateHostFields(hostID uint, fields map[string]interface{}) error {
-	return repository.Repo.DB.Model(&commonmodel.Host{}).Where("id = ?", hostID).Updates(fields).Error
+// UpdateHostFields 更新主机记录（只更新指定字段），并以操作者身份保存指定方式的修订
+func UpdateHostFields(actorID, hostID uint, action string, fields map[string]interface{}) error {
+	return updateWithRevision(constant.RESOURCE_HOSTS, hostID, actorID, action, &commonmodel.Host{}, &commonmodel.Host{}, func(tx *gorm.DB) error {
+		return tx.Model(&commonmodel.Host{}).Where("id = ?", hostID).Updates(fields).Error
+	})
 }
 
-// FindHosts 查询主机记录
-func FindHosts(keyword string, page, size int) ([]commonmodel.Host, int64, error) {
+// FindHosts 查询主机记录（仅限用户拥有或被共享的记录）
+func FindHosts(userID uint, keyword string, page, size int) ([]commonmodel.Host, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -49,7 +58,7 @@ func FindHosts(keyword string, page, size int) ([]commonmodel.Host, int64, error
 	var total int64
 
 	// 构建查询条件
-	query := repository.Repo.DB.Model(&commonmodel.Host{}).Where("provider LIKE ? OR hostname LIKE ? OR address LIKE ?", "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+	query := repository.Repo.DB.Model(&commonmodel.Host{}).Scopes(visibleScope(constant.RESOURCE_HOSTS, userID), hostKeywordScope(keyword))
 
 	// 获取总数
 	err := query.Count(&total).Error
@@ -67,8 +76,35 @@ func FindHosts(keyword string, page, size int) ([]commonmodel.Host, int64, error
 	return hosts, total, nil
 }
 
-// FindHostsList 获取主机记录列表
-func FindHostsList(page, size int) ([]commonmodel.Host, int64, error) {
+// hostKeywordScope 查询范围：提供商、主机名或地址包含关键字的记录，关键字为空时不过滤
+func hostKeywordScope(keyword string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if keyword == "" {
+			return db
+		}
+		return db.Where("provider LIKE ? OR hostname LIKE ? OR address LIKE ?", "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+	}
+}
+
+// FindHostsAfter 按ID升序查询ID大于 afterID 的一批主机记录，用于按游标分批遍历（仅限用户拥有或被共享且匹配关键字的记录）
+func FindHostsAfter(userID uint, keyword string, afterID uint, size int) ([]commonmodel.Host, error) {
+	var hosts []commonmodel.Host
+
+	err := repository.Repo.DB.Model(&commonmodel.Host{}).
+		Scopes(visibleScope(constant.RESOURCE_HOSTS, userID), hostKeywordScope(keyword)).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(size).
+		Find(&hosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+}
+
+// FindHostsList 获取主机记录列表（仅限用户拥有或被共享的记录）
+func FindHostsList(userID uint, page, size int) ([]commonmodel.Host, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -80,7 +116,7 @@ func FindHostsList(page, size int) ([]commonmodel.Host, int64, error) {
 	var total int64
 	var err error
 
-	query := repository.Repo.DB.Model(&commonmodel.Host{})
+	query := repository.Repo.DB.Model(&commonmodel.Host{}).Scopes(visibleScope(constant.RESOURCE_HOSTS, userID))
 	err = query.Count(&total).Error
 	if err != nil {
 		return nil, 0, err
@@ -94,3 +130,69 @@ func FindHostsList(page, size int) ([]commonmodel.Host, int64, error) {
 
 	return hosts, total, nil
 }
+
+// FindTrashedHosts 查询回收站中的主机记录（仅限用户拥有的记录），按删除时间倒序排列
+func FindTrashedHosts(userID uint, page, size int) ([]commonmodel.Host, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	var hosts []commonmodel.Host
+	var total int64
+
+	query := repository.Repo.DB.Unscoped().Model(&commonmodel.Host{}).Where("owner_id = ? AND deleted_at IS NOT NULL", userID)
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	err = query.Order("deleted_at DESC, id DESC").Offset(offset).Limit(size).Find(&hosts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return hosts, total, nil
+}
+
+// FindHostByID 根据ID查询主机记录（仅限用户拥有或被共享的记录）
+func FindHostByID(userID, hostID uint) (*commonmodel.Host, error) {
+	var host commonmodel.Host
+
+	err := repository.Repo.DB.Scopes(visibleScope(constant.RESOURCE_HOSTS, userID)).Where("id = ?", hostID).First(&host).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &host, nil
+}
+
+// EncryptPlaintextHosts 加密历史遗留的明文密码（包含已软删除的记录），返回处理的记录数
+func EncryptPlaintextHosts() (int, error) {
+	var hosts []commonmodel.Host
+
+	err := repository.Repo.DB.Unscoped().Where("password <> '' AND password NOT LIKE ?", vault.CipherPrefix+"%").Find(&hosts).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, host := range hosts {
+		ciphertext, err := vault.EncryptString(host.Password)
+		if err != nil {
+			return 0, err
+		}
+
+		err = repository.Repo.DB.Unscoped().Model(&commonmodel.Host{}).Where("id = ?", host.ID).UpdateColumn("password", ciphertext).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(hosts), nil
+}
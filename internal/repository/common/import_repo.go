@@ -0,0 +1,113 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/repository"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"reflect"
+
+	commonmodel "cyber-life/internal/model/common"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 14:10
+// @Desc:	导入记录的批量写入
+
+var (
+	// errImportRollback 全部或不导入时记录写入失败，回滚整个事务
+	errImportRollback = errors.New("import rolled back")
+	// errImportDryRun 试运行结束，回滚整个事务
+	errImportDryRun = errors.New("import dry run")
+)
+
+// ImportEntry 单条待写入的导入记录
+type ImportEntry struct {
+	Record       interface{}                            // 待创建的记录模型指针
+	ResourceType string                                 // 记录类型，写回已有记录时据此保存修订
+	Identity     map[string]interface{}                 // 查重使用的自然标识字段，为空时直接创建
+	Resolve      func(existing interface{}) interface{} // 与导入者名下的已有记录冲突时调用，返回需要写回的记录，返回 nil 时不写入
+}
+
+// ImportOutcome 单条导入记录的写入结果
+type ImportOutcome struct {
+	Existing interface{} // 冲突的已有记录，无冲突时为空
+	Saved    bool        // 是否创建或写回了记录
+	Err      error       // 写入错误
+}
+
+// SaveImportedRecords 在同一事务中逐条写入导入的记录，返回与 entries 一一对应的写入结果；
+// allOrNothing 为真时任一记录失败即回滚全部记录，否则仅回滚失败记录的保存点；dryRun 为真时写入后回滚整个事务
+func SaveImportedRecords(ownerID uint, entries []ImportEntry, allOrNothing, dryRun bool) ([]ImportOutcome, error) {
+	outcomes := make([]ImportOutcome, len(entries))
+
+	err := repository.Repo.DB.Transaction(func(tx *gorm.DB) error {
+		for index, entry := range entries {
+			savepoint := fmt.Sprintf("import_%d", index)
+			if !allOrNothing {
+				if err := tx.SavePoint(savepoint).Error; err != nil {
+					return err
+				}
+			}
+
+			outcomes[index] = saveImportEntry(tx, ownerID, entry)
+			if outcomes[index].Err == nil {
+				continue
+			}
+			if allOrNothing {
+				return errImportRollback
+			}
+			if err := tx.RollbackTo(savepoint).Error; err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) && !errors.Is(err, errImportDryRun) {
+		return nil, err
+	}
+
+	return outcomes, nil
+}
+
+// saveImportEntry 查找导入者名下自然标识相同的记录：不存在时创建记录，存在时按处理函数的结果写回或跳过
+func saveImportEntry(tx *gorm.DB, ownerID uint, entry ImportEntry) ImportOutcome {
+	if len(entry.Identity) > 0 && entry.Resolve != nil {
+		existing := reflect.New(reflect.TypeOf(entry.Record).Elem()).Interface()
+		err := tx.Where("owner_id = ?", ownerID).Where(entry.Identity).Order("id").First(existing).Error
+		if err == nil {
+			record := entry.Resolve(existing)
+			if record == nil {
+				return ImportOutcome{Existing: existing}
+			}
+			return ImportOutcome{Existing: existing, Saved: true, Err: saveResolvedRecord(tx, ownerID, entry.ResourceType, record)}
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return ImportOutcome{Err: err}
+		}
+	}
+
+	return ImportOutcome{Saved: true, Err: tx.Create(entry.Record).Error}
+}
+
+// saveResolvedRecord 写回冲突的已有记录，支持修订历史的记录以导入者为操作者保存修订，以便查看与回滚被覆盖的字段
+func saveResolvedRecord(tx *gorm.DB, ownerID uint, resourceType string, record interface{}) error {
+	if _, ok := record.(commonmodel.Revisioned); !ok || resourceType == "" {
+		return tx.Save(record).Error
+	}
+
+	recordType := reflect.TypeOf(record).Elem()
+	before := reflect.New(recordType).Interface().(commonmodel.Revisioned)
+	after := reflect.New(recordType).Interface().(commonmodel.Revisioned)
+	recordID := uint(reflect.ValueOf(record).Elem().FieldByName("ID").Uint())
+
+	return updateWithRevisionTx(tx, resourceType, recordID, ownerID, constant.REVISION_ACTION_IMPORT, before, after, func(tx *gorm.DB) error {
+		return tx.Save(record).Error
+	})
+}
@@ -0,0 +1,158 @@
+package common
+
+import (
+	"bytes"
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"sort"
+
+	commonmodel "cyber-life/internal/model/common"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 20:25
+// @Desc:	记录修订历史数据操作实现
+
+// updateWithRevision 在同一事务中更新记录并保存修订：before 与 after 为用于读取更新前后记录的空模型，字段值均未变化时不保存修订
+func updateWithRevision(resourceType string, recordID, actorID uint, action string, before, after commonmodel.Revisioned, update func(tx *gorm.DB) error) error {
+	return repository.Repo.DB.Transaction(func(tx *gorm.DB) error {
+		return updateWithRevisionTx(tx, resourceType, recordID, actorID, action, before, after, update)
+	})
+}
+
+// updateWithRevisionTx 在调用方的事务中更新记录并保存修订，参数含义同 updateWithRevision
+func updateWithRevisionTx(tx *gorm.DB, resourceType string, recordID, actorID uint, action string, before, after commonmodel.Revisioned, update func(tx *gorm.DB) error) error {
+	err := tx.Where("id = ?", recordID).First(before).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.ErrRecordNotFound
+		}
+		return err
+	}
+
+	err = update(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Where("id = ?", recordID).First(after).Error
+	if err != nil {
+		return err
+	}
+
+	changes, err := diffRevisionValues(resourceType, before.RevisionValues(), after.RevisionValues())
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return tx.Create(&commonmodel.Revision{
+		ResourceType: resourceType,
+		ResourceID:   recordID,
+		ActorID:      actorID,
+		Action:       action,
+		Changes:      changes,
+	}).Error
+}
+
+// diffRevisionValues 按字段名顺序比较更新前后的字段值，返回值发生变化的字段
+func diffRevisionValues(resourceType string, before, after map[string]interface{}) ([]commonmodel.RevisionChange, error) {
+	fields := make([]string, 0, len(after))
+	for field := range after {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var changes []commonmodel.RevisionChange
+	for _, field := range fields {
+		// 以JSON编码比较，映射等不可直接比较的字段值同样适用
+		oldValue, err := json.Marshal(before[field])
+		if err != nil {
+			return nil, err
+		}
+		newValue, err := json.Marshal(after[field])
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(oldValue, newValue) {
+			continue
+		}
+
+		changes = append(changes, commonmodel.RevisionChange{
+			Field:     field,
+			Old:       before[field],
+			New:       after[field],
+			Sensitive: constant.RevisionSensitiveFields[resourceType][field],
+		})
+	}
+
+	return changes, nil
+}
+
+// revisionQuery 构造指定记录的修订查询，关联用户表获取操作者用户名
+func revisionQuery(resourceType string, recordID uint) *gorm.DB {
+	return repository.Repo.DB.Model(&commonmodel.Revision{}).
+		Select("revisions.*, users.username AS actor").
+		Joins("LEFT JOIN users ON users.id = revisions.actor_id").
+		Where("revisions.resource_type = ? AND revisions.resource_id = ?", resourceType, recordID)
+}
+
+// FindRevisions 查询指定记录的修订历史，按修订时间倒序排列
+func FindRevisions(resourceType string, recordID uint, page, size int) ([]commonmodel.Revision, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	var revisions []commonmodel.Revision
+	var total int64
+
+	err := repository.Repo.DB.Model(&commonmodel.Revision{}).
+		Where("resource_type = ? AND resource_id = ?", resourceType, recordID).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	err = revisionQuery(resourceType, recordID).Order("revisions.id DESC").Offset(offset).Limit(size).Find(&revisions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+// FindRevisionsSince 查询指定记录从指定修订起（含该修订）的全部修订，按修订时间倒序排列，指定修订不存在时返回revision not found
+func FindRevisionsSince(resourceType string, recordID, revisionID uint) ([]commonmodel.Revision, error) {
+	var revisions []commonmodel.Revision
+
+	err := revisionQuery(resourceType, recordID).
+		Where("revisions.id >= ?", revisionID).
+		Order("revisions.id DESC").
+		Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 || revisions[len(revisions)-1].ID != revisionID {
+		return nil, errs.ErrRevisionNotFound
+	}
+
+	return revisions, nil
+}
+
+// HardDeleteRevisionsByResource 删除指定记录的全部修订
+func HardDeleteRevisionsByResource(resourceType string, resourceID uint) error {
+	return repository.Repo.DB.
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Delete(&commonmodel.Revision{}).Error
+}
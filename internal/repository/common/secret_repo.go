@@ -1,7 +1,12 @@
 package common
 
 import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/core/vault"
+	"cyber-life/internal/errs"
 	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
 
 	commonmodel "cyber-life/internal/model/common"
 )
@@ -26,18 +31,22 @@ func HardDeleteSecret(secret *commonmodel.Secret) error {
 	return repository.Repo.DB.Unscoped().Delete(secret).Error
 }
 
-// UpdateSecret 更新密钥记录
-func UpdateSecret(secret *commonmodel.Secret) error {
-	return repository.Repo.DB.Model(secret).Updates(secret).Error
+// UpdateSecret 更新密钥记录，并以操作者身份保存修订
+func UpdateSecret(actorID uint, secret *commonmodel.Secret) error {
+	return updateWithRevision(constant.RESOURCE_SECRETS, secret.ID, actorID, constant.REVISION_ACTION_UPDATE, &commonmodel.Secret{}, &commonmodel.Secret{}, func(tx *gorm.DB) error {
+		return tx.Model(secret).Updates(secret).Error
+	})
 }
 
-// UpdateSecretFields 更新密钥记录（只更新指定字段）
-func UpdateSecretFields(secretID uint, fields map[string]interface{}) error {
-	return repository.Repo.DB.Model(&commonmodel.Secret{}).Where("id = ?", secretID).Updates(fields).Error
+// UpdateSecretFields 更新密钥记录（只更新指定字段），并以操作者身份保存指定方式的修订
+func UpdateSecretFields(actorID, secretID uint, action string, fields map[string]interface{}) error {
+	return updateWithRevision(constant.RESOURCE_SECRETS, secretID, actorID, action, &commonmodel.Secret{}, &commonmodel.Secret{}, func(tx *gorm.DB) error {
+		return tx.Model(&commonmodel.Secret{}).Where("id = ?", secretID).Updates(fields).Error
+	})
 }
 
-// FindSecrets 查询密钥记录
-func FindSecrets(keyword string, page, size int) ([]commonmodel.Secret, int64, error) {
+// FindSecrets 查询密钥记录（仅限用户拥有或被共享的记录）
+func FindSecrets(userID uint, keyword string, page, size int) ([]commonmodel.Secret, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -49,7 +58,7 @@ func FindSecrets(keyword string, page, size int) ([]commonmodel.Secret, int64, e
 	var total int64
 
 	// 构建查询条件
-	query := repository.Repo.DB.Model(&commonmodel.Secret{}).Where("platform LIKE ? OR platform_url LIKE ? OR key_id LIKE ?", "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+	query := repository.Repo.DB.Model(&commonmodel.Secret{}).Scopes(visibleScope(constant.RESOURCE_SECRETS, userID), secretKeywordScope(keyword))
 
 	// 获取总数
 	err := query.Count(&total).Error
@@ -67,8 +76,35 @@ func FindSecrets(keyword string, page, size int) ([]commonmodel.Secret, int64, e
 	return secrets, total, nil
 }
 
-// FindSecretsList 获取密钥记录列表
-func FindSecretsList(page, size int) ([]commonmodel.Secret, int64, error) {
+// secretKeywordScope 查询范围：平台、平台链接或密钥ID包含关键字的记录，关键字为空时不过滤
+func secretKeywordScope(keyword string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if keyword == "" {
+			return db
+		}
+		return db.Where("platform LIKE ? OR platform_url LIKE ? OR key_id LIKE ?", "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+	}
+}
+
+// FindSecretsAfter 按ID升序查询ID大于 afterID 的一批密钥记录，用于按游标分批遍历（仅限用户拥有或被共享且匹配关键字的记录）
+func FindSecretsAfter(userID uint, keyword string, afterID uint, size int) ([]commonmodel.Secret, error) {
+	var secrets []commonmodel.Secret
+
+	err := repository.Repo.DB.Model(&commonmodel.Secret{}).
+		Scopes(visibleScope(constant.RESOURCE_SECRETS, userID), secretKeywordScope(keyword)).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(size).
+		Find(&secrets).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// FindSecretsList 获取密钥记录列表（仅限用户拥有或被共享的记录）
+func FindSecretsList(userID uint, page, size int) ([]commonmodel.Secret, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -80,7 +116,7 @@ func FindSecretsList(page, size int) ([]commonmodel.Secret, int64, error) {
 	var total int64
 	var err error
 
-	query := repository.Repo.DB.Model(&commonmodel.Secret{})
+	query := repository.Repo.DB.Model(&commonmodel.Secret{}).Scopes(visibleScope(constant.RESOURCE_SECRETS, userID))
 	err = query.Count(&total).Error
 	if err != nil {
 		return nil, 0, err
@@ -94,3 +130,69 @@ func FindSecretsList(page, size int) ([]commonmodel.Secret, int64, error) {
 
 	return secrets, total, nil
 }
+
+// FindTrashedSecrets 查询回收站中的密钥记录（仅限用户拥有的记录），按删除时间倒序排列
+func FindTrashedSecrets(userID uint, page, size int) ([]commonmodel.Secret, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	var secrets []commonmodel.Secret
+	var total int64
+
+	query := repository.Repo.DB.Unscoped().Model(&commonmodel.Secret{}).Where("owner_id = ? AND deleted_at IS NOT NULL", userID)
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	err = query.Order("deleted_at DESC, id DESC").Offset(offset).Limit(size).Find(&secrets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return secrets, total, nil
+}
+
+// FindSecretByID 根据ID查询密钥记录（仅限用户拥有或被共享的记录）
+func FindSecretByID(userID, secretID uint) (*commonmodel.Secret, error) {
+	var secret commonmodel.Secret
+
+	err := repository.Repo.DB.Scopes(visibleScope(constant.RESOURCE_SECRETS, userID)).Where("id = ?", secretID).First(&secret).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &secret, nil
+}
+
+// EncryptPlaintextSecrets 加密历史遗留的明文密钥Secret（包含已软删除的记录），返回处理的记录数
+func EncryptPlaintextSecrets() (int, error) {
+	var secrets []commonmodel.Secret
+
+	err := repository.Repo.DB.Unscoped().Where("key_secret <> '' AND key_secret NOT LIKE ?", vault.CipherPrefix+"%").Find(&secrets).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, secret := range secrets {
+		ciphertext, err := vault.EncryptString(secret.KeySecret)
+		if err != nil {
+			return 0, err
+		}
+
+		err = repository.Repo.DB.Unscoped().Model(&commonmodel.Secret{}).Where("id = ?", secret.ID).UpdateColumn("key_secret", ciphertext).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(secrets), nil
+}
@@ -0,0 +1,186 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
+
+	commonmodel "cyber-life/internal/model/common"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 19:15
+// @Desc:	记录共享授权数据操作实现
+
+// sharedRecordIDs 构造子查询：共享给指定用户（直接授权或经由所在用户组授权）的记录ID
+func sharedRecordIDs(resourceType string, userID uint, writeOnly bool) *gorm.DB {
+	groupIDs := repository.Repo.DB.Table("group_members").
+		Select("group_id").
+		Where("user_id = ? AND deleted_at IS NULL", userID)
+
+	query := repository.Repo.DB.Model(&commonmodel.Share{}).
+		Select("resource_id").
+		Where("resource_type = ?", resourceType).
+		Where("(grantee_type = ? AND grantee_id = ?) OR (grantee_type = ? AND grantee_id IN (?))",
+			constant.GRANTEE_USER, userID, constant.GRANTEE_GROUP, groupIDs)
+	if writeOnly {
+		query = query.Where("access = ?", constant.SHARE_ACCESS_READ_WRITE)
+	}
+
+	return query
+}
+
+// visibleScope 查询范围：用户拥有或被共享的记录
+func visibleScope(resourceType string, userID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(owner_id = ? OR id IN (?))", userID, sharedRecordIDs(resourceType, userID, false))
+	}
+}
+
+// writableScope 查询范围：用户拥有或被以读写方式共享的记录
+func writableScope(resourceType string, userID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(owner_id = ? OR id IN (?))", userID, sharedRecordIDs(resourceType, userID, true))
+	}
+}
+
+// CheckRecordVisible 校验用户可查看指定记录，不可见时返回record not found
+func CheckRecordVisible(resourceType string, userID, recordID uint) error {
+	var count int64
+
+	err := repository.Repo.DB.Table(resourceType).
+		Where("id = ? AND deleted_at IS NULL", recordID).
+		Scopes(visibleScope(resourceType, userID)).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errs.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CheckRecordWritable 校验用户可修改指定记录，不可见或只读时返回record not found
+func CheckRecordWritable(resourceType string, userID, recordID uint) error {
+	var count int64
+
+	err := repository.Repo.DB.Table(resourceType).
+		Where("id = ? AND deleted_at IS NULL", recordID).
+		Scopes(writableScope(resourceType, userID)).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errs.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CheckRecordOwner 校验用户为指定记录的所有者，否则返回record not found
+func CheckRecordOwner(resourceType string, userID, recordID uint) error {
+	var count int64
+
+	err := repository.Repo.DB.Table(resourceType).
+		Where("id = ? AND owner_id = ? AND deleted_at IS NULL", recordID, userID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errs.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ClaimUnownedRecords 将没有所有者的历史记录（包含已软删除的记录）归属给指定用户，返回处理的记录数
+func ClaimUnownedRecords(resourceType string, ownerID uint) (int64, error) {
+	result := repository.Repo.DB.Table(resourceType).
+		Where("owner_id = 0 OR owner_id IS NULL").
+		UpdateColumn("owner_id", ownerID)
+
+	return result.RowsAffected, result.Error
+}
+
+// CreateShare 创建共享授权
+func CreateShare(share *commonmodel.Share) error {
+	return repository.Repo.DB.Create(share).Error
+}
+
+// UpdateShareFields 更新共享授权（只更新指定字段）
+func UpdateShareFields(shareID uint, fields map[string]interface{}) error {
+	return repository.Repo.DB.Model(&commonmodel.Share{}).Where("id = ?", shareID).Updates(fields).Error
+}
+
+// HardDeleteShare 删除共享授权（硬删除）
+func HardDeleteShare(share *commonmodel.Share) error {
+	return repository.Repo.DB.Unscoped().Delete(share).Error
+}
+
+// HardDeleteSharesByResource 删除指定记录的全部共享授权（硬删除）
+func HardDeleteSharesByResource(resourceType string, resourceID uint) error {
+	return repository.Repo.DB.Unscoped().
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Delete(&commonmodel.Share{}).Error
+}
+
+// HardDeleteSharesByGrantee 删除授予指定用户或用户组的全部共享授权（硬删除）
+func HardDeleteSharesByGrantee(granteeType string, granteeID uint) error {
+	return repository.Repo.DB.Unscoped().
+		Where("grantee_type = ? AND grantee_id = ?", granteeType, granteeID).
+		Delete(&commonmodel.Share{}).Error
+}
+
+// FindShareByID 根据ID查询共享授权
+func FindShareByID(shareID uint) (*commonmodel.Share, error) {
+	var share commonmodel.Share
+
+	err := repository.Repo.DB.First(&share, shareID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &share, nil
+}
+
+// FindShareByGrantee 查询指定记录授予指定用户或用户组的共享授权
+func FindShareByGrantee(resourceType string, resourceID uint, granteeType string, granteeID uint) (*commonmodel.Share, error) {
+	var share commonmodel.Share
+
+	err := repository.Repo.DB.
+		Where("resource_type = ? AND resource_id = ? AND grantee_type = ? AND grantee_id = ?", resourceType, resourceID, granteeType, granteeID).
+		First(&share).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &share, nil
+}
+
+// FindSharesByResource 查询指定记录的全部共享授权
+func FindSharesByResource(resourceType string, resourceID uint) ([]commonmodel.Share, error) {
+	var shares []commonmodel.Share
+
+	err := repository.Repo.DB.
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Order("id").
+		Find(&shares).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
@@ -1,7 +1,11 @@
 package common
 
 import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
 	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
 
 	commonmodel "cyber-life/internal/model/common"
 )
@@ -49,8 +53,23 @@ func UpdateSiteFields(siteID uint, fields map[string]interface{}) error {
 	return repository.Repo.DB.Model(&commonmodel.Site{}).Where("id = ?", siteID).Updates(fields).Error
 }
 
-// FindSites 查询站点记录
-func FindSites(keyword string, page, size int) ([]commonmodel.Site, int64, error) {
+// FindSiteByID 根据ID查询站点记录（仅限用户拥有或被共享的记录）
+func FindSiteByID(userID, siteID uint) (*commonmodel.Site, error) {
+	var site commonmodel.Site
+
+	err := repository.Repo.DB.Scopes(visibleScope(constant.RESOURCE_SITES, userID)).Where("id = ?", siteID).First(&site).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &site, nil
+}
+
+// FindSites 查询站点记录（仅限用户拥有或被共享的记录）
+func FindSites(userID uint, keyword string, page, size int) ([]commonmodel.Site, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -62,7 +81,7 @@ func FindSites(keyword string, page, size int) ([]commonmodel.Site, int64, error
 	var total int64
 
 	// 构建查询条件
-	query := repository.Repo.DB.Model(&commonmodel.Site{}).Where("name LIKE ? OR url LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	query := repository.Repo.DB.Model(&commonmodel.Site{}).Scopes(visibleScope(constant.RESOURCE_SITES, userID), siteKeywordScope(keyword))
 
 	// 获取总数
 	err := query.Count(&total).Error
@@ -80,8 +99,35 @@ func FindSites(keyword string, page, size int) ([]commonmodel.Site, int64, error
 	return sites, total, nil
 }
 
-// FindSitesList 获取站点记录列表
-func FindSitesList(page, size int) ([]commonmodel.Site, int64, error) {
+// siteKeywordScope 查询范围：站点名称或链接包含关键字的记录，关键字为空时不过滤
+func siteKeywordScope(keyword string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if keyword == "" {
+			return db
+		}
+		return db.Where("name LIKE ? OR url LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+}
+
+// FindSitesAfter 按ID升序查询ID大于 afterID 的一批站点记录，用于按游标分批遍历（仅限用户拥有或被共享且匹配关键字的记录）
+func FindSitesAfter(userID uint, keyword string, afterID uint, size int) ([]commonmodel.Site, error) {
+	var sites []commonmodel.Site
+
+	err := repository.Repo.DB.Model(&commonmodel.Site{}).
+		Scopes(visibleScope(constant.RESOURCE_SITES, userID), siteKeywordScope(keyword)).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(size).
+		Find(&sites).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return sites, nil
+}
+
+// FindSitesList 获取站点记录列表（仅限用户拥有或被共享的记录）
+func FindSitesList(userID uint, page, size int) ([]commonmodel.Site, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -93,7 +139,7 @@ func FindSitesList(page, size int) ([]commonmodel.Site, int64, error) {
 	var total int64
 	var err error
 
-	query := repository.Repo.DB.Model(&commonmodel.Site{})
+	query := repository.Repo.DB.Model(&commonmodel.Site{}).Scopes(visibleScope(constant.RESOURCE_SITES, userID))
 	err = query.Count(&total).Error
 	if err != nil {
 		return nil, 0, err
@@ -107,3 +153,30 @@ func FindSitesList(page, size int) ([]commonmodel.Site, int64, error) {
 
 	return sites, total, nil
 }
+
+// FindTrashedSites 查询回收站中的站点记录（仅限用户拥有的记录），按删除时间倒序排列
+func FindTrashedSites(userID uint, page, size int) ([]commonmodel.Site, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	var sites []commonmodel.Site
+	var total int64
+
+	query := repository.Repo.DB.Unscoped().Model(&commonmodel.Site{}).Where("owner_id = ? AND deleted_at IS NOT NULL", userID)
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	err = query.Order("deleted_at DESC, id DESC").Offset(offset).Limit(size).Find(&sites).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sites, total, nil
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+
+	commonmodel "cyber-life/internal/model/common"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 18:30
+// @Desc:	回收站数据操作实现：软删除的记录即位于回收站中
+
+// purgeBatchSize 清理过期记录时每批删除的记录数
+const purgeBatchSize = 500
+
+// CheckRecordTrashed 校验记录属于用户且位于回收站中，记录不存在或不属于用户时返回record not found，未删除时返回record not in trash
+func CheckRecordTrashed(resourceType string, userID, recordID uint) error {
+	var records []struct {
+		DeletedAt gorm.DeletedAt
+	}
+
+	err := repository.Repo.DB.Table(resourceType).
+		Select("deleted_at").
+		Where("id = ? AND owner_id = ?", recordID, userID).
+		Limit(1).
+		Scan(&records).Error
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errs.ErrRecordNotFound
+	}
+	if !records[0].DeletedAt.Valid {
+		return errs.ErrRecordNotInTrash
+	}
+
+	return nil
+}
+
+// RestoreRecord 将回收站中的记录恢复为正常状态
+func RestoreRecord(resourceType string, recordID uint) error {
+	return repository.Repo.DB.Table(resourceType).
+		Where("id = ? AND deleted_at IS NOT NULL", recordID).
+		UpdateColumn("deleted_at", nil).Error
+}
+
+// PurgeTrashedRecords 彻底删除在指定时间之前移入回收站的记录及其共享授权与修订历史，返回删除的记录数
+func PurgeTrashedRecords(resourceType string, before time.Time) (int64, error) {
+	var total int64
+
+	for {
+		var ids []uint
+		err := repository.Repo.DB.Table(resourceType).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Order("id").
+			Limit(purgeBatchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		err = repository.Repo.DB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Unscoped().
+				Where("resource_type = ? AND resource_id IN ?", resourceType, ids).
+				Delete(&commonmodel.Share{}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Where("resource_type = ? AND resource_id IN ?", resourceType, ids).
+				Delete(&commonmodel.Revision{}).Error
+			if err != nil {
+				return err
+			}
+			return tx.Exec("DELETE FROM ? WHERE id IN ?", clause.Table{Name: resourceType}, ids).Error
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += int64(len(ids))
+		if len(ids) < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package system
+
+import (
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 23:05
+// @Desc:	个人访问令牌数据操作实现
+
+// CreateAccessToken 创建访问令牌
+func CreateAccessToken(token *systemmodel.AccessToken) error {
+	return repository.Repo.DB.Create(token).Error
+}
+
+// FindAccessTokenByHash 根据令牌哈希查询访问令牌
+func FindAccessTokenByHash(tokenHash string) (*systemmodel.AccessToken, error) {
+	var token systemmodel.AccessToken
+
+	err := repository.Repo.DB.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// FindAccessTokensByUserID 查询用户未吊销的访问令牌，含已过期的令牌
+func FindAccessTokensByUserID(userID uint) ([]systemmodel.AccessToken, error) {
+	var tokens []systemmodel.AccessToken
+
+	err := repository.Repo.DB.Where("user_id = ? AND revoked_at IS NULL", userID).Order("id DESC").Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// TouchAccessToken 记录访问令牌的最近使用时间与来源IP
+func TouchAccessToken(tokenID uint, ip string) error {
+	return repository.Repo.DB.Model(&systemmodel.AccessToken{}).Where("id = ?", tokenID).Updates(map[string]interface{}{
+		"last_used_at": time.Now(),
+		"last_used_ip": ip,
+	}).Error
+}
+
+// RevokeAccessToken 吊销用户的访问令牌
+func RevokeAccessToken(userID, tokenID uint) error {
+	result := repository.Repo.DB.Model(&systemmodel.AccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errs.ErrRecordNotFound
+	}
+
+	return nil
+}
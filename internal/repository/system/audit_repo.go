@@ -0,0 +1,89 @@
+package system
+
+import (
+	"cyber-life/internal/repository"
+	"gorm.io/gorm"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 20:10
+// @Desc:	审计事件数据操作实现，仅提供追加与查询
+
+// AppendAuditEvent 追加审计事件：在事务内读取链尾哈希并写入新事件，fill负责根据链尾哈希补全事件的哈希字段
+func AppendAuditEvent(event *systemmodel.AuditEvent, fill func(prevHash string)) error {
+	return repository.Repo.DB.Transaction(func(tx *gorm.DB) error {
+		var last systemmodel.AuditEvent
+
+		err := tx.Order("id DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		fill(last.Hash)
+		return tx.Create(event).Error
+	})
+}
+
+// FindAuditEventsWithPage 按条件分页查询审计事件，按时间倒序排列
+func FindAuditEventsWithPage(filter systemmodel.AuditEventFilter, page, size int) ([]systemmodel.AuditEvent, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	var events []systemmodel.AuditEvent
+	var total int64
+
+	query := repository.Repo.DB.Model(&systemmodel.AuditEvent{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at <= ?", filter.Until)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	err = query.Order("id DESC").Offset(offset).Limit(size).Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// FindAuditEventsAfter 按ID顺序查询指定ID之后的一批审计事件，用于校验哈希链
+func FindAuditEventsAfter(afterID uint, limit int) ([]systemmodel.AuditEvent, error) {
+	var events []systemmodel.AuditEvent
+
+	err := repository.Repo.DB.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
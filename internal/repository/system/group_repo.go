@@ -0,0 +1,108 @@
+package system
+
+import (
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 19:30
+// @Desc:	用户组数据操作实现
+
+// CreateGroup 创建用户组
+func CreateGroup(group *systemmodel.Group) error {
+	return repository.Repo.DB.Create(group).Error
+}
+
+// UpdateGroup 更新用户组
+func UpdateGroup(group *systemmodel.Group) error {
+	return repository.Repo.DB.Model(group).Omit("Members").Updates(group).Error
+}
+
+// HardDeleteGroup 删除用户组及其成员关系（硬删除）
+func HardDeleteGroup(group *systemmodel.Group) error {
+	return repository.Repo.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("group_id = ?", group.ID).Delete(&systemmodel.GroupMember{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(group).Error
+	})
+}
+
+// FindGroupByID 根据ID查询用户组
+func FindGroupByID(groupID uint) (*systemmodel.Group, error) {
+	var group systemmodel.Group
+
+	err := repository.Repo.DB.Preload("Members").First(&group, groupID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// FindGroupByName 根据Name查询用户组
+func FindGroupByName(name string) (*systemmodel.Group, error) {
+	var group systemmodel.Group
+
+	err := repository.Repo.DB.Where("name = ?", name).First(&group).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// FindGroupList 查询全部用户组及其成员
+func FindGroupList() ([]systemmodel.Group, error) {
+	var groups []systemmodel.Group
+
+	err := repository.Repo.DB.Preload("Members").Order("id").Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// CreateGroupMember 添加用户组成员
+func CreateGroupMember(member *systemmodel.GroupMember) error {
+	return repository.Repo.DB.Create(member).Error
+}
+
+// FindGroupMember 查询用户组成员关系
+func FindGroupMember(groupID, userID uint) (*systemmodel.GroupMember, error) {
+	var member systemmodel.GroupMember
+
+	err := repository.Repo.DB.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// HardDeleteGroupMember 移除用户组成员（硬删除）
+func HardDeleteGroupMember(member *systemmodel.GroupMember) error {
+	return repository.Repo.DB.Unscoped().Delete(member).Error
+}
+
+// HardDeleteGroupMembersByUserID 移除用户在全部用户组中的成员关系（硬删除）
+func HardDeleteGroupMembersByUserID(userID uint) error {
+	return repository.Repo.DB.Unscoped().Where("user_id = ?", userID).Delete(&systemmodel.GroupMember{}).Error
+}
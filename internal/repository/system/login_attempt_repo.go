@@ -0,0 +1,73 @@
+package system
+
+import (
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 21:40
+// @Desc:	登录失败计数数据操作实现
+
+// SaveLoginAttempt 保存登录失败计数（不存在时创建）
+func SaveLoginAttempt(attempt *systemmodel.LoginAttempt) error {
+	return repository.Repo.DB.Save(attempt).Error
+}
+
+// HardDeleteLoginAttempt 删除指定维度的登录失败计数
+func HardDeleteLoginAttempt(scope, subject string) error {
+	return repository.Repo.DB.Where("scope = ? AND subject = ?", scope, subject).Delete(&systemmodel.LoginAttempt{}).Error
+}
+
+// HardDeleteExpiredLoginAttempts 删除最近一次失败早于 lastFailedBefore 且退避等待与锁定均已在 now 之前结束的登录失败计数
+func HardDeleteExpiredLoginAttempts(lastFailedBefore, now time.Time) error {
+	return repository.Repo.DB.
+		Where("last_failed_at < ? AND blocked_until < ? AND (locked_until IS NULL OR locked_until < ?)", lastFailedBefore, now, now).
+		Delete(&systemmodel.LoginAttempt{}).Error
+}
+
+// HardDeleteLoginAttemptByID 根据ID删除登录失败计数
+func HardDeleteLoginAttemptByID(attemptID uint) error {
+	result := repository.Repo.DB.Delete(&systemmodel.LoginAttempt{}, attemptID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errs.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// FindLoginAttempt 查询指定维度的登录失败计数
+func FindLoginAttempt(scope, subject string) (*systemmodel.LoginAttempt, error) {
+	var attempt systemmodel.LoginAttempt
+
+	err := repository.Repo.DB.Where("scope = ? AND subject = ?", scope, subject).First(&attempt).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// FindActiveLoginAttempts 查询处于锁定或退避等待中的登录失败计数
+func FindActiveLoginAttempts(now time.Time) ([]systemmodel.LoginAttempt, error) {
+	var attempts []systemmodel.LoginAttempt
+
+	err := repository.Repo.DB.Where("locked_until > ? OR blocked_until > ?", now, now).Order("updated_at DESC").Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
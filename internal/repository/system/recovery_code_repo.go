@@ -0,0 +1,59 @@
+package system
+
+import (
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 15:34
+// @Desc:	双因素认证恢复码数据操作实现
+
+// CreateRecoveryCodes 批量创建恢复码
+func CreateRecoveryCodes(codes []systemmodel.RecoveryCode) error {
+	return repository.Repo.DB.Create(&codes).Error
+}
+
+// HardDeleteRecoveryCodesByUserID 删除用户的全部恢复码（硬删除）
+func HardDeleteRecoveryCodesByUserID(userID uint) error {
+	return repository.Repo.DB.Unscoped().Where("user_id = ?", userID).Delete(&systemmodel.RecoveryCode{}).Error
+}
+
+// FindUnusedRecoveryCode 根据哈希查询用户未使用的恢复码
+func FindUnusedRecoveryCode(userID uint, codeHash string) (*systemmodel.RecoveryCode, error) {
+	var code systemmodel.RecoveryCode
+
+	err := repository.Repo.DB.Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// MarkRecoveryCodeUsed 将恢复码标记为已使用，返回是否标记成功（并发使用时仅有一次成功）
+func MarkRecoveryCodeUsed(codeID uint) (bool, error) {
+	result := repository.Repo.DB.Model(&systemmodel.RecoveryCode{}).Where("id = ? AND used_at IS NULL", codeID).Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// CountUnusedRecoveryCodes 统计用户剩余可用的恢复码数量
+func CountUnusedRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+
+	err := repository.Repo.DB.Model(&systemmodel.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
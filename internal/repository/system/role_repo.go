@@ -0,0 +1,153 @@
+package system
+
+import (
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 18:20
+// @Desc:	角色与权限数据操作实现
+
+// CreateRole 创建角色（同时写入权限关联）
+func CreateRole(role *systemmodel.Role) error {
+	return repository.Repo.DB.Create(role).Error
+}
+
+// UpdateRole 更新角色
+func UpdateRole(role *systemmodel.Role) error {
+	return repository.Repo.DB.Model(role).Omit("Permissions").Updates(role).Error
+}
+
+// ReplaceRolePermissions 替换角色持有的权限
+func ReplaceRolePermissions(role *systemmodel.Role, permissions []systemmodel.Permission) error {
+	return repository.Repo.DB.Model(role).Association("Permissions").Replace(permissions)
+}
+
+// AppendRolePermissions 为角色追加权限
+func AppendRolePermissions(role *systemmodel.Role, permissions []systemmodel.Permission) error {
+	return repository.Repo.DB.Model(role).Association("Permissions").Append(permissions)
+}
+
+// HardDeleteRole 删除角色（硬删除，同时清除权限关联，避免角色名唯一索引冲突）
+func HardDeleteRole(role *systemmodel.Role) error {
+	return repository.Repo.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(role).Association("Permissions").Clear()
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(role).Error
+	})
+}
+
+// FindRoleByID 根据ID查询角色
+func FindRoleByID(roleID uint) (*systemmodel.Role, error) {
+	var role systemmodel.Role
+
+	err := repository.Repo.DB.Preload("Permissions").First(&role, roleID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// FindRoleByName 根据Name查询角色
+func FindRoleByName(name string) (*systemmodel.Role, error) {
+	var role systemmodel.Role
+
+	err := repository.Repo.DB.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// FindRoleList 查询全部角色
+func FindRoleList() ([]systemmodel.Role, error) {
+	var roles []systemmodel.Role
+
+	err := repository.Repo.DB.Preload("Permissions").Order("id").Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// CreatePermission 创建权限
+func CreatePermission(permission *systemmodel.Permission) error {
+	return repository.Repo.DB.Create(permission).Error
+}
+
+// UpdatePermission 更新权限
+func UpdatePermission(permission *systemmodel.Permission) error {
+	return repository.Repo.DB.Model(permission).Updates(permission).Error
+}
+
+// FindPermissionList 查询全部权限
+func FindPermissionList() ([]systemmodel.Permission, error) {
+	var permissions []systemmodel.Permission
+
+	err := repository.Repo.DB.Order("code").Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// FindPermissionsByCodes 根据编码批量查询权限
+func FindPermissionsByCodes(codes []string) ([]systemmodel.Permission, error) {
+	var permissions []systemmodel.Permission
+
+	err := repository.Repo.DB.Where("code IN ?", codes).Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// FindUserPermissionCodes 查询用户所属角色持有的全部权限编码
+func FindUserPermissionCodes(userID uint) ([]string, error) {
+	var codes []string
+
+	err := repository.Repo.DB.Model(&systemmodel.Permission{}).
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id AND roles.deleted_at IS NULL").
+		Joins("JOIN users ON users.role_id = roles.id AND users.deleted_at IS NULL").
+		Where("users.id = ? AND users.is_active = ?", userID, true).
+		Pluck("permissions.code", &codes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// CountUsersByRoleID 统计属于指定角色的有效用户数量
+func CountUsersByRoleID(roleID uint) (int64, error) {
+	var count int64
+
+	err := repository.Repo.DB.Model(&systemmodel.User{}).
+		Where("role_id = ? AND is_active = ?", roleID, true).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
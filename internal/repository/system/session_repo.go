@@ -0,0 +1,95 @@
+package system
+
+import (
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 17:12
+// @Desc:	用户登录会话数据操作实现
+
+// CreateSession 创建会话
+func CreateSession(session *systemmodel.Session) error {
+	return repository.Repo.DB.Create(session).Error
+}
+
+// UpdateSessionFields 更新会话（只更新指定字段）
+func UpdateSessionFields(sessionID uint, fields map[string]interface{}) error {
+	return repository.Repo.DB.Model(&systemmodel.Session{}).Where("id = ?", sessionID).Updates(fields).Error
+}
+
+// RotateSessionRefreshHash 轮换刷新令牌，仅当当前哈希未被并发轮换时成功
+func RotateSessionRefreshHash(sessionID uint, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	result := repository.Repo.DB.Model(&systemmodel.Session{}).
+		Where("id = ? AND refresh_hash = ? AND revoked_at IS NULL", sessionID, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_hash":      newHash,
+			"prev_refresh_hash": oldHash,
+			"last_seen_at":      time.Now(),
+			"expires_at":        expiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeSession 吊销会话
+func RevokeSession(sessionID uint) error {
+	return repository.Repo.DB.Model(&systemmodel.Session{}).Where("id = ? AND revoked_at IS NULL", sessionID).Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserSessions 吊销用户除 exceptID 外的全部会话
+func RevokeUserSessions(userID, exceptID uint) error {
+	return repository.Repo.DB.Model(&systemmodel.Session{}).Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).Update("revoked_at", time.Now()).Error
+}
+
+// FindSessionByID 根据ID查询会话
+func FindSessionByID(sessionID uint) (*systemmodel.Session, error) {
+	var session systemmodel.Session
+
+	err := repository.Repo.DB.First(&session, sessionID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindSessionByRefreshHash 根据当前或上一个刷新令牌哈希查询会话
+func FindSessionByRefreshHash(refreshHash string) (*systemmodel.Session, error) {
+	var session systemmodel.Session
+
+	err := repository.Repo.DB.Where("refresh_hash = ? OR prev_refresh_hash = ?", refreshHash, refreshHash).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindActiveSessionsByUserID 查询用户未吊销且未过期的会话
+func FindActiveSessionsByUserID(userID uint) ([]systemmodel.Session, error) {
+	var sessions []systemmodel.Session
+
+	err := repository.Repo.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).Order("last_seen_at DESC").Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"cyber-life/internal/errs"
 	"cyber-life/internal/repository"
 	"errors"
 	"gorm.io/gorm"
@@ -33,6 +34,21 @@ func UpdateUser(user *systemmodel.User) error {
 	return repository.Repo.DB.Model(user).Updates(user).Error
 }
 
+// UpdateUserFields 更新用户（只更新指定字段，可更新零值）
+func UpdateUserFields(userID uint, fields map[string]interface{}) error {
+	return repository.Repo.DB.Model(&systemmodel.User{}).Where("id = ?", userID).Updates(fields).Error
+}
+
+// AdvanceUserTotpStep 将用户最近通过校验的TOTP时间步推进到 step，返回是否推进成功（同一时间步并发校验时仅有一次成功）
+func AdvanceUserTotpStep(userID uint, step int64) (bool, error) {
+	result := repository.Repo.DB.Model(&systemmodel.User{}).Where("id = ? AND totp_last_step < ?", userID, step).Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
 // FindUserByID 根据ID查询用户
 func FindUserByID(userID uint) (*systemmodel.User, error) {
 	var user systemmodel.User
@@ -40,7 +56,7 @@ func FindUserByID(userID uint) (*systemmodel.User, error) {
 	err := repository.Repo.DB.First(&user, userID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("record not found")
+			return nil, errs.ErrRecordNotFound
 		}
 		return nil, err
 	}
@@ -55,7 +71,7 @@ func FindUserByUsername(username string) (*systemmodel.User, error) {
 	err := repository.Repo.DB.Where("username = ?", username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("record not found")
+			return nil, errs.ErrRecordNotFound
 		}
 		return nil, err
 	}
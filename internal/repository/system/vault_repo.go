@@ -0,0 +1,35 @@
+package system
+
+import (
+	"cyber-life/internal/errs"
+	"cyber-life/internal/repository"
+	"errors"
+	"gorm.io/gorm"
+
+	systemmodel "cyber-life/internal/model/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 11:05
+// @Desc:	保险库密钥数据操作实现
+
+// CreateVaultKey 创建保险库密钥，已存在密钥时违反单例标记的唯一索引而失败
+func CreateVaultKey(vaultKey *systemmodel.VaultKey) error {
+	return repository.Repo.DB.Create(vaultKey).Error
+}
+
+// FindVaultKey 查询保险库密钥
+func FindVaultKey() (*systemmodel.VaultKey, error) {
+	var vaultKey systemmodel.VaultKey
+
+	err := repository.Repo.DB.Order("id ASC").First(&vaultKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &vaultKey, nil
+}
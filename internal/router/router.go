@@ -1,6 +1,8 @@
 package router
 
 import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
 	"cyber-life/internal/middleware"
 	"github.com/gin-gonic/gin"
 
@@ -15,6 +17,8 @@ import (
 
 var whitelist = []string{
 	`^/api/sys/users/login$`,
+	`^/api/sys/users/login/totp$`,
+	`^/api/sys/users/refresh$`,
 }
 
 func InitRouter(eng *gin.Engine) {
@@ -33,62 +37,152 @@ func InitRouter(eng *gin.Engine) {
 	eng.StaticFile("/login.html", "./web/login.html")
 	eng.StaticFile("/admin.html", "./web/admin.html")
 
+	// 参数校验错误以JSON字段名报告出错的字段
+	errs.RegisterJSONFieldNames()
+
 	// 全局中间件
+	// 请求ID中间件最先执行，以便全部错误响应携带请求ID
+	eng.Use(middleware.RequestIDMiddleware())
+	// 鉴权中间件解析当前用户，各路由再通过RequirePermission声明所需权限、通过AuditMiddleware声明审计动作
 	eng.Use(middleware.JwtAuthMiddleware(whitelist))
 
 	api := eng.Group("/api")
 	sys := api.Group("/sys")
 
 	sys.POST("/users/login", systemapi.UserLoginHandler)
-	sys.POST("/users/logout", systemapi.UserLogoutHandler)
-	sys.POST("/users/create", systemapi.CreateUserHandler)
-	sys.DELETE("/users/delete", systemapi.DeleteUserHandler)
-	sys.PUT("/users/update", systemapi.UpdateUserHandler)
-	sys.GET("/users/find", systemapi.FindUserHandler)
-	sys.GET("/users/list", systemapi.ListUserHandler)
+	sys.POST("/users/login/totp", systemapi.UserTotpLoginHandler)
+	sys.POST("/users/refresh", systemapi.UserRefreshHandler)
+	sys.POST("/users/logout", middleware.AuditMiddleware("users.logout"), middleware.RequireSession(), systemapi.UserLogoutHandler)
+	sys.POST("/users/create", middleware.AuditMiddleware("users.create"), middleware.RequirePermission(constant.PERM_USERS_MANAGE), systemapi.CreateUserHandler)
+	sys.DELETE("/users/delete", middleware.AuditMiddleware("users.delete"), middleware.RequirePermission(constant.PERM_USERS_MANAGE), systemapi.DeleteUserHandler)
+	sys.PUT("/users/update", middleware.AuditMiddleware("users.update"), middleware.RequirePermission(constant.PERM_USERS_MANAGE), systemapi.UpdateUserHandler)
+	sys.GET("/users/find", middleware.RequirePermission(constant.PERM_USERS_READ), systemapi.FindUserHandler)
+	sys.GET("/users/list", middleware.RequirePermission(constant.PERM_USERS_READ), systemapi.ListUserHandler)
+	sys.GET("/users/permissions", systemapi.UserPermissionsHandler)
+
+	// 角色权限管理
+	sys.GET("/roles/list", middleware.RequirePermission(constant.PERM_USERS_READ), systemapi.ListRolesHandler)
+	sys.GET("/permissions/list", middleware.RequirePermission(constant.PERM_USERS_READ), systemapi.ListPermissionsHandler)
+	sys.POST("/roles/create", middleware.AuditMiddleware("roles.create"), middleware.RequirePermission(constant.PERM_ROLES_MANAGE), systemapi.CreateRoleHandler)
+	sys.PUT("/roles/update", middleware.AuditMiddleware("roles.update"), middleware.RequirePermission(constant.PERM_ROLES_MANAGE), systemapi.UpdateRoleHandler)
+	sys.DELETE("/roles/delete", middleware.AuditMiddleware("roles.delete"), middleware.RequirePermission(constant.PERM_ROLES_MANAGE), systemapi.DeleteRoleHandler)
+
+	// 用户组管理
+	sys.GET("/groups/list", systemapi.ListGroupsHandler)
+	sys.POST("/groups/create", middleware.AuditMiddleware("groups.create"), middleware.RequirePermission(constant.PERM_USERS_MANAGE), systemapi.CreateGroupHandler)
+	sys.PUT("/groups/update", middleware.AuditMiddleware("groups.update"), middleware.RequirePermission(constant.PERM_USERS_MANAGE), systemapi.UpdateGroupHandler)
+	sys.DELETE("/groups/delete", middleware.AuditMiddleware("groups.delete"), middleware.RequirePermission(constant.PERM_USERS_MANAGE), systemapi.DeleteGroupHandler)
+	sys.POST("/groups/members/add", middleware.AuditMiddleware("groups.add_member"), middleware.RequirePermission(constant.PERM_USERS_MANAGE), systemapi.AddGroupMemberHandler)
+	sys.DELETE("/groups/members/remove", middleware.AuditMiddleware("groups.remove_member"), middleware.RequirePermission(constant.PERM_USERS_MANAGE), systemapi.RemoveGroupMemberHandler)
+
+	// 审计日志
+	sys.GET("/audit", middleware.AuditMiddleware("audit.read"), middleware.RequirePermission(constant.PERM_AUDIT_READ), systemapi.ListAuditEventsHandler)
+	sys.GET("/audit/verify", middleware.AuditMiddleware("audit.verify"), middleware.RequirePermission(constant.PERM_AUDIT_READ), systemapi.VerifyAuditChainHandler)
+
+	// 登录锁定管理
+	sys.GET("/lockouts/list", middleware.RequirePermission(constant.PERM_USERS_READ), systemapi.ListLockoutsHandler)
+	sys.DELETE("/lockouts/clear", middleware.AuditMiddleware("lockouts.clear"), middleware.RequirePermission(constant.PERM_USERS_MANAGE), systemapi.ClearLockoutHandler)
+
+	// 登录会话管理（仅限登录会话，个人访问令牌不可使用）
+	sys.GET("/sessions", middleware.RequireSession(), systemapi.ListSessionsHandler)
+	sys.DELETE("/sessions/revoke", middleware.AuditMiddleware("sessions.revoke"), middleware.RequireSession(), systemapi.RevokeSessionHandler)
+	sys.POST("/sessions/revoke-others", middleware.AuditMiddleware("sessions.revoke_others"), middleware.RequireSession(), systemapi.RevokeOtherSessionsHandler)
+
+	// 个人访问令牌管理（仅限登录会话）
+	sys.GET("/tokens", middleware.RequireSession(), systemapi.ListAccessTokensHandler)
+	sys.POST("/tokens/create", middleware.AuditMiddleware("tokens.create"), middleware.RequireSession(), systemapi.CreateAccessTokenHandler)
+	sys.DELETE("/tokens/revoke", middleware.AuditMiddleware("tokens.revoke"), middleware.RequireSession(), systemapi.RevokeAccessTokenHandler)
+
+	// 双因素认证管理
+	sys.GET("/totp/status", middleware.RequireSession(), systemapi.TotpStatusHandler)
+	sys.POST("/totp/setup", middleware.RequireSession(), systemapi.SetupTotpHandler)
+	sys.POST("/totp/enable", middleware.AuditMiddleware("totp.enable"), middleware.RequireSession(), systemapi.EnableTotpHandler)
+	sys.POST("/totp/disable", middleware.AuditMiddleware("totp.disable"), middleware.RequireSession(), systemapi.DisableTotpHandler)
+	sys.POST("/totp/recovery-codes", middleware.AuditMiddleware("totp.regenerate_recovery_codes"), middleware.RequireSession(), systemapi.RegenerateRecoveryCodesHandler)
+
+	// 凭据保险库管理
+	api.POST("/vault/unlock", middleware.AuditMiddleware("vault.unlock"), middleware.RequirePermission(constant.PERM_VAULT_MANAGE), systemapi.UnlockVaultHandler)
+	api.POST("/vault/lock", middleware.AuditMiddleware("vault.lock"), middleware.RequirePermission(constant.PERM_VAULT_MANAGE), systemapi.LockVaultHandler)
+	api.GET("/vault/status", systemapi.VaultStatusHandler)
+
+	// 自动备份
+	sys.GET("/backups/status", middleware.RequirePermission(constant.PERM_BACKUPS_READ), systemapi.BackupStatusHandler)
 
 	// 实际业务路由
+	// 涉及凭据字段的路由要求保险库处于解封状态
+	vaulted := api.Group("", middleware.VaultUnsealedMiddleware())
+
 	// 账号记录管理
-	api.POST("/accounts/create", commonapi.CreateAccountHandler)
-	api.DELETE("/accounts/delete", commonapi.DeleteAccountHandler)
-	api.PUT("/accounts/update", commonapi.UpdateAccountHandler)
-	api.GET("/accounts/find", commonapi.FindAccountsHandler)
-	api.GET("/accounts/list", commonapi.FindAccountsListHandler)
-	api.GET("/accounts/export", commonapi.ExportAccountsCSVHandler)
-	api.POST("/accounts/import", commonapi.ImportAccountsCSVHandler)
+	vaulted.POST("/accounts/create", middleware.AuditMiddleware("accounts.create"), middleware.RequirePermission(constant.PERM_ACCOUNTS_WRITE), commonapi.CreateAccountHandler)
+	vaulted.DELETE("/accounts/delete", middleware.AuditMiddleware("accounts.delete"), middleware.RequirePermission(constant.PERM_ACCOUNTS_WRITE), commonapi.DeleteAccountHandler)
+	vaulted.GET("/accounts/trash", middleware.AuditMiddleware("accounts.read"), middleware.RequirePermission(constant.PERM_ACCOUNTS_READ), commonapi.FindTrashedAccountsHandler)
+	vaulted.POST("/accounts/trash/restore", middleware.AuditMiddleware("accounts.restore"), middleware.RequirePermission(constant.PERM_ACCOUNTS_WRITE), commonapi.RestoreAccountsHandler)
+	vaulted.DELETE("/accounts/trash/purge", middleware.AuditMiddleware("accounts.purge"), middleware.RequirePermission(constant.PERM_ACCOUNTS_WRITE), commonapi.PurgeAccountsHandler)
+	vaulted.GET("/accounts/history", middleware.AuditMiddleware("accounts.read"), middleware.RequirePermission(constant.PERM_ACCOUNTS_READ), commonapi.FindAccountHistoryHandler)
+	vaulted.POST("/accounts/rollback", middleware.AuditMiddleware("accounts.rollback"), middleware.RequirePermission(constant.PERM_ACCOUNTS_WRITE), commonapi.RollbackAccountHandler)
+	vaulted.PUT("/accounts/update", middleware.AuditMiddleware("accounts.update"), middleware.RequirePermission(constant.PERM_ACCOUNTS_WRITE), commonapi.UpdateAccountHandler)
+	vaulted.GET("/accounts/find", middleware.AuditMiddleware("accounts.read"), middleware.RequirePermission(constant.PERM_ACCOUNTS_READ), commonapi.FindAccountsHandler)
+	vaulted.GET("/accounts/list", middleware.AuditMiddleware("accounts.read"), middleware.RequirePermission(constant.PERM_ACCOUNTS_READ), commonapi.FindAccountsListHandler)
+	vaulted.POST("/accounts/reveal", middleware.AuditMiddleware("accounts.reveal"), middleware.RequirePermission(constant.PERM_ACCOUNTS_READ, constant.PERM_ACCOUNTS_READ_PLAINTEXT), commonapi.RevealAccountHandler)
+	vaulted.POST("/accounts/export", middleware.AuditMiddleware("accounts.export"), middleware.RequirePermission(constant.PERM_ACCOUNTS_EXPORT, constant.PERM_ACCOUNTS_READ_PLAINTEXT), commonapi.ExportAccountsHandler)
+	vaulted.POST("/accounts/import", middleware.AuditMiddleware("accounts.import"), middleware.RequirePermission(constant.PERM_ACCOUNTS_IMPORT), commonapi.ImportAccountsCSVHandler)
+	vaulted.POST("/accounts/import/kdbx", middleware.AuditMiddleware("accounts.import"), middleware.RequirePermission(constant.PERM_ACCOUNTS_IMPORT), commonapi.ImportAccountsKDBXHandler)
 
 	// 密钥记录管理
-	api.POST("/secrets/create", commonapi.CreateSecretHandler)
-	api.DELETE("/secrets/delete", commonapi.DeleteSecretHandler)
-	api.PUT("/secrets/update", commonapi.UpdateSecretHandler)
-	api.GET("/secrets/find", commonapi.FindSecretsHandler)
-	api.GET("/secrets/list", commonapi.FindSecretsListHandler)
-	api.GET("/secrets/export", commonapi.ExportSecretsCSVHandler)
-	api.POST("/secrets/import", commonapi.ImportSecretsCSVHandler)
+	vaulted.POST("/secrets/create", middleware.AuditMiddleware("secrets.create"), middleware.RequirePermission(constant.PERM_SECRETS_WRITE), commonapi.CreateSecretHandler)
+	vaulted.DELETE("/secrets/delete", middleware.AuditMiddleware("secrets.delete"), middleware.RequirePermission(constant.PERM_SECRETS_WRITE), commonapi.DeleteSecretHandler)
+	vaulted.GET("/secrets/trash", middleware.AuditMiddleware("secrets.read"), middleware.RequirePermission(constant.PERM_SECRETS_READ), commonapi.FindTrashedSecretsHandler)
+	vaulted.POST("/secrets/trash/restore", middleware.AuditMiddleware("secrets.restore"), middleware.RequirePermission(constant.PERM_SECRETS_WRITE), commonapi.RestoreSecretsHandler)
+	vaulted.DELETE("/secrets/trash/purge", middleware.AuditMiddleware("secrets.purge"), middleware.RequirePermission(constant.PERM_SECRETS_WRITE), commonapi.PurgeSecretsHandler)
+	vaulted.GET("/secrets/history", middleware.AuditMiddleware("secrets.read"), middleware.RequirePermission(constant.PERM_SECRETS_READ), commonapi.FindSecretHistoryHandler)
+	vaulted.POST("/secrets/rollback", middleware.AuditMiddleware("secrets.rollback"), middleware.RequirePermission(constant.PERM_SECRETS_WRITE), commonapi.RollbackSecretHandler)
+	vaulted.PUT("/secrets/update", middleware.AuditMiddleware("secrets.update"), middleware.RequirePermission(constant.PERM_SECRETS_WRITE), commonapi.UpdateSecretHandler)
+	vaulted.GET("/secrets/find", middleware.AuditMiddleware("secrets.read"), middleware.RequirePermission(constant.PERM_SECRETS_READ), commonapi.FindSecretsHandler)
+	vaulted.GET("/secrets/list", middleware.AuditMiddleware("secrets.read"), middleware.RequirePermission(constant.PERM_SECRETS_READ), commonapi.FindSecretsListHandler)
+	vaulted.POST("/secrets/reveal", middleware.AuditMiddleware("secrets.reveal"), middleware.RequirePermission(constant.PERM_SECRETS_READ, constant.PERM_SECRETS_READ_PLAINTEXT), commonapi.RevealSecretHandler)
+	vaulted.POST("/secrets/export", middleware.AuditMiddleware("secrets.export"), middleware.RequirePermission(constant.PERM_SECRETS_EXPORT, constant.PERM_SECRETS_READ_PLAINTEXT), commonapi.ExportSecretsHandler)
+	vaulted.POST("/secrets/import", middleware.AuditMiddleware("secrets.import"), middleware.RequirePermission(constant.PERM_SECRETS_IMPORT), commonapi.ImportSecretsCSVHandler)
 
 	// 主机记录管理
-	api.POST("/hosts/create", commonapi.CreateHostHandler)
-	api.DELETE("/hosts/delete", commonapi.DeleteHostHandler)
-	api.PUT("/hosts/update", commonapi.UpdateHostHandler)
-	api.GET("/hosts/find", commonapi.FindHostsHandler)
-	api.GET("/hosts/list", commonapi.FindHostsListHandler)
-	api.GET("/hosts/export", commonapi.ExportHostsCSVHandler)
-	api.POST("/hosts/import", commonapi.ImportHostsCSVHandler)
+	vaulted.POST("/hosts/create", middleware.AuditMiddleware("hosts.create"), middleware.RequirePermission(constant.PERM_HOSTS_WRITE), commonapi.CreateHostHandler)
+	vaulted.DELETE("/hosts/delete", middleware.AuditMiddleware("hosts.delete"), middleware.RequirePermission(constant.PERM_HOSTS_WRITE), commonapi.DeleteHostHandler)
+	vaulted.GET("/hosts/trash", middleware.AuditMiddleware("hosts.read"), middleware.RequirePermission(constant.PERM_HOSTS_READ), commonapi.FindTrashedHostsHandler)
+	vaulted.POST("/hosts/trash/restore", middleware.AuditMiddleware("hosts.restore"), middleware.RequirePermission(constant.PERM_HOSTS_WRITE), commonapi.RestoreHostsHandler)
+	vaulted.DELETE("/hosts/trash/purge", middleware.AuditMiddleware("hosts.purge"), middleware.RequirePermission(constant.PERM_HOSTS_WRITE), commonapi.PurgeHostsHandler)
+	vaulted.GET("/hosts/history", middleware.AuditMiddleware("hosts.read"), middleware.RequirePermission(constant.PERM_HOSTS_READ), commonapi.FindHostHistoryHandler)
+	vaulted.POST("/hosts/rollback", middleware.AuditMiddleware("hosts.rollback"), middleware.RequirePermission(constant.PERM_HOSTS_WRITE), commonapi.RollbackHostHandler)
+	vaulted.PUT("/hosts/update", middleware.AuditMiddleware("hosts.update"), middleware.RequirePermission(constant.PERM_HOSTS_WRITE), commonapi.UpdateHostHandler)
+	vaulted.GET("/hosts/find", middleware.AuditMiddleware("hosts.read"), middleware.RequirePermission(constant.PERM_HOSTS_READ), commonapi.FindHostsHandler)
+	vaulted.GET("/hosts/list", middleware.AuditMiddleware("hosts.read"), middleware.RequirePermission(constant.PERM_HOSTS_READ), commonapi.FindHostsListHandler)
+	vaulted.POST("/hosts/reveal", middleware.AuditMiddleware("hosts.reveal"), middleware.RequirePermission(constant.PERM_HOSTS_READ, constant.PERM_HOSTS_READ_PLAINTEXT), commonapi.RevealHostHandler)
+	vaulted.POST("/hosts/export", middleware.AuditMiddleware("hosts.export"), middleware.RequirePermission(constant.PERM_HOSTS_EXPORT, constant.PERM_HOSTS_READ_PLAINTEXT), commonapi.ExportHostsHandler)
+	vaulted.POST("/hosts/import", middleware.AuditMiddleware("hosts.import"), middleware.RequirePermission(constant.PERM_HOSTS_IMPORT), commonapi.ImportHostsCSVHandler)
+
+	// 第三方密码库导入，按记录类别分别校验导入权限
+	vaulted.POST("/import", middleware.AuditMiddleware("records.import"), commonapi.ImportRecordsHandler)
 
 	// 站点记录管理
-	api.POST("/sites/create", commonapi.CreateSiteHandler)
-	api.DELETE("/sites/delete", commonapi.DeleteSiteHandler)
-	api.PUT("/sites/update", commonapi.UpdateSiteHandler)
-	api.GET("/sites/find", commonapi.FindSitesHandler)
-	api.GET("/sites/list", commonapi.FindSitesListHandler)
-	api.GET("/sites/export", commonapi.ExportSitesCSVHandler)
-	api.POST("/sites/import", commonapi.ImportSitesCSVHandler)
+	api.POST("/sites/create", middleware.RequirePermission(constant.PERM_SITES_WRITE), commonapi.CreateSiteHandler)
+	api.DELETE("/sites/delete", middleware.RequirePermission(constant.PERM_SITES_WRITE), commonapi.DeleteSiteHandler)
+	api.GET("/sites/trash", middleware.RequirePermission(constant.PERM_SITES_READ), commonapi.FindTrashedSitesHandler)
+	api.POST("/sites/trash/restore", middleware.RequirePermission(constant.PERM_SITES_WRITE), commonapi.RestoreSitesHandler)
+	api.DELETE("/sites/trash/purge", middleware.RequirePermission(constant.PERM_SITES_WRITE), commonapi.PurgeSitesHandler)
+	api.PUT("/sites/update", middleware.RequirePermission(constant.PERM_SITES_WRITE), commonapi.UpdateSiteHandler)
+	api.GET("/sites/find", middleware.RequirePermission(constant.PERM_SITES_READ), commonapi.FindSitesHandler)
+	api.GET("/sites/list", middleware.RequirePermission(constant.PERM_SITES_READ), commonapi.FindSitesListHandler)
+	api.POST("/sites/export", middleware.RequirePermission(constant.PERM_SITES_EXPORT), commonapi.ExportSitesHandler)
+	api.POST("/sites/import", middleware.RequirePermission(constant.PERM_SITES_IMPORT), commonapi.ImportSitesCSVHandler)
+
+	// 记录共享管理（仅限记录所有者，且须经登录会话）
+	api.POST("/shares/create", middleware.AuditMiddleware("shares.create"), middleware.RequireSession(), commonapi.CreateShareHandler)
+	api.DELETE("/shares/delete", middleware.AuditMiddleware("shares.delete"), middleware.RequireSession(), commonapi.DeleteShareHandler)
+	api.GET("/shares/list", commonapi.FindRecordSharesHandler)
 
 	// 图标管理
-	api.POST("/icons/upload-platform-icon", commonapi.UploadPlatformIconHandler)
+	api.POST("/icons/upload-platform-icon", middleware.RequirePermission(constant.PERM_ICONS_UPLOAD), commonapi.UploadPlatformIconHandler)
 	api.GET("/icons/platform-icons", commonapi.GetPlatformIconsListHandler)
-	api.POST("/icons/upload-os-icon", commonapi.UploadOSIconHandler)
+	api.POST("/icons/upload-os-icon", middleware.RequirePermission(constant.PERM_ICONS_UPLOAD), commonapi.UploadOSIconHandler)
 	api.GET("/icons/os-icons", commonapi.GetOSIconsListHandler)
-	api.POST("/icons/upload-site-icon", commonapi.UploadSiteIconHandler)
+	api.POST("/icons/upload-site-icon", middleware.RequirePermission(constant.PERM_ICONS_UPLOAD), commonapi.UploadSiteIconHandler)
 	api.GET("/icons/site-icons", commonapi.GetSiteIconsListHandler)
 }
@@ -1,12 +1,11 @@
 package common
 
 import (
-	"encoding/csv"
-	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
-	"time"
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/exporter"
+	"cyber-life/pkg/patch"
+	"encoding/json"
 
 	commonmodel "cyber-life/internal/model/common"
 	commonrepository "cyber-life/internal/repository/common"
@@ -17,9 +16,10 @@ import (
 // @Date:   2025/10/29 13:58
 // @Desc:	账号记录服务
 
-// CreateAccount 创建账号记录
-func CreateAccount(accountType, platform, platformURL, username, password, securityEmail, securityPhone, remark, logo string) error {
+// CreateAccount 创建账号记录，记录归属于创建者
+func CreateAccount(ownerID uint, accountType, platform, platformURL, username, password, securityEmail, securityPhone, remark, logo string) error {
 	account := &commonmodel.Account{
+		OwnerID:       ownerID,
 		Type:          accountType,
 		Platform:      platform,
 		PlatformURL:   platformURL,
@@ -34,19 +34,63 @@ func CreateAccount(accountType, platform, platformURL, username, password, secur
 	return commonrepository.CreateAccount(account)
 }
 
-// DeleteAccount 删除账号记录
-func DeleteAccount(accountID uint, hardDelete bool) error {
+// DeleteAccount 删除账号记录（仅限记录所有者）：软删除将记录移入回收站，硬删除仅限回收站中的记录
+func DeleteAccount(userID, accountID uint, hardDelete bool) error {
+	var err error
+	if hardDelete {
+		err = commonrepository.CheckRecordTrashed(constant.RESOURCE_ACCOUNTS, userID, accountID)
+	} else {
+		err = commonrepository.CheckRecordOwner(constant.RESOURCE_ACCOUNTS, userID, accountID)
+	}
+	if err != nil {
+		return err
+	}
+
 	account := &commonmodel.Account{}
 	account.ID = accountID
 
 	if hardDelete {
+		err = commonrepository.HardDeleteSharesByResource(constant.RESOURCE_ACCOUNTS, accountID)
+		if err != nil {
+			return err
+		}
+		err = commonrepository.HardDeleteRevisionsByResource(constant.RESOURCE_ACCOUNTS, accountID)
+		if err != nil {
+			return err
+		}
 		return commonrepository.HardDeleteAccount(account)
 	}
 	return commonrepository.SoftDeleteAccount(account)
 }
 
-// UpdateAccount 更新账号记录
-func UpdateAccount(accountID uint, accountType, platform, platformURL, username, password, securityEmail, securityPhone, remark, logo string) error {
+// RestoreAccount 将回收站中的账号记录恢复（仅限记录所有者）
+func RestoreAccount(userID, accountID uint) error {
+	err := commonrepository.CheckRecordTrashed(constant.RESOURCE_ACCOUNTS, userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	return commonrepository.RestoreRecord(constant.RESOURCE_ACCOUNTS, accountID)
+}
+
+// FindTrashedAccounts 查询回收站中的账号记录（仅限记录所有者），敏感字段以掩码返回
+func FindTrashedAccounts(userID uint, page, size int) ([]commonmodel.Account, int64, error) {
+	accounts, total, err := commonrepository.FindTrashedAccounts(userID, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	maskAccounts(accounts)
+	return accounts, total, nil
+}
+
+// UpdateAccount 更新账号记录（仅限所有者或被以读写方式共享的用户）
+func UpdateAccount(userID, accountID uint, accountType, platform, platformURL, username, password, securityEmail, securityPhone, remark, logo string) error {
+	err := commonrepository.CheckRecordWritable(constant.RESOURCE_ACCOUNTS, userID, accountID)
+	if err != nil {
+		return err
+	}
+
 	account := &commonmodel.Account{
 		Type:          accountType,
 		Platform:      platform,
@@ -60,172 +104,209 @@ func UpdateAccount(accountID uint, accountType, platform, platformURL, username,
 	}
 	account.ID = accountID
 
-	return commonrepository.UpdateAccount(account)
+	return commonrepository.UpdateAccount(userID, account)
 }
 
-// UpdateAccountFields 更新账号记录（只更新指定字段）
-func UpdateAccountFields(accountID uint, fields map[string]interface{}) error {
-	return commonrepository.UpdateAccountFields(accountID, fields)
-}
+// accountPatchFields 可经补丁修改的账号记录字段
+var accountPatchFields = []string{"type", "platform", "platform_url", "username", "password", "security_email", "security_phone", "remark", "logo"}
+
+// PatchAccount 按JSON Merge Patch语义更新账号记录（仅可修改 accountPatchFields 中的字段，回传的掩码值视为未修改；仅限所有者或被以读写方式共享的用户），
+// 字段未知、只读、类型不符或不满足校验规则时返回 patch.Errors
+func PatchAccount(userID, accountID uint, document map[string]json.RawMessage) error {
+	err := commonrepository.CheckRecordWritable(constant.RESOURCE_ACCOUNTS, userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	account, err := commonrepository.FindAccountByID(userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	dropMaskedValues(document, "password")
+	fields, err := patch.Apply(account, document, accountPatchFields)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
 
-// FindAccountsList 获取账号记录列表
-func FindAccountsList(page, size int) ([]commonmodel.Account, int64, error) {
-	return commonrepository.FindAccountsList(page, size)
+	return commonrepository.UpdateAccountFields(userID, accountID, constant.REVISION_ACTION_UPDATE, fields)
 }
 
-// FindAccounts 搜索账号记录
-func FindAccounts(keyword string, page, size int) ([]commonmodel.Account, int64, error) {
-	return commonrepository.FindAccounts(keyword, page, size)
+// FindAccountHistory 查询账号记录的修订历史（仅限用户拥有或被共享的记录），按修订时间倒序排列，敏感字段以掩码返回
+func FindAccountHistory(userID, accountID uint, page, size int) ([]commonmodel.Revision, int64, error) {
+	return findRecordHistory(constant.RESOURCE_ACCOUNTS, userID, accountID, page, size)
 }
 
-// ExportAccountsCSV 导出账号记录为CSV文件
-func ExportAccountsCSV() (string, error) {
-	tempDir := "temp"
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return "", err
+// RollbackAccount 将账号记录回滚到指定修订之前的状态，回滚本身作为一条新的修订保存（仅限所有者或被以读写方式共享的用户）
+func RollbackAccount(userID, accountID, revisionID uint) error {
+	fields, err := rollbackFields(constant.RESOURCE_ACCOUNTS, userID, accountID, revisionID)
+	if err != nil {
+		return err
 	}
 
-	filename := fmt.Sprintf("accounts_%s.csv", time.Now().Format("20060102_150405"))
-	filePath := filepath.Join(tempDir, filename)
-	file, err := os.Create(filePath)
+	return commonrepository.UpdateAccountFields(userID, accountID, constant.REVISION_ACTION_ROLLBACK, fields)
+}
+
+// FindAccountsList 获取账号记录列表（仅限用户拥有或被共享的记录，敏感字段以掩码返回）
+func FindAccountsList(userID uint, page, size int) ([]commonmodel.Account, int64, error) {
+	accounts, total, err := commonrepository.FindAccountsList(userID, page, size)
 	if err != nil {
-		return "", err
+		return nil, 0, err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	maskAccounts(accounts)
+	return accounts, total, nil
+}
 
-	headers := []string{"ID", "类型", "平台", "平台链接", "账号", "密码", "安全邮箱", "安全电话", "备注", "Logo", "创建时间", "更新时间"}
-	err = writer.Write(headers)
+// FindAccounts 搜索账号记录（仅限用户拥有或被共享的记录，敏感字段以掩码返回）
+func FindAccounts(userID uint, keyword string, page, size int) ([]commonmodel.Account, int64, error) {
+	accounts, total, err := commonrepository.FindAccounts(userID, keyword, page, size)
 	if err != nil {
-		return "", err
+		return nil, 0, err
 	}
 
-	accounts, _, err := commonrepository.FindAccountsList(1, 999999)
+	maskAccounts(accounts)
+	return accounts, total, nil
+}
+
+// RevealAccountField 获取单条账号记录指定敏感字段的明文（仅限用户拥有或被共享的记录）
+func RevealAccountField(userID, accountID uint, field string) (string, error) {
+	account, err := commonrepository.FindAccountByID(userID, accountID)
 	if err != nil {
 		return "", err
 	}
 
-	for _, account := range accounts {
-		record := []string{
-			strconv.FormatUint(uint64(account.ID), 10),
-			account.Type,
-			account.Platform,
-			account.PlatformURL,
-			account.Username,
-			account.Password,
-			account.SecurityEmail,
-			account.SecurityPhone,
-			account.Remark,
-			account.Logo,
-			account.CreatedAt.Format("2006-01-02 15:04:05"),
-			account.UpdatedAt.Format("2006-01-02 15:04:05"),
-		}
-		err = writer.Write(record)
-		if err != nil {
-			return "", err
+	switch field {
+	case "password":
+		return account.Password, nil
+	default:
+		return "", errs.ErrInvalidRevealField
+	}
+}
+
+// maskAccounts 将账号记录中的敏感字段替换为掩码
+func maskAccounts(accounts []commonmodel.Account) {
+	for i := range accounts {
+		if accounts[i].Password != "" {
+			accounts[i].Password = constant.MASKED_VALUE
 		}
 	}
+}
 
-	return filePath, nil
+// accountExportColumns 账号记录的导出列，CSV文件的列顺序与导入格式一致
+var accountExportColumns = []exporter.Column{
+	{Key: "id", Title: "ID"},
+	{Key: "type", Title: "类型"},
+	{Key: "platform", Title: "平台"},
+	{Key: "platform_url", Title: "平台链接"},
+	{Key: "username", Title: "账号"},
+	{Key: "password", Title: "密码"},
+	{Key: "security_email", Title: "安全邮箱"},
+	{Key: "security_phone", Title: "安全电话"},
+	{Key: "remark", Title: "备注"},
+	{Key: "logo", Title: "Logo"},
+	{Key: "created_at", Title: "创建时间"},
+	{Key: "updated_at", Title: "更新时间"},
 }
 
-// ImportAccountsCSV 从CSV文件导入账号记录
-func ImportAccountsCSV(filePath string) (*commonmodel.ImportResult, error) {
-	file, err := os.Open(filePath)
+// ExportAccounts 按写入器的格式流式导出账号记录（仅包含用户可见且匹配关键字的记录），按ID游标分批查询，返回导出的记录数
+func ExportAccounts(userID uint, keyword string, writer exporter.Writer) (int, error) {
+	err := writer.WriteHeader(accountExportColumns)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
+	count := 0
+	var lastID uint
+	for {
+		accounts, err := commonrepository.FindAccountsAfter(userID, keyword, lastID, exportBatchSize)
+		if err != nil {
+			return count, err
+		}
+
+		for _, account := range accounts {
+			err = writer.WriteRow([]interface{}{
+				account.ID,
+				account.Type,
+				account.Platform,
+				account.PlatformURL,
+				account.Username,
+				account.Password,
+				account.SecurityEmail,
+				account.SecurityPhone,
+				account.Remark,
+				account.Logo,
+				formatExportTime(account.CreatedAt),
+				formatExportTime(account.UpdatedAt),
+			})
+			if err != nil {
+				return count, err
+			}
+		}
+
+		count += len(accounts)
+		if len(accounts) < exportBatchSize {
+			break
+		}
+		lastID = accounts[len(accounts)-1].ID
 	}
 
-	if len(records) < 2 {
-		return nil, fmt.Errorf("invalid cvs file format")
+	return count, writer.Close()
+}
+
+// ImportAccountsCSV 从CSV文件导入账号记录，导入的记录归属于导入者；逐行返回行号、失败原因与解析结果
+func ImportAccountsCSV(ownerID uint, filePath string, options commonmodel.ImportOptions) (*commonmodel.ImportResult, error) {
+	records, err := readImportCSV(filePath)
+	if err != nil {
+		return nil, err
 	}
 
-	importedCount := 0
-	failedCount := 0
-	for _, record := range records[1:] {
+	rows := make([]importRow, 0, len(records))
+	for index, record := range records {
 		// CSV格式: 类型,平台,平台链接,账号,密码,安全邮箱,安全电话,备注,Logo（ID和时间字段会被忽略）
-		if len(record) < 5 {
-			failedCount++
+		row, ok := newCSVImportRow(index, record, 5)
+		if !ok {
+			rows = append(rows, row)
 			continue
 		}
 
-		accountType := ""
-		platform := ""
-		platformURL := ""
-		username := ""
-		password := ""
-		securityEmail := ""
-		securityPhone := ""
-		remark := ""
-		logo := ""
-
-		if len(record) >= 12 {
+		fields := record.fields
+		if len(fields) >= 12 {
 			// 完整格式：ID, 类型, 平台, 平台链接, 账号, 密码, 安全邮箱, 安全电话, 备注, Logo, 创建时间, 更新时间
-			accountType = record[1]
-			platform = record[2]
-			platformURL = record[3]
-			username = record[4]
-			password = record[5]
-			if len(record) > 6 {
-				securityEmail = record[6]
-			}
-			if len(record) > 7 {
-				securityPhone = record[7]
-			}
-			if len(record) > 8 {
-				remark = record[8]
-			}
-			if len(record) > 9 {
-				logo = record[9]
-			}
-		} else {
-			// 简化格式：类型, 平台, 平台链接, 账号, 密码, 安全邮箱, 安全电话, 备注, Logo
-			accountType = record[0]
-			platform = record[1]
-			platformURL = record[2]
-			username = record[3]
-			password = record[4]
-			if len(record) > 5 {
-				securityEmail = record[5]
-			}
-			if len(record) > 6 {
-				securityPhone = record[6]
-			}
-			if len(record) > 7 {
-				remark = record[7]
-			}
-			if len(record) > 8 {
-				logo = record[8]
-			}
+			fields = fields[1:]
 		}
+		// 简化格式：类型, 平台, 平台链接, 账号, 密码, 安全邮箱, 安全电话, 备注, Logo
+		fields = append(fields, make([]string, 9)...)
 
-		// 验证必填字段
-		if accountType == "" || platform == "" || platformURL == "" || username == "" || password == "" {
-			failedCount++
-			continue
+		account := &commonmodel.Account{
+			OwnerID:       ownerID,
+			Type:          fields[0],
+			Platform:      fields[1],
+			PlatformURL:   fields[2],
+			Username:      fields[3],
+			Password:      fields[4],
+			SecurityEmail: fields[5],
+			SecurityPhone: fields[6],
+			Remark:        fields[7],
+			Logo:          fields[8],
 		}
+		row.model = account
+		row.item.Title = account.Platform
+		row.item.Group = account.Type
+		row.item.Resource = constant.RESOURCE_ACCOUNTS
 
-		// 创建账号记录
-		err = CreateAccount(accountType, platform, platformURL, username, password, securityEmail, securityPhone, remark, logo)
+		// 验证必填字段
+		err = requireFields("type", account.Type, "platform", account.Platform, "platform_url", account.PlatformURL,
+			"username", account.Username, "password", account.Password)
 		if err != nil {
-			failedCount++
-			continue
+			failImportRow(&row, err)
 		}
 
-		importedCount++
+		rows = append(rows, row)
 	}
 
-	return &commonmodel.ImportResult{
-		SuccessCount: importedCount,
-		FailedCount:  failedCount,
-	}, nil
+	return commitImportRows(ownerID, rows, options)
 }
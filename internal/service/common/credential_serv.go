@@ -0,0 +1,30 @@
+package common
+
+import (
+	commonrepository "cyber-life/internal/repository/common"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 11:28
+// @Desc:	凭据字段加密迁移服务
+
+// EncryptPlaintextCredentials 加密账号、密钥、主机记录中历史遗留的明文凭据，返回处理的记录数
+func EncryptPlaintextCredentials() (int, error) {
+	var total int
+
+	migrations := []func() (int, error){
+		commonrepository.EncryptPlaintextAccounts,
+		commonrepository.EncryptPlaintextSecrets,
+		commonrepository.EncryptPlaintextHosts,
+	}
+	for _, migrate := range migrations {
+		count, err := migrate()
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+
+	return total, nil
+}
@@ -0,0 +1,22 @@
+package common
+
+import (
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 16:05
+// @Desc:	记录导出的公共定义
+
+const (
+	// exportBatchSize 导出时每批查询的记录数
+	exportBatchSize = 500
+	// exportTimeLayout 导出文件中的时间格式
+	exportTimeLayout = "2006-01-02 15:04:05"
+)
+
+// formatExportTime 格式化导出文件中的时间
+func formatExportTime(t time.Time) string {
+	return t.Format(exportTimeLayout)
+}
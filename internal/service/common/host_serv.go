@@ -1,11 +1,12 @@
 package common
 
 import (
-	"encoding/csv"
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/exporter"
+	"cyber-life/pkg/patch"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 
@@ -18,9 +19,10 @@ import (
 // @Date:   2025/10/30 13:13
 // @Desc:	主机记录服务
 
-// CreateHost 创建主机记录
-func CreateHost(provider, providerURL, hostname, address string, ports map[string]string, username, password, os, logo string, cpuNum, ramSize, diskSize int, expirationTime int64) error {
+// CreateHost 创建主机记录，记录归属于创建者
+func CreateHost(ownerID uint, provider, providerURL, hostname, address string, ports map[string]string, username, password, os, logo string, cpuNum, ramSize, diskSize int, expirationTime int64) error {
 	host := &commonmodel.Host{
+		OwnerID:        ownerID,
 		Provider:       provider,
 		ProviderURL:    providerURL,
 		Hostname:       hostname,
@@ -39,19 +41,63 @@ func CreateHost(provider, providerURL, hostname, address string, ports map[strin
 	return commonrepository.CreateHost(host)
 }
 
-// DeleteHost 删除主机记录
-func DeleteHost(hostID uint, hardDelete bool) error {
+// DeleteHost 删除主机记录（仅限记录所有者）：软删除将记录移入回收站，硬删除仅限回收站中的记录
+func DeleteHost(userID, hostID uint, hardDelete bool) error {
+	var err error
+	if hardDelete {
+		err = commonrepository.CheckRecordTrashed(constant.RESOURCE_HOSTS, userID, hostID)
+	} else {
+		err = commonrepository.CheckRecordOwner(constant.RESOURCE_HOSTS, userID, hostID)
+	}
+	if err != nil {
+		return err
+	}
+
 	host := &commonmodel.Host{}
 	host.ID = hostID
 
 	if hardDelete {
+		err = commonrepository.HardDeleteSharesByResource(constant.RESOURCE_HOSTS, hostID)
+		if err != nil {
+			return err
+		}
+		err = commonrepository.HardDeleteRevisionsByResource(constant.RESOURCE_HOSTS, hostID)
+		if err != nil {
+			return err
+		}
 		return commonrepository.HardDeleteHost(host)
 	}
 	return commonrepository.SoftDeleteHost(host)
 }
 
-// UpdateHost 更新主机记录
-func UpdateHost(hostID uint, provider, providerURL, hostname, address string, ports map[string]string, username, password, os, logo string, cpuNum, ramSize, diskSize int, expirationTime int64) error {
+// RestoreHost 将回收站中的主机记录恢复（仅限记录所有者）
+func RestoreHost(userID, hostID uint) error {
+	err := commonrepository.CheckRecordTrashed(constant.RESOURCE_HOSTS, userID, hostID)
+	if err != nil {
+		return err
+	}
+
+	return commonrepository.RestoreRecord(constant.RESOURCE_HOSTS, hostID)
+}
+
+// FindTrashedHosts 查询回收站中的主机记录（仅限记录所有者），敏感字段以掩码返回
+func FindTrashedHosts(userID uint, page, size int) ([]commonmodel.Host, int64, error) {
+	hosts, total, err := commonrepository.FindTrashedHosts(userID, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	maskHosts(hosts)
+	return hosts, total, nil
+}
+
+// UpdateHost 更新主机记录（仅限所有者或被以读写方式共享的用户）
+func UpdateHost(userID, hostID uint, provider, providerURL, hostname, address string, ports map[string]string, username, password, os, logo string, cpuNum, ramSize, diskSize int, expirationTime int64) error {
+	err := commonrepository.CheckRecordWritable(constant.RESOURCE_HOSTS, userID, hostID)
+	if err != nil {
+		return err
+	}
+
 	host := &commonmodel.Host{
 		Provider:       provider,
 		ProviderURL:    providerURL,
@@ -69,226 +115,293 @@ func UpdateHost(hostID uint, provider, providerURL, hostname, address string, po
 	}
 	host.ID = hostID
 
-	return commonrepository.UpdateHost(host)
+	return commonrepository.UpdateHost(userID, host)
 }
 
-// UpdateHostFields 更新主机记录（只更新指定字段）
-func UpdateHostFields(hostID uint, fields map[string]interface{}) error {
-	return commonrepository.UpdateHostFields(hostID, fields)
+// hostPatchFields 可经补丁修改的主机记录字段
+var hostPatchFields = []string{"provider", "provider_url", "hostname", "address", "ports", "username", "password", "os", "logo", "cpu_num", "ram_size", "disk_size", "expiration_time"}
+
+// PatchHost 按JSON Merge Patch语义更新主机记录（仅可修改 hostPatchFields 中的字段，回传的掩码值视为未修改；仅限所有者或被以读写方式共享的用户），
+// 字段未知、只读、类型不符或不满足校验规则时返回 patch.Errors
+func PatchHost(userID, hostID uint, document map[string]json.RawMessage) error {
+	err := commonrepository.CheckRecordWritable(constant.RESOURCE_HOSTS, userID, hostID)
+	if err != nil {
+		return err
+	}
+
+	host, err := commonrepository.FindHostByID(userID, hostID)
+	if err != nil {
+		return err
+	}
+
+	dropMaskedValues(document, "password")
+	fields, err := patch.Apply(host, document, hostPatchFields)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	err = encodeHostPorts(fields)
+	if err != nil {
+		return err
+	}
+
+	return commonrepository.UpdateHostFields(userID, hostID, constant.REVISION_ACTION_UPDATE, fields)
 }
 
-// FindHostsList 获取主机记录列表
-func FindHostsList(page, size int) ([]commonmodel.Host, int64, error) {
-	return commonrepository.FindHostsList(page, size)
+// FindHostHistory 查询主机记录的修订历史（仅限用户拥有或被共享的记录），按修订时间倒序排列，敏感字段以掩码返回
+func FindHostHistory(userID, hostID uint, page, size int) ([]commonmodel.Revision, int64, error) {
+	return findRecordHistory(constant.RESOURCE_HOSTS, userID, hostID, page, size)
 }
 
-// FindHosts 搜索主机记录
-func FindHosts(keyword string, page, size int) ([]commonmodel.Host, int64, error) {
-	return commonrepository.FindHosts(keyword, page, size)
+// RollbackHost 将主机记录回滚到指定修订之前的状态，回滚本身作为一条新的修订保存（仅限所有者或被以读写方式共享的用户）
+func RollbackHost(userID, hostID, revisionID uint) error {
+	fields, err := rollbackFields(constant.RESOURCE_HOSTS, userID, hostID, revisionID)
+	if err != nil {
+		return err
+	}
+
+	err = encodeHostPorts(fields)
+	if err != nil {
+		return err
+	}
+
+	return commonrepository.UpdateHostFields(userID, hostID, constant.REVISION_ACTION_ROLLBACK, fields)
 }
 
-// ExportHostsCSV 导出主机记录为CSV文件
-func ExportHostsCSV() (string, error) {
-	tempDir := "temp"
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return "", err
+// encodeHostPorts 按字段更新时端口不经过序列化器，须先将端口映射转换为JSON文本
+func encodeHostPorts(fields map[string]interface{}) error {
+	ports, ok := fields["ports"]
+	if !ok {
+		return nil
 	}
 
-	filename := fmt.Sprintf("hosts_%s.csv", time.Now().Format("20060102_150405"))
-	filePath := filepath.Join(tempDir, filename)
-	file, err := os.Create(filePath)
+	portsJSON, err := json.Marshal(ports)
 	if err != nil {
-		return "", err
+		return err
 	}
-	defer file.Close()
+	fields["ports"] = string(portsJSON)
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	return nil
+}
 
-	headers := []string{"ID", "提供商", "提供商链接", "主机名", "地址", "端口映射", "用户名", "密码", "操作系统", "Logo", "CPU核心数", "内存大小(MB)", "磁盘大小(MB)", "到期时间", "创建时间", "更新时间"}
-	err = writer.Write(headers)
+// FindHostsList 获取主机记录列表（仅限用户拥有或被共享的记录，敏感字段以掩码返回）
+func FindHostsList(userID uint, page, size int) ([]commonmodel.Host, int64, error) {
+	hosts, total, err := commonrepository.FindHostsList(userID, page, size)
 	if err != nil {
-		return "", err
+		return nil, 0, err
 	}
 
-	hosts, _, err := commonrepository.FindHostsList(1, 999999)
+	maskHosts(hosts)
+	return hosts, total, nil
+}
+
+// FindHosts 搜索主机记录（仅限用户拥有或被共享的记录，敏感字段以掩码返回）
+func FindHosts(userID uint, keyword string, page, size int) ([]commonmodel.Host, int64, error) {
+	hosts, total, err := commonrepository.FindHosts(userID, keyword, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	maskHosts(hosts)
+	return hosts, total, nil
+}
+
+// RevealHostField 获取单条主机记录指定敏感字段的明文（仅限用户拥有或被共享的记录）
+func RevealHostField(userID, hostID uint, field string) (string, error) {
+	host, err := commonrepository.FindHostByID(userID, hostID)
 	if err != nil {
 		return "", err
 	}
 
-	for _, host := range hosts {
-		// 将 Ports map 序列化为 JSON 字符串
-		portsJSON, err := json.Marshal(host.Ports)
-		if err != nil {
-			portsJSON = []byte("{}")
+	switch field {
+	case "password":
+		return host.Password, nil
+	default:
+		return "", errs.ErrInvalidRevealField
+	}
+}
+
+// maskHosts 将主机记录中的敏感字段替换为掩码
+func maskHosts(hosts []commonmodel.Host) {
+	for i := range hosts {
+		if hosts[i].Password != "" {
+			hosts[i].Password = constant.MASKED_VALUE
 		}
+	}
+}
+
+// hostExportColumns 主机记录的导出列，CSV文件的列顺序与导入格式一致
+var hostExportColumns = []exporter.Column{
+	{Key: "id", Title: "ID"},
+	{Key: "provider", Title: "提供商"},
+	{Key: "provider_url", Title: "提供商链接"},
+	{Key: "hostname", Title: "主机名"},
+	{Key: "address", Title: "地址"},
+	{Key: "ports", Title: "端口映射"},
+	{Key: "username", Title: "用户名"},
+	{Key: "password", Title: "密码"},
+	{Key: "os", Title: "操作系统"},
+	{Key: "logo", Title: "Logo"},
+	{Key: "cpu_num", Title: "CPU核心数"},
+	{Key: "ram_size", Title: "内存大小(MB)"},
+	{Key: "disk_size", Title: "磁盘大小(MB)"},
+	{Key: "expiration_time", Title: "到期时间"},
+	{Key: "created_at", Title: "创建时间"},
+	{Key: "updated_at", Title: "更新时间"},
+}
 
-		// 格式化到期时间
-		expirationTimeStr := ""
-		if host.ExpirationTime > 0 {
-			expirationTimeStr = time.Unix(host.ExpirationTime, 0).Format("2006-01-02 15:04:05")
+// ExportHosts 按写入器的格式流式导出主机记录（仅包含用户可见且匹配关键字的记录），按ID游标分批查询，返回导出的记录数
+func ExportHosts(userID uint, keyword string, writer exporter.Writer) (int, error) {
+	err := writer.WriteHeader(hostExportColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var lastID uint
+	for {
+		hosts, err := commonrepository.FindHostsAfter(userID, keyword, lastID, exportBatchSize)
+		if err != nil {
+			return count, err
 		}
 
-		record := []string{
-			strconv.FormatUint(uint64(host.ID), 10),
-			host.Provider,
-			host.ProviderURL,
-			host.Hostname,
-			host.Address,
-			string(portsJSON),
-			host.Username,
-			host.Password,
-			host.OS,
-			host.Logo,
-			strconv.Itoa(host.CpuNum),
-			strconv.Itoa(host.RamSize),
-			strconv.Itoa(host.DiskSize),
-			expirationTimeStr,
-			host.CreatedAt.Format("2006-01-02 15:04:05"),
-			host.UpdatedAt.Format("2006-01-02 15:04:05"),
+		for _, host := range hosts {
+			// 格式化到期时间
+			expirationTime := ""
+			if host.ExpirationTime > 0 {
+				expirationTime = time.Unix(host.ExpirationTime, 0).Format(exportTimeLayout)
+			}
+
+			err = writer.WriteRow([]interface{}{
+				host.ID,
+				host.Provider,
+				host.ProviderURL,
+				host.Hostname,
+				host.Address,
+				host.Ports,
+				host.Username,
+				host.Password,
+				host.OS,
+				host.Logo,
+				host.CpuNum,
+				host.RamSize,
+				host.DiskSize,
+				expirationTime,
+				formatExportTime(host.CreatedAt),
+				formatExportTime(host.UpdatedAt),
+			})
+			if err != nil {
+				return count, err
+			}
 		}
-		err = writer.Write(record)
-		if err != nil {
-			return "", err
+
+		count += len(hosts)
+		if len(hosts) < exportBatchSize {
+			break
 		}
+		lastID = hosts[len(hosts)-1].ID
 	}
 
-	return filePath, nil
+	return count, writer.Close()
 }
 
-// ImportHostsCSV 从CSV文件导入主机记录
-func ImportHostsCSV(filePath string) (*commonmodel.ImportResult, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+// ImportHostsCSV 从CSV文件导入主机记录，导入的记录归属于导入者；逐行返回行号、失败原因与解析结果
+func ImportHostsCSV(ownerID uint, filePath string, options commonmodel.ImportOptions) (*commonmodel.ImportResult, error) {
+	records, err := readImportCSV(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(records) < 2 {
-		return nil, fmt.Errorf("invalid cvs file format")
-	}
-
-	importedCount := 0
-	failedCount := 0
-	for _, record := range records[1:] {
+	rows := make([]importRow, 0, len(records))
+	for index, record := range records {
 		// CSV格式: 提供商,提供商链接,主机名,地址,端口映射,用户名,密码,操作系统,Logo,CPU核心数,内存大小,磁盘大小,到期时间（ID和时间字段会被忽略）
-		if len(record) < 7 {
-			failedCount++
+		row, ok := newCSVImportRow(index, record, 7)
+		if !ok {
+			rows = append(rows, row)
 			continue
 		}
 
-		provider := ""
-		providerURL := ""
-		hostname := ""
-		address := ""
-		portsStr := ""
-		username := ""
-		password := ""
-		os := ""
-		logo := ""
-		cpuNum := 0
-		ramSize := 0
-		diskSize := 0
-		expirationTime := int64(0)
-
-		if len(record) >= 16 {
+		fields := record.fields
+		if len(fields) >= 16 {
 			// 完整格式：ID, 提供商, 提供商链接, 主机名, 地址, 端口映射, 用户名, 密码, 操作系统, Logo, CPU核心数, 内存大小, 磁盘大小, 到期时间, 创建时间, 更新时间
-			provider = record[1]
-			providerURL = record[2]
-			hostname = record[3]
-			address = record[4]
-			portsStr = record[5]
-			username = record[6]
-			password = record[7]
-			if len(record) > 8 {
-				os = record[8]
-			}
-			if len(record) > 9 {
-				logo = record[9]
-			}
-			if len(record) > 10 {
-				cpuNum, _ = strconv.Atoi(record[10])
-			}
-			if len(record) > 11 {
-				ramSize, _ = strconv.Atoi(record[11])
-			}
-			if len(record) > 12 {
-				diskSize, _ = strconv.Atoi(record[12])
-			}
-			if len(record) > 13 && record[13] != "" {
-				// 解析日期时间字符串为时间戳
-				if t, err := time.Parse("2006-01-02 15:04:05", record[13]); err == nil {
-					expirationTime = t.Unix()
-				}
-			}
-		} else {
-			// 简化格式：提供商, 提供商链接, 主机名, 地址, 端口映射, 用户名, 密码, 操作系统, Logo, CPU核心数, 内存大小, 磁盘大小, 到期时间
-			provider = record[0]
-			providerURL = record[1]
-			hostname = record[2]
-			address = record[3]
-			portsStr = record[4]
-			username = record[5]
-			password = record[6]
-			if len(record) > 7 {
-				os = record[7]
-			}
-			if len(record) > 8 {
-				logo = record[8]
-			}
-			if len(record) > 9 {
-				cpuNum, _ = strconv.Atoi(record[9])
-			}
-			if len(record) > 10 {
-				ramSize, _ = strconv.Atoi(record[10])
-			}
-			if len(record) > 11 {
-				diskSize, _ = strconv.Atoi(record[11])
-			}
-			if len(record) > 12 && record[12] != "" {
-				// 解析日期时间字符串为时间戳
-				if t, err := time.Parse("2006-01-02 15:04:05", record[12]); err == nil {
-					expirationTime = t.Unix()
-				}
-			}
+			fields = fields[1:]
+		}
+		// 简化格式：提供商, 提供商链接, 主机名, 地址, 端口映射, 用户名, 密码, 操作系统, Logo, CPU核心数, 内存大小, 磁盘大小, 到期时间
+		fields = append(fields, make([]string, 13)...)
+
+		host := &commonmodel.Host{
+			OwnerID:     ownerID,
+			Provider:    fields[0],
+			ProviderURL: fields[1],
+			Hostname:    fields[2],
+			Address:     fields[3],
+			Ports:       make(map[string]string),
+			Username:    fields[5],
+			Password:    fields[6],
+			OS:          fields[7],
+			Logo:        fields[8],
+		}
+		row.model = host
+		row.item.Title = host.Hostname
+		row.item.Group = host.Provider
+		row.item.Resource = constant.RESOURCE_HOSTS
+
+		err = parseHostCSVFields(host, fields)
+		if err == nil {
+			// 验证必填字段
+			err = requireFields("provider", host.Provider, "provider_url", host.ProviderURL, "hostname", host.Hostname,
+				"address", host.Address, "username", host.Username, "password", host.Password)
+		}
+		if err != nil {
+			failImportRow(&row, err)
 		}
 
-		// 解析端口映射 JSON
-		var ports map[string]string
-		if portsStr != "" {
-			err := json.Unmarshal([]byte(portsStr), &ports)
-			if err != nil {
-				failedCount++
-				continue
-			}
-		} else {
-			ports = make(map[string]string)
+		rows = append(rows, row)
+	}
+
+	return commitImportRows(ownerID, rows, options)
+}
+
+// parseHostCSVFields 解析主机CSV数据行中的端口映射、数值与到期时间字段，空值保持默认值
+func parseHostCSVFields(host *commonmodel.Host, fields []string) error {
+	// 解析端口映射 JSON
+	if fields[4] != "" {
+		err := json.Unmarshal([]byte(fields[4]), &host.Ports)
+		if err != nil {
+			return fmt.Errorf("invalid ports JSON: %v", err)
 		}
+	}
 
-		// 验证必填字段
-		if provider == "" || providerURL == "" || hostname == "" || address == "" || username == "" || password == "" {
-			failedCount++
+	numbers := []struct {
+		name  string
+		value *int
+	}{
+		{"cpu_num", &host.CpuNum},
+		{"ram_size", &host.RamSize},
+		{"disk_size", &host.DiskSize},
+	}
+	for i, number := range numbers {
+		if fields[9+i] == "" {
 			continue
 		}
-
-		// 创建主机记录
-		err = CreateHost(provider, providerURL, hostname, address, ports, username, password, os, logo, cpuNum, ramSize, diskSize, expirationTime)
+		value, err := strconv.Atoi(fields[9+i])
 		if err != nil {
-			failedCount++
-			continue
+			return fmt.Errorf("invalid %s: %q is not an integer", number.name, fields[9+i])
 		}
+		*number.value = value
+	}
 
-		importedCount++
+	if fields[12] != "" {
+		// 解析日期时间字符串为时间戳
+		t, err := time.Parse("2006-01-02 15:04:05", fields[12])
+		if err != nil {
+			return fmt.Errorf("invalid expiration_time: %q, expected format 2006-01-02 15:04:05", fields[12])
+		}
+		host.ExpirationTime = t.Unix()
 	}
 
-	return &commonmodel.ImportResult{
-		SuccessCount: importedCount,
-		FailedCount:  failedCount,
-	}, nil
+	return nil
 }
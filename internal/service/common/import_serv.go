@@ -0,0 +1,549 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/importer"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	commonmodel "cyber-life/internal/model/common"
+	commonrepository "cyber-life/internal/repository/common"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 11:05
+// @Desc:	导入服务：逐条解析与校验源文件中的记录，再按导入选项在同一事务中写入；第三方密码库的记录按类别保存为账号、密钥或主机
+
+// importResources 导入类别对应的记录类型
+var importResources = map[importer.Kind]string{
+	importer.KindAccount: constant.RESOURCE_ACCOUNTS,
+	importer.KindSecret:  constant.RESOURCE_SECRETS,
+	importer.KindHost:    constant.RESOURCE_HOSTS,
+}
+
+// importRow 待导入的单条记录，明细状态为空表示校验通过、等待写入
+type importRow struct {
+	item  commonmodel.ImportItem
+	model interface{} // 解析得到的记录模型指针，未能解析时为空
+}
+
+// csvRow CSV文件的数据行
+type csvRow struct {
+	line   int // 起始行号
+	fields []string
+	err    error // 该行的格式错误
+}
+
+// readImportCSV 读取CSV文件的全部数据行（不含表头），格式错误的行保留错误原因而不中断读取
+func readImportCSV(filePath string) ([]csvRow, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	var rows []csvRow
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		row := csvRow{fields: fields, err: err}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row.line = parseErr.StartLine
+		} else if err == nil {
+			row.line, _ = reader.FieldPos(0)
+		} else {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("invalid cvs file format")
+	}
+	if rows[0].err != nil {
+		return nil, rows[0].err
+	}
+	return rows[1:], nil
+}
+
+// newCSVImportRow 生成CSV数据行的导入记录，格式错误或列数不足时直接记为失败
+func newCSVImportRow(index int, row csvRow, minFields int) (importRow, bool) {
+	result := importRow{item: commonmodel.ImportItem{Index: index + 1, Line: row.line}}
+
+	switch {
+	case row.err != nil:
+		result.item.Status = constant.IMPORT_STATUS_FAILED
+		result.item.Error = row.err.Error()
+	case len(row.fields) < minFields:
+		result.item.Status = constant.IMPORT_STATUS_FAILED
+		result.item.Error = fmt.Sprintf("too few columns: expected at least %d, got %d", minFields, len(row.fields))
+	default:
+		return result, true
+	}
+	return result, false
+}
+
+// requireFields 校验必填字段，fields 为依次排列的字段名与字段值
+func requireFields(fields ...string) error {
+	var missing []string
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i+1] == "" {
+			missing = append(missing, fields[i])
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// failImportRow 将导入记录标记为失败
+func failImportRow(row *importRow, err error) {
+	row.item.Status = constant.IMPORT_STATUS_FAILED
+	row.item.Error = err.Error()
+}
+
+// commitImportRows 按导入选项在同一事务中写入校验通过的记录并汇总导入结果：试运行时写入后回滚；
+// 全部或不导入时存在任一失败记录则不写入任何记录，否则失败的记录不影响其它记录；
+// 与导入者名下自然标识相同的已有记录（含同一文件中先导入的记录）按冲突处理方式跳过、覆盖、合并或另行创建
+func commitImportRows(ownerID uint, rows []importRow, options commonmodel.ImportOptions) (*commonmodel.ImportResult, error) {
+	if options.Conflict == "" {
+		options.Conflict = constant.IMPORT_CONFLICT_SKIP
+	}
+	result := &commonmodel.ImportResult{
+		DryRun:       options.DryRun,
+		AllOrNothing: options.AllOrNothing,
+		Conflict:     options.Conflict,
+		Total:        len(rows),
+		Items:        []commonmodel.ImportItem{},
+	}
+
+	var (
+		pending []int
+		entries []commonrepository.ImportEntry
+		failed  bool
+	)
+	for index := range rows {
+		switch rows[index].item.Status {
+		case "":
+			pending = append(pending, index)
+			entries = append(entries, newImportEntry(rows[index].model, options.Conflict))
+		case constant.IMPORT_STATUS_FAILED:
+			failed = true
+		}
+	}
+
+	if len(entries) > 0 && !(options.AllOrNothing && failed) {
+		outcomes, err := commonrepository.SaveImportedRecords(ownerID, entries, options.AllOrNothing, options.DryRun)
+		if err != nil {
+			return nil, err
+		}
+
+		// 本次导入中创建的记录，用于识别同一文件中的重复记录
+		created := make(map[string]int)
+		for position, index := range pending {
+			outcome, row := outcomes[position], &rows[index]
+
+			switch {
+			case outcome.Err != nil:
+				failImportRow(row, outcome.Err)
+				failed = true
+
+			case outcome.Existing == nil:
+				row.item.Status = constant.IMPORT_STATUS_SUCCESS
+				if options.DryRun {
+					row.item.Status = constant.IMPORT_STATUS_VALID
+				} else {
+					row.item.RecordID = importedRecordID(row.model)
+				}
+				created[importedRecordKey(row.model)] = index
+
+			default:
+				existingID := importedRecordID(outcome.Existing)
+				if earlier, ok := created[importedRecordKey(outcome.Existing)]; ok {
+					row.item.Error = fmt.Sprintf("duplicate of row %d", rows[earlier].item.Index)
+				} else {
+					row.item.Error = fmt.Sprintf("duplicate of record %d", existingID)
+					row.item.DuplicateOf = existingID
+				}
+
+				row.item.Status = constant.IMPORT_STATUS_SKIPPED
+				if outcome.Saved {
+					row.item.Status = constant.IMPORT_STATUS_UPDATED
+					row.model = outcome.Existing
+					if !options.DryRun {
+						row.item.RecordID = existingID
+					}
+				}
+			}
+		}
+	}
+
+	if options.AllOrNothing && failed {
+		result.RolledBack = !options.DryRun
+		for _, index := range pending {
+			if rows[index].item.Status != constant.IMPORT_STATUS_FAILED {
+				rows[index].item.Status = constant.IMPORT_STATUS_ROLLED_BACK
+				rows[index].item.RecordID = 0
+			}
+		}
+	}
+
+	for index := range rows {
+		item := rows[index].item
+		item.Record = maskedImportRecord(rows[index].model)
+
+		switch item.Status {
+		case constant.IMPORT_STATUS_SUCCESS, constant.IMPORT_STATUS_VALID:
+			result.SuccessCount++
+		case constant.IMPORT_STATUS_UPDATED:
+			result.UpdatedCount++
+		case constant.IMPORT_STATUS_FAILED:
+			result.FailedCount++
+		case constant.IMPORT_STATUS_SKIPPED:
+			result.SkippedCount++
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	return result, nil
+}
+
+// newImportEntry 生成待写入的导入记录，除保留两者外均按自然标识查重
+func newImportEntry(model interface{}, conflict string) commonrepository.ImportEntry {
+	entry := commonrepository.ImportEntry{Record: model}
+	if conflict == constant.IMPORT_CONFLICT_KEEP_BOTH {
+		return entry
+	}
+
+	identity, merge := importedRecordIdentity(model)
+	if identity == nil {
+		return entry
+	}
+	entry.Identity = identity
+	entry.ResourceType = importedRecordResource(model)
+	entry.Resolve = func(existing interface{}) interface{} {
+		switch conflict {
+		case constant.IMPORT_CONFLICT_OVERWRITE:
+			merge(existing, true)
+		case constant.IMPORT_CONFLICT_MERGE:
+			merge(existing, false)
+		default:
+			return nil
+		}
+		return existing
+	}
+	return entry
+}
+
+// importedRecordIdentity 返回记录的自然标识字段及将导入记录合并到已有记录的函数，不参与查重的记录返回 nil；
+// overwrite 为真时以导入记录覆盖全部字段，否则仅覆盖导入记录中的非空字段
+func importedRecordIdentity(model interface{}) (map[string]interface{}, func(existing interface{}, overwrite bool)) {
+	switch record := model.(type) {
+	case *commonmodel.Account:
+		return map[string]interface{}{"platform": record.Platform, "username": record.Username},
+			func(existing interface{}, overwrite bool) {
+				account := existing.(*commonmodel.Account)
+				mergeString(&account.Type, record.Type, overwrite)
+				mergeString(&account.PlatformURL, record.PlatformURL, overwrite)
+				mergeString(&account.Password, record.Password, overwrite)
+				mergeString(&account.SecurityEmail, record.SecurityEmail, overwrite)
+				mergeString(&account.SecurityPhone, record.SecurityPhone, overwrite)
+				mergeString(&account.Remark, record.Remark, overwrite)
+				mergeString(&account.Logo, record.Logo, overwrite)
+			}
+	case *commonmodel.Secret:
+		return map[string]interface{}{"platform": record.Platform, "key_id": record.KeyID},
+			func(existing interface{}, overwrite bool) {
+				secret := existing.(*commonmodel.Secret)
+				mergeString(&secret.PlatformURL, record.PlatformURL, overwrite)
+				mergeString(&secret.KeySecret, record.KeySecret, overwrite)
+				mergeString(&secret.Remark, record.Remark, overwrite)
+				mergeString(&secret.Logo, record.Logo, overwrite)
+			}
+	case *commonmodel.Host:
+		return map[string]interface{}{"address": record.Address, "username": record.Username},
+			func(existing interface{}, overwrite bool) {
+				host := existing.(*commonmodel.Host)
+				mergeString(&host.Provider, record.Provider, overwrite)
+				mergeString(&host.ProviderURL, record.ProviderURL, overwrite)
+				mergeString(&host.Hostname, record.Hostname, overwrite)
+				mergeString(&host.Password, record.Password, overwrite)
+				mergeString(&host.OS, record.OS, overwrite)
+				mergeString(&host.Logo, record.Logo, overwrite)
+				if overwrite || len(record.Ports) > 0 {
+					host.Ports = record.Ports
+				}
+				mergeInt(&host.CpuNum, record.CpuNum, overwrite)
+				mergeInt(&host.RamSize, record.RamSize, overwrite)
+				mergeInt(&host.DiskSize, record.DiskSize, overwrite)
+				if overwrite || record.ExpirationTime != 0 {
+					host.ExpirationTime = record.ExpirationTime
+				}
+			}
+	}
+	return nil, nil
+}
+
+// mergeString 合并字符串字段，overwrite 为假时忽略空值
+func mergeString(field *string, value string, overwrite bool) {
+	if overwrite || value != "" {
+		*field = value
+	}
+}
+
+// mergeInt 合并整数字段，overwrite 为假时忽略零值
+func mergeInt(field *int, value int, overwrite bool) {
+	if overwrite || value != 0 {
+		*field = value
+	}
+}
+
+// importedRecordKey 记录的类型与ID组成的唯一键
+func importedRecordKey(model interface{}) string {
+	return fmt.Sprintf("%T:%d", model, importedRecordID(model))
+}
+
+// importedRecordID 读取已写入记录的ID
+func importedRecordID(model interface{}) uint {
+	switch record := model.(type) {
+	case *commonmodel.Account:
+		return record.ID
+	case *commonmodel.Secret:
+		return record.ID
+	case *commonmodel.Host:
+		return record.ID
+	case *commonmodel.Site:
+		return record.ID
+	}
+	return 0
+}
+
+// importedRecordResource 记录模型对应的记录类型
+func importedRecordResource(model interface{}) string {
+	switch model.(type) {
+	case *commonmodel.Account:
+		return constant.RESOURCE_ACCOUNTS
+	case *commonmodel.Secret:
+		return constant.RESOURCE_SECRETS
+	case *commonmodel.Host:
+		return constant.RESOURCE_HOSTS
+	case *commonmodel.Site:
+		return constant.RESOURCE_SITES
+	}
+	return ""
+}
+
+// maskedImportRecord 复制解析得到的记录，敏感字段替换为掩码
+func maskedImportRecord(model interface{}) interface{} {
+	switch record := model.(type) {
+	case *commonmodel.Account:
+		accounts := []commonmodel.Account{*record}
+		maskAccounts(accounts)
+		return accounts[0]
+	case *commonmodel.Secret:
+		secrets := []commonmodel.Secret{*record}
+		maskSecrets(secrets)
+		return secrets[0]
+	case *commonmodel.Host:
+		hosts := []commonmodel.Host{*record}
+		maskHosts(hosts)
+		return hosts[0]
+	case *commonmodel.Site:
+		return *record
+	}
+	return nil
+}
+
+// ImportRecords 解析第三方密码库的导出文件并导入其中的记录，导入的记录归属于导入者；
+// format 为空时根据文件名与内容自动识别格式，allowed 为导入者有权导入的类别，其余类别的记录记为失败
+func ImportRecords(ownerID uint, format, filename string, data []byte, parseOptions importer.Options, allowed map[importer.Kind]bool, options commonmodel.ImportOptions) (*commonmodel.ImportResult, error) {
+	parser, err := importer.Resolve(format, filename, data)
+	if err != nil {
+		return nil, err
+	}
+	records, err := parser.Parse(data, parseOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := commitImportRows(ownerID, buildImportedRows(ownerID, records, allowed), options)
+	if err != nil {
+		return nil, err
+	}
+	result.Format = parser.Name()
+	return result, nil
+}
+
+// ImportAccountsKDBX 使用主口令与可选的密钥文件解密KDBX数据库并将其中的条目全部导入为账号记录，
+// 导入的记录归属于导入者；回收站中的条目与条目的历史版本不导入
+func ImportAccountsKDBX(ownerID uint, data []byte, password string, keyFile []byte, options commonmodel.ImportOptions) (*commonmodel.ImportResult, error) {
+	parser, err := importer.Resolve("keepass", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	records, err := parser.Parse(data, importer.Options{Password: password, KeyFile: keyFile})
+	if err != nil {
+		return nil, err
+	}
+	for index := range records {
+		records[index].Hint = importer.KindAccount
+	}
+
+	result, err := commitImportRows(ownerID, buildImportedRows(ownerID, records, map[importer.Kind]bool{importer.KindAccount: true}), options)
+	if err != nil {
+		return nil, err
+	}
+	result.Format = parser.Name()
+	return result, nil
+}
+
+// buildImportedRows 将解析后的记录按类别转换为待导入的记录
+func buildImportedRows(ownerID uint, records []importer.Record, allowed map[importer.Kind]bool) []importRow {
+	rows := make([]importRow, 0, len(records))
+
+	for index := range records {
+		record := &records[index]
+		row := importRow{item: commonmodel.ImportItem{
+			Index: index + 1,
+			Line:  record.Line,
+			Title: record.Title,
+			Group: record.Group,
+		}}
+
+		kind := importer.Classify(record)
+		row.item.Resource = importResources[kind]
+
+		var err error
+		switch {
+		case record.Skip != "":
+			row.item.Status = constant.IMPORT_STATUS_SKIPPED
+			row.item.Error = record.Skip
+		case kind == "":
+			row.item.Status = constant.IMPORT_STATUS_SKIPPED
+			row.item.Error = "unsupported item type"
+		case !allowed[kind]:
+			err = errs.ErrPermissionDenied
+		case kind == importer.KindAccount:
+			row.model, err = buildImportedAccount(ownerID, record)
+		case kind == importer.KindSecret:
+			row.model, err = buildImportedSecret(ownerID, record)
+		case kind == importer.KindHost:
+			row.model, err = buildImportedHost(ownerID, record)
+		}
+		if err != nil {
+			failImportRow(&row, err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// importedTitle 记录标题，无标题时使用URL中的主机名
+func importedTitle(record *importer.Record) string {
+	title := strings.TrimSpace(record.Title)
+	if title == "" {
+		if parsed, err := url.Parse(record.URL); err == nil {
+			title = parsed.Hostname()
+		}
+	}
+	return title
+}
+
+// buildImportedAccount 将记录转换为账号：分组→类型，标题→平台，URL→平台链接，用户名→账号，备注→备注
+func buildImportedAccount(ownerID uint, record *importer.Record) (*commonmodel.Account, error) {
+	account := &commonmodel.Account{
+		OwnerID:     ownerID,
+		Type:        record.Group,
+		Platform:    importedTitle(record),
+		PlatformURL: record.URL,
+		Username:    record.Username,
+		Password:    record.Password,
+		Remark:      record.Notes,
+	}
+
+	if account.Platform == "" {
+		return account, errors.New("missing title")
+	}
+	if account.Password == "" {
+		return account, errors.New("missing password")
+	}
+	return account, nil
+}
+
+// buildImportedSecret 将记录转换为密钥：标题→平台，URL→平台链接，密钥标识与密钥取自对应字段或用户名与密码
+func buildImportedSecret(ownerID uint, record *importer.Record) (*commonmodel.Secret, error) {
+	keyID, keySecret := importer.KeyPair(record)
+	secret := &commonmodel.Secret{
+		OwnerID:     ownerID,
+		Platform:    importedTitle(record),
+		PlatformURL: record.URL,
+		KeyID:       keyID,
+		KeySecret:   keySecret,
+		Remark:      record.Notes,
+	}
+
+	if secret.Platform == "" {
+		return secret, errors.New("missing title")
+	}
+	if secret.KeySecret == "" {
+		return secret, errors.New("missing key secret")
+	}
+	return secret, nil
+}
+
+// buildImportedHost 将记录转换为主机：分组→提供商，标题→主机名，地址与端口取自主机协议的URL或对应字段
+func buildImportedHost(ownerID uint, record *importer.Record) (*commonmodel.Host, error) {
+	address, port, service := importer.HostEndpoint(record)
+
+	hostname := strings.TrimSpace(record.Title)
+	if hostname == "" {
+		hostname = address
+	}
+	ports := make(map[string]string)
+	if port != "" {
+		ports[port] = service
+	}
+
+	// 仅网页链接作为提供商链接
+	providerURL := ""
+	if parsed, err := url.Parse(record.URL); err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+		providerURL = record.URL
+	}
+
+	host := &commonmodel.Host{
+		OwnerID:     ownerID,
+		Provider:    record.Group,
+		ProviderURL: providerURL,
+		Hostname:    hostname,
+		Address:     address,
+		Ports:       ports,
+		Username:    record.Username,
+		Password:    record.Password,
+	}
+
+	if host.Address == "" {
+		return host, errors.New("missing host address")
+	}
+	if host.Password == "" {
+		return host, errors.New("missing password")
+	}
+	return host, nil
+}
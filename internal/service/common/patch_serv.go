@@ -0,0 +1,21 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"encoding/json"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 21:30
+// @Desc:	记录补丁更新的公共逻辑
+
+// dropMaskedValues 移除补丁中回传掩码值的敏感字段，这些字段视为未修改
+func dropMaskedValues(document map[string]json.RawMessage, fields ...string) {
+	for _, field := range fields {
+		var value string
+		if json.Unmarshal(document[field], &value) == nil && value == constant.MASKED_VALUE {
+			delete(document, field)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+
+	commonmodel "cyber-life/internal/model/common"
+	commonrepository "cyber-life/internal/repository/common"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 20:40
+// @Desc:	记录修订历史与回滚服务
+
+// findRecordHistory 查询记录的修订历史（仅限用户拥有或被共享的记录），每条修订包含被修改字段修改前后的值，敏感字段以掩码返回
+func findRecordHistory(resourceType string, userID, recordID uint, page, size int) ([]commonmodel.Revision, int64, error) {
+	err := commonrepository.CheckRecordVisible(resourceType, userID, recordID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	revisions, total, err := commonrepository.FindRevisions(resourceType, recordID, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range revisions {
+		maskRevisionChanges(revisions[i].Changes)
+	}
+	return revisions, total, nil
+}
+
+// rollbackFields 计算将记录回滚到指定修订之前的状态所需更新的字段（仅限所有者或被以读写方式共享的用户）：
+// 由新到旧依次取该修订及其后各修订修改前的值，同一字段以最早的修订为准
+func rollbackFields(resourceType string, userID, recordID, revisionID uint) (map[string]interface{}, error) {
+	err := commonrepository.CheckRecordWritable(resourceType, userID, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := commonrepository.FindRevisionsSince(resourceType, recordID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	for _, revision := range revisions {
+		for _, change := range revision.Changes {
+			fields[change.Field] = change.Old
+		}
+	}
+
+	return fields, nil
+}
+
+// maskRevisionChanges 将修订中敏感字段的非空值替换为掩码
+func maskRevisionChanges(changes []commonmodel.RevisionChange) {
+	for i := range changes {
+		if !changes[i].Sensitive {
+			continue
+		}
+		if value, ok := changes[i].Old.(string); ok && value != "" {
+			changes[i].Old = constant.MASKED_VALUE
+		}
+		if value, ok := changes[i].New.(string); ok && value != "" {
+			changes[i].New = constant.MASKED_VALUE
+		}
+	}
+}
@@ -1,12 +1,11 @@
 package common
 
 import (
-	"encoding/csv"
-	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
-	"time"
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/exporter"
+	"cyber-life/pkg/patch"
+	"encoding/json"
 
 	commonmodel "cyber-life/internal/model/common"
 	commonrepository "cyber-life/internal/repository/common"
@@ -17,9 +16,10 @@ import (
 // @Date:   2025/10/30
 // @Desc:	密钥记录服务
 
-// CreateSecret 创建密钥记录
-func CreateSecret(platform, platformURL, keyID, keySecret, remark, logo string) error {
+// CreateSecret 创建密钥记录，记录归属于创建者
+func CreateSecret(ownerID uint, platform, platformURL, keyID, keySecret, remark, logo string) error {
 	secret := &commonmodel.Secret{
+		OwnerID:     ownerID,
 		Platform:    platform,
 		PlatformURL: platformURL,
 		KeyID:       keyID,
@@ -31,19 +31,63 @@ func CreateSecret(platform, platformURL, keyID, keySecret, remark, logo string)
 	return commonrepository.CreateSecret(secret)
 }
 
-// DeleteSecret 删除密钥记录
-func DeleteSecret(secretID uint, hardDelete bool) error {
+// DeleteSecret 删除密钥记录（仅限记录所有者）：软删除将记录移入回收站，硬删除仅限回收站中的记录
+func DeleteSecret(userID, secretID uint, hardDelete bool) error {
+	var err error
+	if hardDelete {
+		err = commonrepository.CheckRecordTrashed(constant.RESOURCE_SECRETS, userID, secretID)
+	} else {
+		err = commonrepository.CheckRecordOwner(constant.RESOURCE_SECRETS, userID, secretID)
+	}
+	if err != nil {
+		return err
+	}
+
 	secret := &commonmodel.Secret{}
 	secret.ID = secretID
 
 	if hardDelete {
+		err = commonrepository.HardDeleteSharesByResource(constant.RESOURCE_SECRETS, secretID)
+		if err != nil {
+			return err
+		}
+		err = commonrepository.HardDeleteRevisionsByResource(constant.RESOURCE_SECRETS, secretID)
+		if err != nil {
+			return err
+		}
 		return commonrepository.HardDeleteSecret(secret)
 	}
 	return commonrepository.SoftDeleteSecret(secret)
 }
 
-// UpdateSecret 更新密钥记录
-func UpdateSecret(secretID uint, platform, platformURL, keyID, keySecret, remark, logo string) error {
+// RestoreSecret 将回收站中的密钥记录恢复（仅限记录所有者）
+func RestoreSecret(userID, secretID uint) error {
+	err := commonrepository.CheckRecordTrashed(constant.RESOURCE_SECRETS, userID, secretID)
+	if err != nil {
+		return err
+	}
+
+	return commonrepository.RestoreRecord(constant.RESOURCE_SECRETS, secretID)
+}
+
+// FindTrashedSecrets 查询回收站中的密钥记录（仅限记录所有者），敏感字段以掩码返回
+func FindTrashedSecrets(userID uint, page, size int) ([]commonmodel.Secret, int64, error) {
+	secrets, total, err := commonrepository.FindTrashedSecrets(userID, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	maskSecrets(secrets)
+	return secrets, total, nil
+}
+
+// UpdateSecret 更新密钥记录（仅限所有者或被以读写方式共享的用户）
+func UpdateSecret(userID, secretID uint, platform, platformURL, keyID, keySecret, remark, logo string) error {
+	err := commonrepository.CheckRecordWritable(constant.RESOURCE_SECRETS, userID, secretID)
+	if err != nil {
+		return err
+	}
+
 	secret := &commonmodel.Secret{
 		Platform:    platform,
 		PlatformURL: platformURL,
@@ -54,152 +98,199 @@ func UpdateSecret(secretID uint, platform, platformURL, keyID, keySecret, remark
 	}
 	secret.ID = secretID
 
-	return commonrepository.UpdateSecret(secret)
+	return commonrepository.UpdateSecret(userID, secret)
 }
 
-// UpdateSecretFields 更新密钥记录（只更新指定字段）
-func UpdateSecretFields(secretID uint, fields map[string]interface{}) error {
-	return commonrepository.UpdateSecretFields(secretID, fields)
-}
+// secretPatchFields 可经补丁修改的密钥记录字段
+var secretPatchFields = []string{"platform", "platform_url", "key_id", "key_secret", "remark", "logo"}
+
+// PatchSecret 按JSON Merge Patch语义更新密钥记录（仅可修改 secretPatchFields 中的字段，回传的掩码值视为未修改；仅限所有者或被以读写方式共享的用户），
+// 字段未知、只读、类型不符或不满足校验规则时返回 patch.Errors
+func PatchSecret(userID, secretID uint, document map[string]json.RawMessage) error {
+	err := commonrepository.CheckRecordWritable(constant.RESOURCE_SECRETS, userID, secretID)
+	if err != nil {
+		return err
+	}
+
+	secret, err := commonrepository.FindSecretByID(userID, secretID)
+	if err != nil {
+		return err
+	}
 
-// FindSecretsList 获取密钥记录列表
-func FindSecretsList(page, size int) ([]commonmodel.Secret, int64, error) {
-	return commonrepository.FindSecretsList(page, size)
+	dropMaskedValues(document, "key_secret")
+	fields, err := patch.Apply(secret, document, secretPatchFields)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return commonrepository.UpdateSecretFields(userID, secretID, constant.REVISION_ACTION_UPDATE, fields)
 }
 
-// FindSecrets 搜索密钥记录
-func FindSecrets(keyword string, page, size int) ([]commonmodel.Secret, int64, error) {
-	return commonrepository.FindSecrets(keyword, page, size)
+// FindSecretHistory 查询密钥记录的修订历史（仅限用户拥有或被共享的记录），按修订时间倒序排列，敏感字段以掩码返回
+func FindSecretHistory(userID, secretID uint, page, size int) ([]commonmodel.Revision, int64, error) {
+	return findRecordHistory(constant.RESOURCE_SECRETS, userID, secretID, page, size)
 }
 
-// ExportSecretsCSV 导出密钥记录为CSV文件
-func ExportSecretsCSV() (string, error) {
-	tempDir := "temp"
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return "", err
+// RollbackSecret 将密钥记录回滚到指定修订之前的状态，回滚本身作为一条新的修订保存（仅限所有者或被以读写方式共享的用户）
+func RollbackSecret(userID, secretID, revisionID uint) error {
+	fields, err := rollbackFields(constant.RESOURCE_SECRETS, userID, secretID, revisionID)
+	if err != nil {
+		return err
 	}
 
-	filename := fmt.Sprintf("secrets_%s.csv", time.Now().Format("20060102_150405"))
-	filePath := filepath.Join(tempDir, filename)
-	file, err := os.Create(filePath)
+	return commonrepository.UpdateSecretFields(userID, secretID, constant.REVISION_ACTION_ROLLBACK, fields)
+}
+
+// FindSecretsList 获取密钥记录列表（仅限用户拥有或被共享的记录，敏感字段以掩码返回）
+func FindSecretsList(userID uint, page, size int) ([]commonmodel.Secret, int64, error) {
+	secrets, total, err := commonrepository.FindSecretsList(userID, page, size)
 	if err != nil {
-		return "", err
+		return nil, 0, err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	maskSecrets(secrets)
+	return secrets, total, nil
+}
 
-	headers := []string{"ID", "平台", "平台链接", "密钥ID", "密钥Secret", "备注", "Logo", "创建时间", "更新时间"}
-	err = writer.Write(headers)
+// FindSecrets 搜索密钥记录（仅限用户拥有或被共享的记录，敏感字段以掩码返回）
+func FindSecrets(userID uint, keyword string, page, size int) ([]commonmodel.Secret, int64, error) {
+	secrets, total, err := commonrepository.FindSecrets(userID, keyword, page, size)
 	if err != nil {
-		return "", err
+		return nil, 0, err
 	}
 
-	secrets, _, err := commonrepository.FindSecretsList(1, 999999)
+	maskSecrets(secrets)
+	return secrets, total, nil
+}
+
+// RevealSecretField 获取单条密钥记录指定敏感字段的明文（仅限用户拥有或被共享的记录）
+func RevealSecretField(userID, secretID uint, field string) (string, error) {
+	secret, err := commonrepository.FindSecretByID(userID, secretID)
 	if err != nil {
 		return "", err
 	}
 
-	for _, secret := range secrets {
-		record := []string{
-			strconv.FormatUint(uint64(secret.ID), 10),
-			secret.Platform,
-			secret.PlatformURL,
-			secret.KeyID,
-			secret.KeySecret,
-			secret.Remark,
-			secret.Logo,
-			secret.CreatedAt.Format("2006-01-02 15:04:05"),
-			secret.UpdatedAt.Format("2006-01-02 15:04:05"),
-		}
-		err = writer.Write(record)
-		if err != nil {
-			return "", err
+	switch field {
+	case "key_secret":
+		return secret.KeySecret, nil
+	default:
+		return "", errs.ErrInvalidRevealField
+	}
+}
+
+// maskSecrets 将密钥记录中的敏感字段替换为掩码
+func maskSecrets(secrets []commonmodel.Secret) {
+	for i := range secrets {
+		if secrets[i].KeySecret != "" {
+			secrets[i].KeySecret = constant.MASKED_VALUE
 		}
 	}
+}
 
-	return filePath, nil
+// secretExportColumns 密钥记录的导出列，CSV文件的列顺序与导入格式一致
+var secretExportColumns = []exporter.Column{
+	{Key: "id", Title: "ID"},
+	{Key: "platform", Title: "平台"},
+	{Key: "platform_url", Title: "平台链接"},
+	{Key: "key_id", Title: "密钥ID"},
+	{Key: "key_secret", Title: "密钥Secret"},
+	{Key: "remark", Title: "备注"},
+	{Key: "logo", Title: "Logo"},
+	{Key: "created_at", Title: "创建时间"},
+	{Key: "updated_at", Title: "更新时间"},
 }
 
-// ImportSecretsCSV 从CSV文件导入密钥记录
-func ImportSecretsCSV(filePath string) (*commonmodel.ImportResult, error) {
-	file, err := os.Open(filePath)
+// ExportSecrets 按写入器的格式流式导出密钥记录（仅包含用户可见且匹配关键字的记录），按ID游标分批查询，返回导出的记录数
+func ExportSecrets(userID uint, keyword string, writer exporter.Writer) (int, error) {
+	err := writer.WriteHeader(secretExportColumns)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
+	count := 0
+	var lastID uint
+	for {
+		secrets, err := commonrepository.FindSecretsAfter(userID, keyword, lastID, exportBatchSize)
+		if err != nil {
+			return count, err
+		}
+
+		for _, secret := range secrets {
+			err = writer.WriteRow([]interface{}{
+				secret.ID,
+				secret.Platform,
+				secret.PlatformURL,
+				secret.KeyID,
+				secret.KeySecret,
+				secret.Remark,
+				secret.Logo,
+				formatExportTime(secret.CreatedAt),
+				formatExportTime(secret.UpdatedAt),
+			})
+			if err != nil {
+				return count, err
+			}
+		}
+
+		count += len(secrets)
+		if len(secrets) < exportBatchSize {
+			break
+		}
+		lastID = secrets[len(secrets)-1].ID
 	}
 
-	if len(records) < 2 {
-		return nil, fmt.Errorf("invalid cvs file format")
+	return count, writer.Close()
+}
+
+// ImportSecretsCSV 从CSV文件导入密钥记录，导入的记录归属于导入者；逐行返回行号、失败原因与解析结果
+func ImportSecretsCSV(ownerID uint, filePath string, options commonmodel.ImportOptions) (*commonmodel.ImportResult, error) {
+	records, err := readImportCSV(filePath)
+	if err != nil {
+		return nil, err
 	}
 
-	importedCount := 0
-	failedCount := 0
-	for _, record := range records[1:] {
+	rows := make([]importRow, 0, len(records))
+	for index, record := range records {
 		// CSV格式: 平台,平台链接,密钥ID,密钥Secret,备注,Logo（ID和时间字段会被忽略）
-		if len(record) < 4 {
-			failedCount++
+		row, ok := newCSVImportRow(index, record, 4)
+		if !ok {
+			rows = append(rows, row)
 			continue
 		}
 
-		platform := ""
-		platformURL := ""
-		keyID := ""
-		keySecret := ""
-		remark := ""
-		logo := ""
-
-		if len(record) >= 9 {
+		fields := record.fields
+		if len(fields) >= 9 {
 			// 完整格式：ID, 平台, 平台链接, 密钥ID, 密钥Secret, 备注, Logo, 创建时间, 更新时间
-			platform = record[1]
-			platformURL = record[2]
-			keyID = record[3]
-			keySecret = record[4]
-			if len(record) > 5 {
-				remark = record[5]
-			}
-			if len(record) > 6 {
-				logo = record[6]
-			}
-		} else {
-			// 简化格式：平台, 平台链接, 密钥ID, 密钥Secret, 备注, Logo
-			platform = record[0]
-			platformURL = record[1]
-			keyID = record[2]
-			keySecret = record[3]
-			if len(record) > 4 {
-				remark = record[4]
-			}
-			if len(record) > 5 {
-				logo = record[5]
-			}
+			fields = fields[1:]
 		}
+		// 简化格式：平台, 平台链接, 密钥ID, 密钥Secret, 备注, Logo
+		fields = append(fields, make([]string, 6)...)
 
-		// 验证必填字段
-		if platform == "" || platformURL == "" || keyID == "" || keySecret == "" {
-			failedCount++
-			continue
+		secret := &commonmodel.Secret{
+			OwnerID:     ownerID,
+			Platform:    fields[0],
+			PlatformURL: fields[1],
+			KeyID:       fields[2],
+			KeySecret:   fields[3],
+			Remark:      fields[4],
+			Logo:        fields[5],
 		}
+		row.model = secret
+		row.item.Title = secret.Platform
+		row.item.Resource = constant.RESOURCE_SECRETS
 
-		// 创建密钥记录
-		err = CreateSecret(platform, platformURL, keyID, keySecret, remark, logo)
+		// 验证必填字段
+		err = requireFields("platform", secret.Platform, "platform_url", secret.PlatformURL,
+			"key_id", secret.KeyID, "key_secret", secret.KeySecret)
 		if err != nil {
-			failedCount++
-			continue
+			failImportRow(&row, err)
 		}
 
-		importedCount++
+		rows = append(rows, row)
 	}
 
-	return &commonmodel.ImportResult{
-		SuccessCount: importedCount,
-		FailedCount:  failedCount,
-	}, nil
+	return commitImportRows(ownerID, rows, options)
 }
@@ -0,0 +1,123 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"errors"
+
+	commonmodel "cyber-life/internal/model/common"
+	commonrepository "cyber-life/internal/repository/common"
+	systemrepository "cyber-life/internal/repository/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 19:40
+// @Desc:	记录共享服务实现
+
+// CreateShare 将记录共享给用户或用户组（仅限记录所有者），已存在的授权会更新授权级别
+func CreateShare(ownerID uint, resourceType string, resourceID uint, granteeType string, granteeID uint, access string) (*commonmodel.Share, error) {
+	if !constant.ShareResources[resourceType] {
+		return nil, errs.ErrInvalidShareParams
+	}
+	if access != constant.SHARE_ACCESS_READ && access != constant.SHARE_ACCESS_READ_WRITE {
+		return nil, errs.ErrInvalidShareParams
+	}
+
+	switch granteeType {
+	case constant.GRANTEE_USER:
+		if granteeID == ownerID {
+			return nil, errs.ErrInvalidShareParams
+		}
+		_, err := systemrepository.FindUserByID(granteeID)
+		if err != nil {
+			return nil, err
+		}
+	case constant.GRANTEE_GROUP:
+		_, err := systemrepository.FindGroupByID(granteeID)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errs.ErrInvalidShareParams
+	}
+
+	err := commonrepository.CheckRecordOwner(resourceType, ownerID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	share, err := commonrepository.FindShareByGrantee(resourceType, resourceID, granteeType, granteeID)
+	if err != nil && !errors.Is(err, errs.ErrRecordNotFound) {
+		return nil, err
+	}
+	if share != nil {
+		share.Access = access
+		err = commonrepository.UpdateShareFields(share.ID, map[string]interface{}{
+			"access": access,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return share, nil
+	}
+
+	share = &commonmodel.Share{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		GranteeType:  granteeType,
+		GranteeID:    granteeID,
+		Access:       access,
+		GrantedBy:    ownerID,
+	}
+	err = commonrepository.CreateShare(share)
+	if err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// DeleteShare 撤销共享授权（仅限记录所有者）
+func DeleteShare(ownerID, shareID uint) error {
+	share, err := commonrepository.FindShareByID(shareID)
+	if err != nil {
+		return err
+	}
+
+	err = commonrepository.CheckRecordOwner(share.ResourceType, ownerID, share.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	return commonrepository.HardDeleteShare(share)
+}
+
+// FindRecordShares 查询记录的全部共享授权（仅限记录所有者）
+func FindRecordShares(ownerID uint, resourceType string, resourceID uint) ([]commonmodel.Share, error) {
+	if !constant.ShareResources[resourceType] {
+		return nil, errs.ErrInvalidShareParams
+	}
+
+	err := commonrepository.CheckRecordOwner(resourceType, ownerID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return commonrepository.FindSharesByResource(resourceType, resourceID)
+}
+
+// ClaimUnownedRecords 将没有所有者的历史记录归属给指定用户，返回处理的记录数
+func ClaimUnownedRecords(ownerID uint) (int64, error) {
+	var total int64
+
+	for resourceType := range constant.ShareResources {
+		count, err := commonrepository.ClaimUnownedRecords(resourceType, ownerID)
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+
+	return total, nil
+}
@@ -1,12 +1,10 @@
 package common
 
 import (
-	"encoding/csv"
-	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
-	"time"
+	"cyber-life/internal/constant"
+	"cyber-life/pkg/exporter"
+	"cyber-life/pkg/patch"
+	"encoding/json"
 
 	commonmodel "cyber-life/internal/model/common"
 	commonrepository "cyber-life/internal/repository/common"
@@ -17,30 +15,65 @@ import (
 // @Date:   2025/10/31
 // @Desc:	站点记录服务
 
-// CreateSite 创建站点记录
-func CreateSite(name, logo, url string) error {
+// CreateSite 创建站点记录，记录归属于创建者
+func CreateSite(ownerID uint, name, logo, url string) error {
 	site := &commonmodel.Site{
-		Name: name,
-		Logo: logo,
-		URL:  url,
+		OwnerID: ownerID,
+		Name:    name,
+		Logo:    logo,
+		URL:     url,
 	}
 
 	return commonrepository.CreateSite(site)
 }
 
-// DeleteSite 删除站点记录
-func DeleteSite(siteID uint, hardDelete bool) error {
+// DeleteSite 删除站点记录（仅限记录所有者）：软删除将记录移入回收站，硬删除仅限回收站中的记录
+func DeleteSite(userID, siteID uint, hardDelete bool) error {
+	var err error
+	if hardDelete {
+		err = commonrepository.CheckRecordTrashed(constant.RESOURCE_SITES, userID, siteID)
+	} else {
+		err = commonrepository.CheckRecordOwner(constant.RESOURCE_SITES, userID, siteID)
+	}
+	if err != nil {
+		return err
+	}
+
 	site := &commonmodel.Site{}
 	site.ID = siteID
 
 	if hardDelete {
+		err = commonrepository.HardDeleteSharesByResource(constant.RESOURCE_SITES, siteID)
+		if err != nil {
+			return err
+		}
 		return commonrepository.HardDeleteSite(site)
 	}
 	return commonrepository.SoftDeleteSite(site)
 }
 
-// UpdateSite 更新站点记录
-func UpdateSite(siteID uint, name, logo, url string) error {
+// RestoreSite 将回收站中的站点记录恢复（仅限记录所有者）
+func RestoreSite(userID, siteID uint) error {
+	err := commonrepository.CheckRecordTrashed(constant.RESOURCE_SITES, userID, siteID)
+	if err != nil {
+		return err
+	}
+
+	return commonrepository.RestoreRecord(constant.RESOURCE_SITES, siteID)
+}
+
+// FindTrashedSites 查询回收站中的站点记录（仅限记录所有者）
+func FindTrashedSites(userID uint, page, size int) ([]commonmodel.Site, int64, error) {
+	return commonrepository.FindTrashedSites(userID, page, size)
+}
+
+// UpdateSite 更新站点记录（仅限所有者或被以读写方式共享的用户）
+func UpdateSite(userID, siteID uint, name, logo, url string) error {
+	err := commonrepository.CheckRecordWritable(constant.RESOURCE_SITES, userID, siteID)
+	if err != nil {
+		return err
+	}
+
 	site := &commonmodel.Site{
 		Name: name,
 		Logo: logo,
@@ -51,133 +84,134 @@ func UpdateSite(siteID uint, name, logo, url string) error {
 	return commonrepository.UpdateSite(site)
 }
 
-// UpdateSiteFields 更新站点记录（只更新指定字段）
-func UpdateSiteFields(siteID uint, fields map[string]interface{}) error {
-	return commonrepository.UpdateSiteFields(siteID, fields)
-}
-
-// FindSitesList 获取站点记录列表
-func FindSitesList(page, size int) ([]commonmodel.Site, int64, error) {
-	return commonrepository.FindSitesList(page, size)
-}
-
-// FindSites 搜索站点记录
-func FindSites(keyword string, page, size int) ([]commonmodel.Site, int64, error) {
-	return commonrepository.FindSites(keyword, page, size)
-}
+// sitePatchFields 可经补丁修改的站点记录字段
+var sitePatchFields = []string{"name", "logo", "url"}
 
-// ExportSitesCSV 导出站点记录为CSV文件
-func ExportSitesCSV() (string, error) {
-	tempDir := "temp"
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return "", err
+// PatchSite 按JSON Merge Patch语义更新站点记录（仅可修改 sitePatchFields 中的字段；仅限所有者或被以读写方式共享的用户），
+// 字段未知、只读、类型不符或不满足校验规则时返回 patch.Errors
+func PatchSite(userID, siteID uint, document map[string]json.RawMessage) error {
+	err := commonrepository.CheckRecordWritable(constant.RESOURCE_SITES, userID, siteID)
+	if err != nil {
+		return err
 	}
 
-	filename := fmt.Sprintf("sites_%s.csv", time.Now().Format("20060102_150405"))
-	filePath := filepath.Join(tempDir, filename)
-	file, err := os.Create(filePath)
+	site, err := commonrepository.FindSiteByID(userID, siteID)
 	if err != nil {
-		return "", err
+		return err
 	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
 
-	headers := []string{"ID", "站点名称", "Logo", "站点链接", "创建时间", "更新时间"}
-	err = writer.Write(headers)
+	fields, err := patch.Apply(site, document, sitePatchFields)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
 	}
 
-	sites, _, err := commonrepository.FindSitesList(1, 999999)
+	return commonrepository.UpdateSiteFields(siteID, fields)
+}
+
+// FindSitesList 获取站点记录列表（仅限用户拥有或被共享的记录）
+func FindSitesList(userID uint, page, size int) ([]commonmodel.Site, int64, error) {
+	return commonrepository.FindSitesList(userID, page, size)
+}
+
+// FindSites 搜索站点记录（仅限用户拥有或被共享的记录）
+func FindSites(userID uint, keyword string, page, size int) ([]commonmodel.Site, int64, error) {
+	return commonrepository.FindSites(userID, keyword, page, size)
+}
+
+// siteExportColumns 站点记录的导出列，CSV文件的列顺序与导入格式一致
+var siteExportColumns = []exporter.Column{
+	{Key: "id", Title: "ID"},
+	{Key: "name", Title: "站点名称"},
+	{Key: "logo", Title: "Logo"},
+	{Key: "url", Title: "站点链接"},
+	{Key: "created_at", Title: "创建时间"},
+	{Key: "updated_at", Title: "更新时间"},
+}
+
+// ExportSites 按写入器的格式流式导出站点记录（仅包含用户可见且匹配关键字的记录），按ID游标分批查询，返回导出的记录数
+func ExportSites(userID uint, keyword string, writer exporter.Writer) (int, error) {
+	err := writer.WriteHeader(siteExportColumns)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	for _, site := range sites {
-		record := []string{
-			strconv.FormatUint(uint64(site.ID), 10),
-			site.Name,
-			site.Logo,
-			site.URL,
-			site.CreatedAt.Format("2006-01-02 15:04:05"),
-			site.UpdatedAt.Format("2006-01-02 15:04:05"),
-		}
-		err = writer.Write(record)
+	count := 0
+	var lastID uint
+	for {
+		sites, err := commonrepository.FindSitesAfter(userID, keyword, lastID, exportBatchSize)
 		if err != nil {
-			return "", err
+			return count, err
 		}
-	}
 
-	return filePath, nil
-}
+		for _, site := range sites {
+			err = writer.WriteRow([]interface{}{
+				site.ID,
+				site.Name,
+				site.Logo,
+				site.URL,
+				formatExportTime(site.CreatedAt),
+				formatExportTime(site.UpdatedAt),
+			})
+			if err != nil {
+				return count, err
+			}
+		}
 
-// ImportSitesCSV 从CSV文件导入站点记录
-func ImportSitesCSV(filePath string) (*commonmodel.ImportResult, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
+		count += len(sites)
+		if len(sites) < exportBatchSize {
+			break
+		}
+		lastID = sites[len(sites)-1].ID
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	return count, writer.Close()
+}
+
+// ImportSitesCSV 从CSV文件导入站点记录，导入的记录归属于导入者；逐行返回行号、失败原因与解析结果
+func ImportSitesCSV(ownerID uint, filePath string, options commonmodel.ImportOptions) (*commonmodel.ImportResult, error) {
+	records, err := readImportCSV(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(records) < 2 {
-		return nil, fmt.Errorf("invalid cvs file format")
-	}
-
-	importedCount := 0
-	failedCount := 0
-	for _, record := range records[1:] {
+	rows := make([]importRow, 0, len(records))
+	for index, record := range records {
 		// CSV格式: 站点名称,Logo,站点链接（ID和时间字段会被忽略）
-		if len(record) < 2 {
-			failedCount++
+		row, ok := newCSVImportRow(index, record, 2)
+		if !ok {
+			rows = append(rows, row)
 			continue
 		}
 
-		name := ""
-		logo := ""
-		url := ""
-
-		if len(record) >= 6 {
+		fields := record.fields
+		if len(fields) >= 6 {
 			// 完整格式：ID, 站点名称, Logo, 站点链接, 创建时间, 更新时间
-			name = record[1]
-			logo = record[2]
-			url = record[3]
-		} else {
-			// 简化格式：站点名称, Logo, 站点链接
-			name = record[0]
-			if len(record) > 1 {
-				logo = record[1]
-			}
-			if len(record) > 2 {
-				url = record[2]
-			}
+			fields = fields[1:]
 		}
-
-		// 验证必填字段
-		if name == "" || url == "" {
-			failedCount++
-			continue
+		// 简化格式：站点名称, Logo, 站点链接
+		fields = append(fields, make([]string, 3)...)
+
+		site := &commonmodel.Site{
+			OwnerID: ownerID,
+			Name:    fields[0],
+			Logo:    fields[1],
+			URL:     fields[2],
 		}
+		row.model = site
+		row.item.Title = site.Name
+		row.item.Resource = constant.RESOURCE_SITES
 
-		// 创建站点记录
-		err = CreateSite(name, logo, url)
+		// 验证必填字段
+		err = requireFields("name", site.Name, "url", site.URL)
 		if err != nil {
-			failedCount++
-			continue
+			failImportRow(&row, err)
 		}
 
-		importedCount++
+		rows = append(rows, row)
 	}
 
-	return &commonmodel.ImportResult{
-		SuccessCount: importedCount,
-		FailedCount:  failedCount,
-	}, nil
+	return commitImportRows(ownerID, rows, options)
 }
@@ -0,0 +1,48 @@
+package common
+
+import (
+	"cyber-life/internal/constant"
+	"time"
+
+	commonrepository "cyber-life/internal/repository/common"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 19:10
+// @Desc:	回收站过期记录清理服务
+
+// trashPurgeInterval 清理回收站过期记录的间隔
+const trashPurgeInterval = time.Hour
+
+// trashResources 支持回收站的记录类型
+var trashResources = []string{
+	constant.RESOURCE_ACCOUNTS,
+	constant.RESOURCE_SECRETS,
+	constant.RESOURCE_HOSTS,
+	constant.RESOURCE_SITES,
+}
+
+// PurgeExpiredTrash 彻底删除移入回收站超过保留期限的记录，逐个记录类型调用 onPurge 报告删除的记录数或错误
+func PurgeExpiredTrash(retention time.Duration, onPurge func(resourceType string, count int64, err error)) {
+	before := time.Now().Add(-retention)
+	for _, resourceType := range trashResources {
+		count, err := commonrepository.PurgeTrashedRecords(resourceType, before)
+		if onPurge != nil && (count > 0 || err != nil) {
+			onPurge(resourceType, count, err)
+		}
+	}
+}
+
+// StartTrashPurge 启动后台协程，启动时及此后每小时清理一次回收站中超过保留期限的记录
+func StartTrashPurge(retention time.Duration, onPurge func(resourceType string, count int64, err error)) {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			PurgeExpiredTrash(retention, onPurge)
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,176 @@
+package system
+
+import (
+	"crypto/sha256"
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/encrypt"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemrepository "cyber-life/internal/repository/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 23:10
+// @Desc:	个人访问令牌服务实现
+
+// accessTokenHintLength 令牌提示保留的随机部分长度
+const accessTokenHintLength = 4
+
+// CreateAccessToken 为用户创建访问令牌，scopes 须为用户当前持有的权限，days 为有效天数（0表示默认值）；
+// 返回的令牌明文仅此一次可见
+func CreateAccessToken(userID uint, name string, scopes []string, days int) (*systemmodel.AccessToken, string, error) {
+	if days == 0 {
+		days = constant.ACCESS_TOKEN_DEFAULT_DAYS
+	}
+	if days < 0 || days > constant.ACCESS_TOKEN_MAX_DAYS {
+		return nil, "", errs.InvalidField("expires_in_days", "out of range")
+	}
+
+	scopes, err := normalizeTokenScopes(userID, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	raw, err := newAccessToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &systemmodel.AccessToken{
+		UserID:    userID,
+		Name:      name,
+		Hint:      raw[:len(constant.ACCESS_TOKEN_PREFIX)+accessTokenHintLength],
+		TokenHash: hashAccessToken(raw),
+		Scopes:    scopes,
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	}
+
+	err = systemrepository.CreateAccessToken(token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return token, raw, nil
+}
+
+// AuthenticateAccessToken 校验访问令牌，返回令牌及其所属用户，并按间隔记录最近使用时间与来源IP
+func AuthenticateAccessToken(raw, ip string) (*systemmodel.AccessToken, *systemmodel.User, error) {
+	token, err := systemrepository.FindAccessTokenByHash(hashAccessToken(raw))
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return nil, nil, errs.ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+
+	if token.RevokedAt != nil {
+		return nil, nil, errs.ErrInvalidToken
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, nil, errs.ErrExpiredToken
+	}
+
+	user, err := systemrepository.FindUserByID(token.UserID)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return nil, nil, errs.ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+	if !user.IsActive {
+		return nil, nil, errs.ErrInvalidToken
+	}
+
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) >= sessionTouchInterval || token.LastUsedIP != ip {
+		err = systemrepository.TouchAccessToken(token.ID, ip)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return token, user, nil
+}
+
+// FindUserAccessTokens 查询用户未吊销的访问令牌
+func FindUserAccessTokens(userID uint) ([]systemmodel.AccessToken, error) {
+	return systemrepository.FindAccessTokensByUserID(userID)
+}
+
+// RevokeUserAccessToken 吊销用户的指定访问令牌
+func RevokeUserAccessToken(userID, tokenID uint) error {
+	return systemrepository.RevokeAccessToken(userID, tokenID)
+}
+
+// AccessTokenScopesGrant 判断访问令牌的权限范围是否同时包含全部指定权限
+func AccessTokenScopesGrant(scopes []string, perms ...string) bool {
+	granted := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = true
+	}
+	for _, perm := range perms {
+		if !granted[perm] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalizeTokenScopes 去除重复的权限，并校验每项权限均存在且为用户当前持有
+func normalizeTokenScopes(userID uint, scopes []string) ([]string, error) {
+	codes, err := systemrepository.FindUserPermissionCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	held := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		held[code] = true
+	}
+
+	var (
+		normalized []string
+		fields     []systemmodel.FieldError
+		seen       = make(map[string]bool, len(scopes))
+	)
+	for _, scope := range scopes {
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+
+		if _, ok := constant.Permissions[scope]; !ok {
+			fields = append(fields, errs.Field("scopes", "unknown permission "+scope))
+		} else if !held[scope] {
+			fields = append(fields, errs.Field("scopes", "permission not held "+scope))
+		} else {
+			normalized = append(normalized, scope)
+		}
+	}
+	if len(fields) > 0 {
+		return nil, errs.ErrInvalidTokenScope.WithFields(fields...)
+	}
+
+	return normalized, nil
+}
+
+// newAccessToken 生成带固定前缀的随机访问令牌
+func newAccessToken() (string, error) {
+	buf, err := encrypt.RandomBytes(32)
+	if err != nil {
+		return "", err
+	}
+
+	return constant.ACCESS_TOKEN_PREFIX + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashAccessToken 计算访问令牌哈希，数据库中不保存令牌明文
+func hashAccessToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,98 @@
+package system
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemrepository "cyber-life/internal/repository/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 20:15
+// @Desc:	审计日志服务实现
+
+const auditVerifyBatchSize = 500
+
+// auditMutex 串行化事件追加，保证哈希链不分叉
+var auditMutex sync.Mutex
+
+// auditEventHash 计算审计事件哈希，覆盖前一条事件的哈希及除ID外的全部字段
+func auditEventHash(event *systemmodel.AuditEvent) string {
+	payload, _ := json.Marshal([]interface{}{
+		event.PrevHash,
+		event.CreatedAt.UnixMilli(),
+		event.ActorID,
+		event.Actor,
+		event.Action,
+		event.ResourceType,
+		event.ResourceID,
+		event.IP,
+		event.Outcome,
+		event.Detail,
+	})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// RecordAuditEvent 追加审计事件；未指定资源类型时取动作的前缀（如 secrets.export 的 secrets）
+func RecordAuditEvent(event *systemmodel.AuditEvent) error {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	// 时间精度截断到毫秒，保证不同数据库读回后哈希一致
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	event.CreatedAt = event.CreatedAt.Truncate(time.Millisecond)
+
+	if event.ResourceType == "" {
+		event.ResourceType, _, _ = strings.Cut(event.Action, ".")
+	}
+
+	return systemrepository.AppendAuditEvent(event, func(prevHash string) {
+		event.PrevHash = prevHash
+		event.Hash = auditEventHash(event)
+	})
+}
+
+// FindAuditEventsWithPage 按条件分页查询审计事件
+func FindAuditEventsWithPage(filter systemmodel.AuditEventFilter, page, size int) ([]systemmodel.AuditEvent, int64, error) {
+	return systemrepository.FindAuditEventsWithPage(filter, page, size)
+}
+
+// VerifyAuditChain 按顺序校验审计事件哈希链，返回已校验通过的事件数与首个断链事件的ID（0表示完整）
+func VerifyAuditChain() (int, uint, error) {
+	var (
+		checked  int
+		afterID  uint
+		prevHash string
+	)
+
+	for {
+		events, err := systemrepository.FindAuditEventsAfter(afterID, auditVerifyBatchSize)
+		if err != nil {
+			return checked, 0, err
+		}
+		if len(events) == 0 {
+			return checked, 0, nil
+		}
+
+		for i := range events {
+			event := &events[i]
+			if event.PrevHash != prevHash || auditEventHash(event) != event.Hash {
+				return checked, event.ID, nil
+			}
+
+			prevHash = event.Hash
+			afterID = event.ID
+			checked++
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package system
+
+import (
+	"cyber-life/internal/core/backup"
+	"cyber-life/internal/core/config"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 00:10
+// @Desc:	自动备份服务实现
+
+// FindBackupStatus 查询自动备份状态及备份目录下现存的快照
+func FindBackupStatus() (backup.Status, []backup.SnapshotFile, error) {
+	status := backup.CurrentStatus()
+	if !status.Enabled {
+		status.Dir = config.Config.Backup.Dir
+	}
+
+	snapshots, err := backup.ListSnapshots(status.Dir)
+	if err != nil {
+		return status, nil, err
+	}
+
+	return status, snapshots, nil
+}
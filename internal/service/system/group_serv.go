@@ -0,0 +1,108 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"errors"
+
+	systemmodel "cyber-life/internal/model/system"
+	commonrepository "cyber-life/internal/repository/common"
+	systemrepository "cyber-life/internal/repository/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 19:35
+// @Desc:	用户组服务实现
+
+// CreateGroup 创建用户组
+func CreateGroup(name, description string) error {
+	preGroup, err := systemrepository.FindGroupByName(name)
+	if err != nil && !errors.Is(err, errs.ErrRecordNotFound) {
+		return err
+	}
+	if preGroup != nil {
+		return errs.ErrGroupExists
+	}
+
+	return systemrepository.CreateGroup(&systemmodel.Group{
+		Name:        name,
+		Description: description,
+	})
+}
+
+// UpdateGroup 更新用户组
+func UpdateGroup(groupID uint, name, description string) error {
+	group, err := systemrepository.FindGroupByID(groupID)
+	if err != nil {
+		return err
+	}
+
+	if name != "" && name != group.Name {
+		existGroup, _ := systemrepository.FindGroupByName(name)
+		if existGroup != nil {
+			return errs.ErrGroupExists
+		}
+		group.Name = name
+	}
+	if description != "" {
+		group.Description = description
+	}
+
+	return systemrepository.UpdateGroup(group)
+}
+
+// DeleteGroup 删除用户组，同时撤销授予该用户组的全部共享
+func DeleteGroup(groupID uint) error {
+	group, err := systemrepository.FindGroupByID(groupID)
+	if err != nil {
+		return err
+	}
+
+	err = commonrepository.HardDeleteSharesByGrantee(constant.GRANTEE_GROUP, group.ID)
+	if err != nil {
+		return err
+	}
+
+	return systemrepository.HardDeleteGroup(group)
+}
+
+// FindGroupList 查询全部用户组
+func FindGroupList() ([]systemmodel.Group, error) {
+	return systemrepository.FindGroupList()
+}
+
+// AddGroupMember 添加用户组成员，已是成员时直接返回
+func AddGroupMember(groupID, userID uint) error {
+	_, err := systemrepository.FindGroupByID(groupID)
+	if err != nil {
+		return err
+	}
+	_, err = systemrepository.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	member, err := systemrepository.FindGroupMember(groupID, userID)
+	if err != nil && !errors.Is(err, errs.ErrRecordNotFound) {
+		return err
+	}
+	if member != nil {
+		return nil
+	}
+
+	return systemrepository.CreateGroupMember(&systemmodel.GroupMember{
+		GroupID: groupID,
+		UserID:  userID,
+	})
+}
+
+// RemoveGroupMember 移除用户组成员
+func RemoveGroupMember(groupID, userID uint) error {
+	member, err := systemrepository.FindGroupMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+
+	return systemrepository.HardDeleteGroupMember(member)
+}
@@ -0,0 +1,172 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/core/config"
+	"cyber-life/internal/errs"
+	"errors"
+	"sync"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemrepository "cyber-life/internal/repository/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 21:40
+// @Desc:	登录防爆破服务实现：按用户名与来源IP分别计数，失败后指数退避，超过阈值临时锁定
+
+// loginAttemptMutex 串行化失败计数的读改写，避免并发失败请求相互覆盖
+var loginAttemptMutex sync.Mutex
+
+// loginMaxFailures 指定维度的锁定阈值
+func loginMaxFailures(scope string) int {
+	if scope == constant.LOGIN_SCOPE_IP {
+		if config.Config.Login.IPMaxFailures <= 0 {
+			return 20
+		}
+		return config.Config.Login.IPMaxFailures
+	}
+
+	if config.Config.Login.MaxFailures <= 0 {
+		return 5
+	}
+	return config.Config.Login.MaxFailures
+}
+
+// loginLockoutDuration 临时锁定时长
+func loginLockoutDuration() time.Duration {
+	if config.Config.Login.LockoutMinutes <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(config.Config.Login.LockoutMinutes) * time.Minute
+}
+
+// loginBackoff 第 failures 次连续失败后的退避等待时长
+func loginBackoff(failures int) time.Duration {
+	base := time.Duration(config.Config.Login.BackoffBaseSeconds) * time.Second
+	if base <= 0 {
+		base = time.Second
+	}
+	limit := time.Duration(config.Config.Login.BackoffMaxSeconds) * time.Second
+	if limit <= 0 {
+		limit = time.Minute
+	}
+
+	backoff := base
+	for i := 1; i < failures && backoff < limit; i++ {
+		backoff *= 2
+	}
+	if backoff > limit {
+		backoff = limit
+	}
+
+	return backoff
+}
+
+// CheckLoginAllowed 校验用户名与来源IP当前是否允许尝试登录，拒绝时返回剩余等待时长
+func CheckLoginAllowed(username, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	for _, scope := range []string{constant.LOGIN_SCOPE_USERNAME, constant.LOGIN_SCOPE_IP} {
+		subject := username
+		if scope == constant.LOGIN_SCOPE_IP {
+			subject = ip
+		}
+
+		attempt, err := systemrepository.FindLoginAttempt(scope, subject)
+		if err != nil {
+			if errors.Is(err, errs.ErrRecordNotFound) {
+				continue
+			}
+			return 0, err
+		}
+
+		if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+			if scope == constant.LOGIN_SCOPE_USERNAME {
+				return attempt.LockedUntil.Sub(now), errs.ErrAccountLocked
+			}
+			return attempt.LockedUntil.Sub(now), errs.ErrTooManyLoginAttempts
+		}
+		if now.Before(attempt.BlockedUntil) {
+			return attempt.BlockedUntil.Sub(now), errs.ErrTooManyLoginAttempts
+		}
+	}
+
+	return 0, nil
+}
+
+// RecordLoginFailure 记录一次登录失败，更新退避等待时间，达到阈值时临时锁定；
+// username 为空时只按来源IP计数，用户名不存在时传空，避免任意用户名使计数表无限增长
+func RecordLoginFailure(username, ip string) error {
+	loginAttemptMutex.Lock()
+	defer loginAttemptMutex.Unlock()
+
+	now := time.Now()
+	window := loginLockoutDuration()
+
+	for _, scope := range []string{constant.LOGIN_SCOPE_USERNAME, constant.LOGIN_SCOPE_IP} {
+		subject := username
+		if scope == constant.LOGIN_SCOPE_IP {
+			subject = ip
+		}
+		if subject == "" {
+			continue
+		}
+
+		attempt, err := systemrepository.FindLoginAttempt(scope, subject)
+		if err != nil {
+			if !errors.Is(err, errs.ErrRecordNotFound) {
+				return err
+			}
+			attempt = &systemmodel.LoginAttempt{
+				Scope:   scope,
+				Subject: subject,
+			}
+		}
+
+		// 锁定已到期或距上次失败已超出统计窗口时重新计数
+		if (attempt.LockedUntil != nil && !now.Before(*attempt.LockedUntil)) || now.Sub(attempt.LastFailedAt) > window {
+			attempt.Failures = 0
+			attempt.LockedUntil = nil
+		}
+
+		attempt.Failures++
+		attempt.LastFailedAt = now
+		attempt.BlockedUntil = now.Add(loginBackoff(attempt.Failures))
+		if attempt.Failures >= loginMaxFailures(scope) {
+			lockedUntil := now.Add(window)
+			attempt.LockedUntil = &lockedUntil
+		}
+
+		err = systemrepository.SaveLoginAttempt(attempt)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 顺带清除统计窗口、退避等待与锁定均已结束的计数
+	return systemrepository.HardDeleteExpiredLoginAttempts(now.Add(-window), now)
+}
+
+// ResetLoginFailures 登录成功后清除该用户名的失败计数，来源IP的计数保留至统计窗口结束
+func ResetLoginFailures(username string) error {
+	loginAttemptMutex.Lock()
+	defer loginAttemptMutex.Unlock()
+
+	return systemrepository.HardDeleteLoginAttempt(constant.LOGIN_SCOPE_USERNAME, username)
+}
+
+// FindLoginLockouts 查询处于锁定或退避等待中的用户名与IP
+func FindLoginLockouts() ([]systemmodel.LoginAttempt, error) {
+	return systemrepository.FindActiveLoginAttempts(time.Now())
+}
+
+// ClearLoginLockout 解除指定的锁定并清零失败计数
+func ClearLoginLockout(attemptID uint) error {
+	loginAttemptMutex.Lock()
+	defer loginAttemptMutex.Unlock()
+
+	return systemrepository.HardDeleteLoginAttemptByID(attemptID)
+}
@@ -0,0 +1,274 @@
+package system
+
+import (
+	"cyber-life/internal/constant"
+	"cyber-life/internal/errs"
+	"errors"
+	"sort"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemrepository "cyber-life/internal/repository/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 18:25
+// @Desc:	角色与权限服务实现
+
+// InitRoles 同步权限表并确保内置角色存在，管理员角色每次启动时重新获得全部权限
+func InitRoles() error {
+	permissions, err := systemrepository.FindPermissionList()
+	if err != nil {
+		return err
+	}
+
+	exists := make(map[string]*systemmodel.Permission, len(permissions))
+	for i := range permissions {
+		exists[permissions[i].Code] = &permissions[i]
+	}
+
+	// 新增的权限需同步授予默认持有该权限的内置角色，以免升级后内置角色失去原有能力
+	added := make(map[string]bool)
+	for code, description := range constant.Permissions {
+		permission, ok := exists[code]
+		if !ok {
+			added[code] = true
+			err = systemrepository.CreatePermission(&systemmodel.Permission{
+				Code:        code,
+				Description: description,
+			})
+		} else if permission.Description != description {
+			permission.Description = description
+			err = systemrepository.UpdatePermission(permission)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	allCodes := make([]string, 0, len(constant.Permissions))
+	for code := range constant.Permissions {
+		allCodes = append(allCodes, code)
+	}
+
+	err = ensureBuiltinRole(constant.ROLE_ADMIN, "管理员，拥有全部权限", allCodes, true, added)
+	if err != nil {
+		return err
+	}
+	err = ensureBuiltinRole(constant.ROLE_EDITOR, "编辑者，可管理全部业务记录", constant.BuiltinRoles[constant.ROLE_EDITOR], false, added)
+	if err != nil {
+		return err
+	}
+	return ensureBuiltinRole(constant.ROLE_VIEWER, "访客，仅可查看业务记录", constant.BuiltinRoles[constant.ROLE_VIEWER], false, added)
+}
+
+// ensureBuiltinRole 创建缺失的内置角色；sync为真时覆盖已有角色的权限，否则仅为已有角色追加本次新增的默认权限
+func ensureBuiltinRole(name, description string, codes []string, sync bool, added map[string]bool) error {
+	permissions, err := systemrepository.FindPermissionsByCodes(codes)
+	if err != nil {
+		return err
+	}
+
+	role, err := systemrepository.FindRoleByName(name)
+	if err != nil {
+		if !errors.Is(err, errs.ErrRecordNotFound) {
+			return err
+		}
+
+		return systemrepository.CreateRole(&systemmodel.Role{
+			Name:        name,
+			Description: description,
+			BuiltIn:     true,
+			Permissions: permissions,
+		})
+	}
+
+	if sync {
+		return systemrepository.ReplaceRolePermissions(role, permissions)
+	}
+
+	var appended []systemmodel.Permission
+	for _, permission := range permissions {
+		if added[permission.Code] {
+			appended = append(appended, permission)
+		}
+	}
+	if len(appended) == 0 {
+		return nil
+	}
+	return systemrepository.AppendRolePermissions(role, appended)
+}
+
+// resolvePermissions 校验并查询权限编码对应的权限
+func resolvePermissions(codes []string) ([]systemmodel.Permission, error) {
+	for _, code := range codes {
+		if _, ok := constant.Permissions[code]; !ok {
+			return nil, errs.ErrUnknownPermission
+		}
+	}
+	if len(codes) == 0 {
+		return []systemmodel.Permission{}, nil
+	}
+
+	return systemrepository.FindPermissionsByCodes(codes)
+}
+
+// CreateRole 创建自定义角色
+func CreateRole(name, description string, codes []string) error {
+	preRole, err := systemrepository.FindRoleByName(name)
+	if err != nil && !errors.Is(err, errs.ErrRecordNotFound) {
+		return err
+	}
+	if preRole != nil {
+		return errs.ErrRoleExists
+	}
+
+	permissions, err := resolvePermissions(codes)
+	if err != nil {
+		return err
+	}
+
+	return systemrepository.CreateRole(&systemmodel.Role{
+		Name:        name,
+		Description: description,
+		Permissions: permissions,
+	})
+}
+
+// UpdateRole 更新角色；内置角色不可重命名，管理员角色的权限不可修改
+func UpdateRole(roleID uint, name, description string, codes []string) error {
+	role, err := systemrepository.FindRoleByID(roleID)
+	if err != nil {
+		return err
+	}
+
+	if codes != nil && role.Name == constant.ROLE_ADMIN {
+		return errs.ErrRoleImmutable
+	}
+
+	if name != "" && name != role.Name {
+		if role.BuiltIn {
+			return errs.ErrRoleImmutable
+		}
+		existRole, _ := systemrepository.FindRoleByName(name)
+		if existRole != nil {
+			return errs.ErrRoleExists
+		}
+		role.Name = name
+	}
+	if description != "" {
+		role.Description = description
+	}
+
+	var permissions []systemmodel.Permission
+	if codes != nil {
+		permissions, err = resolvePermissions(codes)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = systemrepository.UpdateRole(role)
+	if err != nil {
+		return err
+	}
+
+	if codes != nil {
+		return systemrepository.ReplaceRolePermissions(role, permissions)
+	}
+
+	return nil
+}
+
+// DeleteRole 删除自定义角色，仍有用户使用的角色不可删除
+func DeleteRole(roleID uint) error {
+	role, err := systemrepository.FindRoleByID(roleID)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return errs.ErrRoleImmutable
+	}
+
+	count, err := systemrepository.CountUsersByRoleID(role.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errs.ErrRoleInUse
+	}
+
+	return systemrepository.HardDeleteRole(role)
+}
+
+// FindRoleByID 根据ID查询角色
+func FindRoleByID(roleID uint) (*systemmodel.Role, error) {
+	return systemrepository.FindRoleByID(roleID)
+}
+
+// FindRoleByName 根据Name查询角色
+func FindRoleByName(name string) (*systemmodel.Role, error) {
+	return systemrepository.FindRoleByName(name)
+}
+
+// FindRoleList 查询全部角色
+func FindRoleList() ([]systemmodel.Role, error) {
+	return systemrepository.FindRoleList()
+}
+
+// FindPermissionList 查询全部权限
+func FindPermissionList() ([]systemmodel.Permission, error) {
+	return systemrepository.FindPermissionList()
+}
+
+// FindUserPermissions 查询用户持有的全部权限编码
+func FindUserPermissions(userID uint) ([]string, error) {
+	codes, err := systemrepository.FindUserPermissionCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(codes)
+	return codes, nil
+}
+
+// UserHasPermissions 判断用户是否同时持有全部指定权限
+func UserHasPermissions(userID uint, perms ...string) (bool, error) {
+	codes, err := systemrepository.FindUserPermissionCodes(userID)
+	if err != nil {
+		return false, err
+	}
+
+	granted := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		granted[code] = true
+	}
+	for _, perm := range perms {
+		if !granted[perm] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// checkLastAdmin 用户即将失去管理员角色时，确保系统中仍保留至少一名管理员
+func checkLastAdmin(user *systemmodel.User) error {
+	adminRole, err := systemrepository.FindRoleByName(constant.ROLE_ADMIN)
+	if err != nil {
+		return err
+	}
+	if user.RoleID != adminRole.ID || !user.IsActive {
+		return nil
+	}
+
+	count, err := systemrepository.CountUsersByRoleID(adminRole.ID)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return errs.ErrLastAdminRequired
+	}
+
+	return nil
+}
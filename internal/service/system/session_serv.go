@@ -0,0 +1,189 @@
+package system
+
+import (
+	"crypto/sha256"
+	"cyber-life/internal/constant"
+	"cyber-life/internal/core/config"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/encrypt"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemrepository "cyber-life/internal/repository/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 17:25
+// @Desc:	用户登录会话服务实现
+
+// 会话最近活跃时间的刷新间隔，避免每个请求都写库
+const sessionTouchInterval = time.Minute
+
+// AccessTokenTTL 访问令牌有效期
+func AccessTokenTTL() time.Duration {
+	if config.Config.Session.AccessTokenMinutes <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(config.Config.Session.AccessTokenMinutes) * time.Minute
+}
+
+// refreshTokenTTL 刷新令牌有效期
+func refreshTokenTTL() time.Duration {
+	if config.Config.Session.RefreshTokenDays <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return time.Duration(config.Config.Session.RefreshTokenDays) * 24 * time.Hour
+}
+
+// CreateSession 为登录用户创建会话，返回会话与刷新令牌
+func CreateSession(userID uint, device, ip, userAgent string) (*systemmodel.Session, string, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := &systemmodel.Session{
+		UserID:      userID,
+		Device:      device,
+		IP:          ip,
+		UserAgent:   userAgent,
+		RefreshHash: hashRefreshToken(refreshToken),
+		LastSeenAt:  now,
+		ExpiresAt:   now.Add(refreshTokenTTL()),
+	}
+
+	err = systemrepository.CreateSession(session)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return session, refreshToken, nil
+}
+
+// RefreshSession 使用刷新令牌换取新的刷新令牌（轮换）
+// 已轮换掉的旧令牌再次出现说明令牌可能被盗用，此时吊销整个会话
+func RefreshSession(refreshToken, ip string) (*systemmodel.Session, string, error) {
+	refreshHash := hashRefreshToken(refreshToken)
+
+	session, err := systemrepository.FindSessionByRefreshHash(refreshHash)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return nil, "", errs.ErrInvalidRefreshToken
+		}
+		return nil, "", err
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, "", errs.ErrInvalidRefreshToken
+	}
+
+	if session.RefreshHash != refreshHash {
+		err = systemrepository.RevokeSession(session.ID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// 刷新令牌被重复使用意味着令牌可能已泄露，记录安全事件
+		err = RecordAuditEvent(&systemmodel.AuditEvent{
+			ActorID:    session.UserID,
+			Action:     "sessions.refresh",
+			ResourceID: strconv.FormatUint(uint64(session.ID), 10),
+			IP:         ip,
+			Outcome:    constant.AUDIT_OUTCOME_DENIED,
+			Detail:     "refresh token reused, session revoked",
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		return nil, "", errs.ErrRefreshTokenReused
+	}
+
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	ok, err := systemrepository.RotateSessionRefreshHash(session.ID, refreshHash, hashRefreshToken(newToken), time.Now().Add(refreshTokenTTL()))
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		return nil, "", errs.ErrInvalidRefreshToken
+	}
+
+	if ip != "" && ip != session.IP {
+		_ = systemrepository.UpdateSessionFields(session.ID, map[string]interface{}{"ip": ip})
+	}
+
+	return session, newToken, nil
+}
+
+// ValidateSession 校验访问令牌绑定的会话是否仍然有效，并按间隔刷新最近活跃时间
+func ValidateSession(sessionID, userID uint, ip string) (*systemmodel.Session, error) {
+	session, err := systemrepository.FindSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID != userID || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, errs.ErrSessionRevoked
+	}
+
+	if time.Since(session.LastSeenAt) >= sessionTouchInterval {
+		fields := map[string]interface{}{"last_seen_at": time.Now()}
+		if ip != "" {
+			fields["ip"] = ip
+		}
+		err = systemrepository.UpdateSessionFields(session.ID, fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return session, nil
+}
+
+// FindUserSessions 查询用户的有效会话
+func FindUserSessions(userID uint) ([]systemmodel.Session, error) {
+	return systemrepository.FindActiveSessionsByUserID(userID)
+}
+
+// RevokeUserSession 吊销用户的指定会话
+func RevokeUserSession(userID, sessionID uint) error {
+	session, err := systemrepository.FindSessionByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return errs.ErrRecordNotFound
+	}
+
+	return systemrepository.RevokeSession(sessionID)
+}
+
+// RevokeOtherSessions 吊销用户除当前会话外的全部会话
+func RevokeOtherSessions(userID, currentSessionID uint) error {
+	return systemrepository.RevokeUserSessions(userID, currentSessionID)
+}
+
+// newRefreshToken 生成随机刷新令牌
+func newRefreshToken() (string, error) {
+	buf, err := encrypt.RandomBytes(32)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashRefreshToken 计算刷新令牌哈希，数据库中不保存令牌明文
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,261 @@
+package system
+
+import (
+	"crypto/sha256"
+	"cyber-life/internal/core/config"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/auth"
+	"cyber-life/pkg/encrypt"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemrepository "cyber-life/internal/repository/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 15:45
+// @Desc:	双因素认证（TOTP）服务实现
+
+const (
+	totpIssuer        = "Cyber Life"
+	recoveryCodeCount = 10
+	recoveryCodeSize  = 10
+)
+
+// SetupUserTotp 为用户生成新的TOTP密钥（尚未启用），返回密钥与扫码链接
+func SetupUserTotp(userID uint) (string, string, error) {
+	user, err := systemrepository.FindUserByID(userID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TotpEnabled {
+		return "", "", errs.ErrTotpAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTotpSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	sealedSecret, err := sealTotpSecret(secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = systemrepository.UpdateUserFields(user.ID, map[string]interface{}{
+		"totp_secret":    sealedSecret,
+		"totp_last_step": 0,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return secret, auth.TotpProvisioningURI(totpIssuer, user.Username, secret), nil
+}
+
+// EnableUserTotp 校验验证器生成的口令后启用TOTP，返回一次性展示的恢复码
+func EnableUserTotp(userID uint, code string) ([]string, error) {
+	user, err := systemrepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TotpEnabled {
+		return nil, errs.ErrTotpAlreadyEnabled
+	}
+	if user.TotpSecret == "" {
+		return nil, errs.ErrTotpNotSetUp
+	}
+
+	ok, err := verifyTotpCode(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errs.ErrInvalidTotpCode
+	}
+
+	err = systemrepository.UpdateUserFields(user.ID, map[string]interface{}{
+		"totp_enabled": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resetRecoveryCodes(user.ID)
+}
+
+// DisableUserTotp 校验第二因素后停用TOTP并清除密钥与恢复码
+func DisableUserTotp(userID uint, code string) error {
+	user, err := systemrepository.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TotpEnabled {
+		return errs.ErrTotpNotEnabled
+	}
+
+	ok, err := VerifyUserSecondFactor(user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errs.ErrInvalidTotpCode
+	}
+
+	err = systemrepository.UpdateUserFields(user.ID, map[string]interface{}{
+		"totp_enabled":   false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+	})
+	if err != nil {
+		return err
+	}
+
+	return systemrepository.HardDeleteRecoveryCodesByUserID(user.ID)
+}
+
+// RegenerateRecoveryCodes 校验TOTP口令后重新生成恢复码，旧恢复码全部作废
+func RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := systemrepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TotpEnabled {
+		return nil, errs.ErrTotpNotEnabled
+	}
+
+	ok, err := verifyTotpCode(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errs.ErrInvalidTotpCode
+	}
+
+	return resetRecoveryCodes(user.ID)
+}
+
+// VerifyUserSecondFactor 校验第二因素，code 可以是TOTP口令或未使用的恢复码
+func VerifyUserSecondFactor(user *systemmodel.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return false, nil
+	}
+
+	ok, err := verifyTotpCode(user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	recoveryCode, err := systemrepository.FindUnusedRecoveryCode(user.ID, hashRecoveryCode(code))
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return systemrepository.MarkRecoveryCodeUsed(recoveryCode.ID)
+}
+
+// CountUnusedRecoveryCodes 统计用户剩余可用的恢复码数量
+func CountUnusedRecoveryCodes(userID uint) (int64, error) {
+	return systemrepository.CountUnusedRecoveryCodes(userID)
+}
+
+// verifyTotpCode 校验TOTP口令，同一时间步的口令只能使用一次
+func verifyTotpCode(user *systemmodel.User, code string) (bool, error) {
+	secret, err := openTotpSecret(user.TotpSecret)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := auth.ValidateTotp(secret, code, time.Now())
+	if !ok || step <= user.TotpLastStep {
+		return false, nil
+	}
+
+	ok, err = systemrepository.AdvanceUserTotpStep(user.ID, step)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	user.TotpLastStep = step
+	return true, nil
+}
+
+// resetRecoveryCodes 删除旧恢复码并生成新的恢复码，数据库中仅保存哈希
+func resetRecoveryCodes(userID uint) ([]string, error) {
+	err := systemrepository.HardDeleteRecoveryCodesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		plainCodes []string
+		codes      []systemmodel.RecoveryCode
+	)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf, err := encrypt.RandomBytes(recoveryCodeSize)
+		if err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))[:recoveryCodeSize]
+		code = code[:5] + "-" + code[5:]
+		plainCodes = append(plainCodes, code)
+		codes = append(codes, systemmodel.RecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+
+	err = systemrepository.CreateRecoveryCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	return plainCodes, nil
+}
+
+// hashRecoveryCode 计算恢复码哈希，恢复码本身为高熵随机值，加盐Sha256即可
+func hashRecoveryCode(code string) string {
+	return encrypt.Sha256String(strings.ToLower(code), config.Config.SecretKey)
+}
+
+// totpSecretKey 由系统密钥派生TOTP密钥的加密密钥
+// TOTP校验发生在登录阶段，此时保险库可能处于封存状态，因此不使用保险库数据密钥
+func totpSecretKey() []byte {
+	sum := sha256.Sum256([]byte(config.Config.SecretKey + "#totp"))
+	return sum[:]
+}
+
+// sealTotpSecret 加密TOTP密钥
+func sealTotpSecret(secret string) (string, error) {
+	data, err := encrypt.AesGcmEncrypt(totpSecretKey(), []byte(secret))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// openTotpSecret 解密TOTP密钥
+func openTotpSecret(sealedSecret string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealedSecret)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := encrypt.AesGcmDecrypt(totpSecretKey(), data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
@@ -1,11 +1,14 @@
 package system
 
 import (
+	"cyber-life/internal/constant"
 	"cyber-life/internal/core/config"
+	"cyber-life/internal/errs"
 	"cyber-life/pkg/encrypt"
 	"errors"
 
 	systemmodel "cyber-life/internal/model/system"
+	commonrepository "cyber-life/internal/repository/common"
 	systemrepository "cyber-life/internal/repository/system"
 )
 
@@ -14,17 +17,34 @@ import (
 // @Date:   2025/10/28 14:35
 // @Desc:	系统用户服务实现
 
-// CreateUser 创建用户
-func CreateUser(username, password, name, email, phone, avatar string) error {
+// CreateUser 创建用户，未指定角色时默认授予访客角色；actorID 为执行操作的用户，命令行与初始化等本地调用传0，不做授权校验；
+// actorScopes 为操作者所用个人访问令牌的权限范围，未使用令牌时为nil
+func CreateUser(actorID uint, actorScopes []string, username, password, name, email, phone, avatar string, roleID uint) error {
+	// 指定角色相当于授予权限，须同时具备角色管理权限
+	if actorID != 0 && roleID != 0 {
+		err := requireRoleManager(actorID, actorScopes)
+		if err != nil {
+			return err
+		}
+	}
+
 	preUser, err := systemrepository.FindUserByUsername(username)
-	if err != nil && err.Error() != "record not found" {
+	if err != nil && !errors.Is(err, errs.ErrRecordNotFound) {
 		return err
 	}
 	if preUser != nil {
-		return errors.New("the username already exists")
+		return errs.ErrUsernameExists
+	}
+
+	role, err := findRoleOrDefault(roleID)
+	if err != nil {
+		return err
 	}
 
-	password = encrypt.Sha256String(password, config.Config.SecretKey)
+	password, err = encrypt.HashPassword(password)
+	if err != nil {
+		return err
+	}
 
 	newUser := &systemmodel.User{
 		Username: username,
@@ -34,21 +54,49 @@ func CreateUser(username, password, name, email, phone, avatar string) error {
 		Phone:    phone,
 		Avatar:   avatar,
 		IsActive: true,
+		RoleID:   role.ID,
 	}
 
 	return systemrepository.CreateUser(newUser)
 }
 
-// DeleteUser 删除用户
-func DeleteUser(userID uint) error {
+// DeleteUser 删除用户；actorID、actorScopes 含义同 CreateUser
+func DeleteUser(actorID uint, actorScopes []string, userID uint) error {
 	user, err := systemrepository.FindUserByID(userID)
 	if err != nil {
 		return err
 	}
 
-	user.IsActive = false
-	user.JwtSign = "-"
-	err = systemrepository.UpdateUser(user)
+	if actorID != 0 {
+		err = requireManagerForPrivileged(actorID, actorScopes, user.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = checkLastAdmin(user)
+	if err != nil {
+		return err
+	}
+
+	err = systemrepository.UpdateUserFields(user.ID, map[string]interface{}{
+		"is_active": false,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = systemrepository.RevokeUserSessions(user.ID, 0)
+	if err != nil {
+		return err
+	}
+
+	// 移除用户组成员关系并撤销共享给该用户的记录
+	err = systemrepository.HardDeleteGroupMembersByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+	err = commonrepository.HardDeleteSharesByGrantee(constant.GRANTEE_USER, user.ID)
 	if err != nil {
 		return err
 	}
@@ -56,22 +104,61 @@ func DeleteUser(userID uint) error {
 	return systemrepository.SoftDeleteUser(user)
 }
 
-// UpdateUser 更新用户
-func UpdateUser(userID uint, username, password, name, email, phone, avatar, jwtSign string) error {
+// DisableUser 停用用户并吊销其全部会话，停用后无法登录；actorID、actorScopes 含义同 CreateUser
+func DisableUser(actorID uint, actorScopes []string, userID uint) error {
+	user, err := systemrepository.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if actorID != 0 {
+		err = requireManagerForPrivileged(actorID, actorScopes, user.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = checkLastAdmin(user)
+	if err != nil {
+		return err
+	}
+
+	err = systemrepository.UpdateUserFields(user.ID, map[string]interface{}{
+		"is_active": false,
+	})
+	if err != nil {
+		return err
+	}
+
+	return systemrepository.RevokeUserSessions(user.ID, 0)
+}
+
+// UpdateUser 更新用户，roleID为0时不修改角色；actorID、actorScopes 含义同 CreateUser
+func UpdateUser(actorID uint, actorScopes []string, userID uint, username, password, name, email, phone, avatar string, roleID uint) error {
 	user, err := systemrepository.FindUserByID(userID)
 	if err != nil {
 		return err
 	}
 
+	if actorID != 0 {
+		err = authorizeUserUpdate(actorID, actorScopes, user, password, roleID)
+		if err != nil {
+			return err
+		}
+	}
+
 	if username != "" && username != user.Username {
 		existUser, _ := systemrepository.FindUserByUsername(username)
 		if existUser != nil {
-			return errors.New("the username already exists")
+			return errs.ErrUsernameExists
 		}
 		user.Username = username
 	}
 	if password != "" {
-		user.Password = encrypt.Sha256String(password, config.Config.SecretKey)
+		user.Password, err = encrypt.HashPassword(password)
+		if err != nil {
+			return err
+		}
 	}
 	if name != "" {
 		user.Name = name
@@ -85,11 +172,115 @@ func UpdateUser(userID uint, username, password, name, email, phone, avatar, jwt
 	if avatar != "" {
 		user.Avatar = avatar
 	}
-	if jwtSign != "" {
-		user.JwtSign = jwtSign
+	if roleID != 0 && roleID != user.RoleID {
+		_, err = systemrepository.FindRoleByID(roleID)
+		if err != nil {
+			return err
+		}
+		err = checkLastAdmin(user)
+		if err != nil {
+			return err
+		}
+		user.RoleID = roleID
+	}
+
+	err = systemrepository.UpdateUser(user)
+	if err != nil {
+		return err
+	}
+
+	// 修改口令后吊销该用户的全部会话
+	if password != "" {
+		return systemrepository.RevokeUserSessions(user.ID, 0)
+	}
+
+	return nil
+}
+
+// authorizeUserUpdate 修改角色须具备角色管理权限；非管理员不得修改持有管理权限的其他用户的口令
+func authorizeUserUpdate(actorID uint, actorScopes []string, user *systemmodel.User, password string, roleID uint) error {
+	if roleID != 0 && roleID != user.RoleID {
+		return requireRoleManager(actorID, actorScopes)
+	}
+	if password == "" || actorID == user.ID {
+		return nil
+	}
+
+	return requireManagerForPrivileged(actorID, actorScopes, user.ID)
+}
+
+// requireManagerForPrivileged 目标用户持有管理员级别的权限时，要求操作者持有角色管理权限
+func requireManagerForPrivileged(actorID uint, actorScopes []string, userID uint) error {
+	privileged, err := userHoldsAnyPermission(userID, adminPermissions...)
+	if err != nil || !privileged {
+		return err
+	}
+	return requireRoleManager(actorID, actorScopes)
+}
+
+// adminPermissions 管理员级别的权限，持有任一项的用户只能由管理员修改口令、删除或停用
+var adminPermissions = []string{constant.PERM_USERS_MANAGE, constant.PERM_ROLES_MANAGE}
+
+// requireRoleManager 要求操作者持有角色管理权限，持有该权限即可授予任意权限，视为管理员；使用个人访问令牌时令牌的权限范围也须包含该权限
+func requireRoleManager(actorID uint, actorScopes []string) error {
+	granted, err := UserHasPermissions(actorID, constant.PERM_ROLES_MANAGE)
+	if err != nil {
+		return err
+	}
+	if actorScopes != nil {
+		granted = granted && AccessTokenScopesGrant(actorScopes, constant.PERM_ROLES_MANAGE)
+	}
+	if !granted {
+		return errs.ErrPermissionDenied
+	}
+	return nil
+}
+
+// userHoldsAnyPermission 判断用户是否持有指定权限中的任意一项
+func userHoldsAnyPermission(userID uint, perms ...string) (bool, error) {
+	codes, err := systemrepository.FindUserPermissionCodes(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, code := range codes {
+		for _, perm := range perms {
+			if code == perm {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// findRoleOrDefault 查询指定角色，roleID为0时返回访客角色
+func findRoleOrDefault(roleID uint) (*systemmodel.Role, error) {
+	if roleID == 0 {
+		return systemrepository.FindRoleByName(constant.ROLE_VIEWER)
+	}
+	return systemrepository.FindRoleByID(roleID)
+}
+
+// VerifyUserPassword 校验用户口令，校验通过且哈希已过时（旧版Sha256、bcrypt或低强度参数）时自动升级为新哈希
+func VerifyUserPassword(user *systemmodel.User, password string) (bool, error) {
+	ok, needsRehash, err := encrypt.VerifyPassword(password, user.Password, config.Config.SecretKey)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if needsRehash {
+		newHash, err := encrypt.HashPassword(password)
+		if err != nil {
+			return true, err
+		}
+
+		user.Password = newHash
+		err = systemrepository.UpdateUser(user)
+		if err != nil {
+			return true, err
+		}
 	}
 
-	return systemrepository.UpdateUser(user)
+	return true, nil
 }
 
 // FindUserByID 根据ID查询用户
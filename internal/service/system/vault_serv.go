@@ -0,0 +1,126 @@
+package system
+
+import (
+	"cyber-life/internal/core/vault"
+	"cyber-life/internal/errs"
+	"cyber-life/pkg/encrypt"
+	"encoding/base64"
+	"errors"
+	"sync"
+
+	systemmodel "cyber-life/internal/model/system"
+	systemrepository "cyber-life/internal/repository/system"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 11:15
+// @Desc:	保险库服务实现
+
+// 首次初始化保险库时主口令的最小长度
+const minPassphraseLength = 8
+
+// vaultKeyMutex 串行化保险库密钥的查询与首次初始化，避免并发的首次解封各自生成不同的数据密钥
+var vaultKeyMutex sync.Mutex
+
+// UnsealVault 使用主口令解开数据密钥并解封保险库，首次运行时生成新的数据密钥
+func UnsealVault(passphrase string) error {
+	vaultKeyMutex.Lock()
+	defer vaultKeyMutex.Unlock()
+
+	vaultKey, err := systemrepository.FindVaultKey()
+	if err != nil && !errors.Is(err, errs.ErrRecordNotFound) {
+		return err
+	}
+
+	if vaultKey == nil {
+		return initVaultKey(passphrase)
+	}
+
+	return unsealVaultKey(vaultKey, passphrase)
+}
+
+// unsealVaultKey 使用主口令解开已保存的数据密钥并解封保险库
+func unsealVaultKey(vaultKey *systemmodel.VaultKey, passphrase string) error {
+	salt, err := base64.StdEncoding.DecodeString(vaultKey.Salt)
+	if err != nil {
+		return err
+	}
+	wrappedKey, err := base64.StdEncoding.DecodeString(vaultKey.WrappedKey)
+	if err != nil {
+		return err
+	}
+
+	params := encrypt.Argon2Params{
+		Time:    vaultKey.Time,
+		Memory:  vaultKey.Memory,
+		Threads: vaultKey.Threads,
+		KeyLen:  encrypt.DefaultArgon2Params.KeyLen,
+	}
+	dataKey, err := vault.UnwrapDataKey(passphrase, salt, params, wrappedKey)
+	if err != nil {
+		return err
+	}
+
+	vault.Unseal(dataKey)
+	return nil
+}
+
+// SealVault 封存保险库，清除内存中的数据密钥
+func SealVault() {
+	vault.Seal()
+}
+
+// FindVaultStatus 查询保险库状态：是否已初始化、是否处于封存状态
+func FindVaultStatus() (initialized bool, sealed bool, err error) {
+	vaultKey, err := systemrepository.FindVaultKey()
+	if err != nil && !errors.Is(err, errs.ErrRecordNotFound) {
+		return false, false, err
+	}
+
+	return vaultKey != nil, vault.IsSealed(), nil
+}
+
+// initVaultKey 生成并保存新的数据密钥
+func initVaultKey(passphrase string) error {
+	if len(passphrase) < minPassphraseLength {
+		return errs.ErrPassphraseTooShort
+	}
+
+	salt, err := encrypt.RandomBytes(16)
+	if err != nil {
+		return err
+	}
+
+	dataKey, err := vault.NewDataKey()
+	if err != nil {
+		return err
+	}
+
+	params := encrypt.DefaultArgon2Params
+	wrappedKey, err := vault.WrapDataKey(passphrase, salt, params, dataKey)
+	if err != nil {
+		return err
+	}
+
+	singleton := true
+	err = systemrepository.CreateVaultKey(&systemmodel.VaultKey{
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Time:       params.Time,
+		Memory:     params.Memory,
+		Threads:    params.Threads,
+		WrappedKey: base64.StdEncoding.EncodeToString(wrappedKey),
+		Singleton:  &singleton,
+	})
+	if err != nil {
+		// 其他实例已抢先初始化时改用已保存的数据密钥解封
+		vaultKey, findErr := systemrepository.FindVaultKey()
+		if findErr != nil {
+			return err
+		}
+		return unsealVaultKey(vaultKey, passphrase)
+	}
+
+	vault.Unseal(dataKey)
+	return nil
+}
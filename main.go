@@ -1,10 +1,8 @@
 package main
 
 import (
-	"cyber-life/internal/core"
-	"cyber-life/internal/core/initialize"
-	"cyber-life/pkg/logger"
-	"log"
+	"cyber-life/internal/cli"
+	"os"
 )
 
 // @Author: yv1ing
@@ -13,18 +11,5 @@ import (
 // @Desc:   程序主入口
 
 func main() {
-	var err error
-
-	// 初始化系统全局配置
-	err = initialize.InitGlobalConfig("config.toml")
-	if err != nil {
-		log.Fatal("an error occurred while loading the configuration file: ", err)
-	}
-
-	// 初始化系统全局日志
-	logger.InitLogger("app.log", "debug")
-	defer logger.Close()
-
-	// 启动Web应用
-	core.Start()
+	os.Exit(cli.Run(os.Args[1:]))
 }
@@ -13,23 +13,23 @@ import (
 // @Desc:	Jwt生成与解析
 
 type AccessClaims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	JwtSign  string `json:"jwt_sign"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	SessionID uint   `json:"session_id"`
 	jwt.RegisteredClaims
 }
 
-func CreateAccessToken(userID uint, username, secretKey, jwtSign string) (string, error) {
+func CreateAccessToken(userID uint, username, secretKey string, sessionID uint, ttl time.Duration) (string, error) {
 	jwtSecret := []byte(secretKey)
 
 	claims := AccessClaims{
-		UserID:   userID,
-		Username: username,
-		JwtSign:  jwtSign,
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   "cyber-life",
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(12 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			ID:        fmt.Sprintf("%d-%d", userID, time.Now().UnixNano()),
 		},
 	}
@@ -42,7 +42,7 @@ func ParseAccessToken(tokenStr, secretKey string) (*AccessClaims, error) {
 	jwtSecret := []byte(secretKey)
 	token, err := jwt.ParseWithClaims(tokenStr, &AccessClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
-	})
+	}, jwt.WithSubject("cyber-life"))
 
 	if err != nil {
 		return nil, err
@@ -54,3 +54,46 @@ func ParseAccessToken(tokenStr, secretKey string) (*AccessClaims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// MfaClaims 两步登录中，口令校验通过后签发的临时凭证
+type MfaClaims struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+// CreateMfaToken 签发有效期5分钟的两步登录临时凭证，使用独立的签名密钥，不能作为访问令牌使用
+func CreateMfaToken(userID uint, username, secretKey string) (string, error) {
+	mfaSecret := []byte(secretKey + "#mfa")
+
+	claims := MfaClaims{
+		UserID:   userID,
+		Username: username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "cyber-life-mfa",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+			ID:        fmt.Sprintf("%d-%d", userID, time.Now().UnixNano()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(mfaSecret)
+}
+
+func ParseMfaToken(tokenStr, secretKey string) (*MfaClaims, error) {
+	mfaSecret := []byte(secretKey + "#mfa")
+	token, err := jwt.ParseWithClaims(tokenStr, &MfaClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return mfaSecret, nil
+	}, jwt.WithSubject("cyber-life-mfa"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*MfaClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid token")
+}
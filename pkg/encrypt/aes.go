@@ -0,0 +1,62 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 10:12
+// @Desc:	AES-GCM对称加解密
+
+// AesGcmEncrypt 使用AES-GCM加密数据，返回结果为 nonce||ciphertext
+func AesGcmEncrypt(key, plaintext []byte) ([]byte, error) {
+	return AesGcmEncryptWithAD(key, plaintext, nil)
+}
+
+// AesGcmEncryptWithAD 使用AES-GCM加密数据并认证附加数据，返回结果为 nonce||ciphertext
+func AesGcmEncryptWithAD(key, plaintext, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := RandomBytes(gcm.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// AesGcmDecrypt 使用AES-GCM解密由 AesGcmEncrypt 生成的数据
+func AesGcmDecrypt(key, data []byte) ([]byte, error) {
+	return AesGcmDecryptWithAD(key, data, nil)
+}
+
+// AesGcmDecryptWithAD 使用AES-GCM解密由 AesGcmEncryptWithAD 生成的数据并校验附加数据
+func AesGcmDecryptWithAD(key, data, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
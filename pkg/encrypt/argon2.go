@@ -0,0 +1,29 @@
+package encrypt
+
+import "golang.org/x/crypto/argon2"
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 10:20
+// @Desc:	基于Argon2id的密钥派生
+
+// Argon2Params Argon2id派生参数
+type Argon2Params struct {
+	Time    uint32
+	Memory  uint32 // 单位KB
+	Threads uint8
+	KeyLen  uint32
+}
+
+// DefaultArgon2Params 默认派生参数
+var DefaultArgon2Params = Argon2Params{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 2,
+	KeyLen:  32,
+}
+
+// Argon2idKey 使用Argon2id从口令派生密钥
+func Argon2idKey(password, salt []byte, params Argon2Params) []byte {
+	return argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, params.KeyLen)
+}
@@ -0,0 +1,126 @@
+package encrypt
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 14:20
+// @Desc:	用户口令哈希与校验
+//
+// 新口令统一使用Argon2id哈希，存储格式为：
+// $argon2id$v=19$m=65536,t=3,p=2$<Base64盐值>$<Base64哈希>
+// 校验时兼容bcrypt哈希与旧版全局加盐的Sha256哈希，并提示调用方升级
+
+const passwordSaltSize = 16
+
+var ErrInvalidPasswordHash = errors.New("invalid password hash format")
+
+// HashPassword 使用Argon2id和随机盐值计算口令哈希
+func HashPassword(password string) (string, error) {
+	salt, err := RandomBytes(passwordSaltSize)
+	if err != nil {
+		return "", err
+	}
+
+	params := DefaultArgon2Params
+	hash := Argon2idKey([]byte(password), salt, params)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		params.Memory,
+		params.Time,
+		params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+// VerifyPassword 以常量时间校验口令，legacySalt 为旧版Sha256哈希使用的全局盐值
+// needsRehash 为 true 表示校验通过但哈希算法或参数已过时，应重新计算哈希
+func VerifyPassword(password, encoded, legacySalt string) (ok bool, needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		params, salt, hash, err := decodeArgon2idHash(encoded)
+		if err != nil {
+			return false, false, err
+		}
+
+		params.KeyLen = uint32(len(hash))
+		computed := Argon2idKey([]byte(password), salt, params)
+		if subtle.ConstantTimeCompare(computed, hash) != 1 {
+			return false, false, nil
+		}
+
+		needsRehash = params.Time != DefaultArgon2Params.Time ||
+			params.Memory != DefaultArgon2Params.Memory ||
+			params.Threads != DefaultArgon2Params.Threads
+		return true, needsRehash, nil
+
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		err = bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, false, nil
+			}
+			return false, false, err
+		}
+		return true, true, nil
+
+	case len(encoded) == 64:
+		computed := Sha256String(password, legacySalt)
+		if subtle.ConstantTimeCompare([]byte(computed), []byte(encoded)) != 1 {
+			return false, false, nil
+		}
+		return true, true, nil
+
+	default:
+		return false, false, ErrInvalidPasswordHash
+	}
+}
+
+// decodeArgon2idHash 解析Argon2id哈希字符串中的参数、盐值与哈希
+func decodeArgon2idHash(encoded string) (Argon2Params, []byte, []byte, error) {
+	var (
+		params  Argon2Params
+		version int
+		threads uint
+	)
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &threads)
+	if err != nil || threads == 0 || threads > 255 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+	params.Threads = uint8(threads)
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(hash) == 0 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	return params, salt, hash, nil
+}
@@ -1,7 +1,8 @@
 package encrypt
 
 import (
-	"math/rand"
+	"crypto/rand"
+	mrand "math/rand"
 	"time"
 )
 
@@ -14,7 +15,7 @@ import (
 func RandomString(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
-	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	random := mrand.New(mrand.NewSource(time.Now().UnixNano()))
 	result := make([]byte, length)
 	for i := range result {
 		result[i] = charset[random.Intn(len(charset))]
@@ -22,3 +23,14 @@ func RandomString(length int) string {
 
 	return string(result)
 }
+
+// RandomBytes 生成指定长度的密码学安全随机字节
+func RandomBytes(length int) ([]byte, error) {
+	buf := make([]byte, length)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,29 @@
+package encrypt
+
+import "golang.org/x/crypto/scrypt"
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/18 23:30
+// @Desc:	基于scrypt的密钥派生
+
+// ScryptParams scrypt派生参数
+type ScryptParams struct {
+	LogN   uint8 // CPU/内存开销参数 N 的以2为底的对数
+	R      uint8
+	P      uint8
+	KeyLen int
+}
+
+// DefaultScryptParams 默认派生参数
+var DefaultScryptParams = ScryptParams{
+	LogN:   15,
+	R:      8,
+	P:      1,
+	KeyLen: 32,
+}
+
+// ScryptKey 使用scrypt从口令派生密钥
+func ScryptKey(password, salt []byte, params ScryptParams) ([]byte, error) {
+	return scrypt.Key(password, salt, 1<<params.LogN, int(params.R), int(params.P), params.KeyLen)
+}
@@ -0,0 +1,189 @@
+package exporter
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"time"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 17:10
+// @Desc:	口令保护的导出压缩包：单文件ZIP，文件内容先以Deflate压缩，再按WinZip AE-2规范以AES-256加密，
+// 可由7-Zip、WinZip、macOS归档实用工具等直接解压
+
+var ErrEmptyArchivePassword = errors.New("the archive password must not be empty")
+
+const (
+	ArchiveExtension   = "zip"
+	ArchiveContentType = "application/zip"
+
+	winzipAESMethod     = 99     // AES加密条目的压缩方法标识
+	winzipAESExtraID    = 0x9901 // AES扩展字段标识
+	winzipAESVersion    = 2      // AE-2：不写入CRC，完整性由HMAC保证
+	winzipAESStrength   = 3      // AES-256
+	winzipAESKeySize    = 32
+	winzipAESSaltSize   = 16
+	winzipAESIterations = 1000
+	winzipAESMacSize    = 10
+	zipEncryptedFlag    = 0x1
+	zipDescriptorFlag   = 0x8
+	zipVersionAES       = 51
+)
+
+// encryptedArchive 口令保护的单文件ZIP压缩包写入器，写入的数据即压缩包中唯一文件的内容
+type encryptedArchive struct {
+	archive    *zip.Writer
+	header     *zip.FileHeader
+	compressor *flate.Writer
+	cipher     *winzipCipher
+	size       uint64
+}
+
+// NewEncryptedArchive 创建口令保护的压缩包写入器，name 为压缩包中的文件名；
+// Close 写出认证码与压缩包目录，不关闭底层的 io.Writer
+func NewEncryptedArchive(w io.Writer, name, password string) (io.WriteCloser, error) {
+	if password == "" {
+		return nil, ErrEmptyArchivePassword
+	}
+
+	salt := make([]byte, winzipAESSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	keys, err := pbkdf2.Key(sha1.New, password, salt, winzipAESIterations, 2*winzipAESKeySize+2)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(keys[:winzipAESKeySize])
+	if err != nil {
+		return nil, err
+	}
+
+	// 扩展字段：版本、厂商标识、密钥强度与实际压缩方法
+	extra := make([]byte, 11)
+	binary.LittleEndian.PutUint16(extra[0:], winzipAESExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], 7)
+	binary.LittleEndian.PutUint16(extra[4:], winzipAESVersion)
+	copy(extra[6:], "AE")
+	extra[8] = winzipAESStrength
+	binary.LittleEndian.PutUint16(extra[9:], zip.Deflate)
+
+	header := &zip.FileHeader{
+		Name:           name,
+		Method:         winzipAESMethod,
+		Flags:          zipEncryptedFlag | zipDescriptorFlag,
+		CreatorVersion: zipVersionAES,
+		ReaderVersion:  zipVersionAES,
+		Extra:          extra,
+	}
+	header.SetModTime(time.Now())
+
+	archive := zip.NewWriter(w)
+	entry, err := archive.CreateRaw(header)
+	if err != nil {
+		return nil, err
+	}
+
+	// 条目数据以盐值与口令校验值开头
+	if _, err = entry.Write(salt); err != nil {
+		return nil, err
+	}
+	if _, err = entry.Write(keys[2*winzipAESKeySize:]); err != nil {
+		return nil, err
+	}
+
+	ciphertext := &winzipCipher{
+		writer: entry,
+		block:  block,
+		mac:    hmac.New(sha1.New, keys[winzipAESKeySize:2*winzipAESKeySize]),
+		used:   aes.BlockSize,
+		size:   uint64(winzipAESSaltSize + 2),
+	}
+	compressor, err := flate.NewWriter(ciphertext, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptedArchive{
+		archive:    archive,
+		header:     header,
+		compressor: compressor,
+		cipher:     ciphertext,
+	}, nil
+}
+
+func (e *encryptedArchive) Write(p []byte) (int, error) {
+	n, err := e.compressor.Write(p)
+	e.size += uint64(n)
+	return n, err
+}
+
+func (e *encryptedArchive) Close() error {
+	if err := e.compressor.Close(); err != nil {
+		return err
+	}
+	if err := e.cipher.finish(); err != nil {
+		return err
+	}
+
+	// 条目写完后才能确定大小，由数据描述符与目录记录
+	e.header.CompressedSize64 = e.cipher.size
+	e.header.UncompressedSize64 = e.size
+	e.header.CompressedSize = uint32(min(e.header.CompressedSize64, 0xffffffff))
+	e.header.UncompressedSize = uint32(min(e.header.UncompressedSize64, 0xffffffff))
+
+	return e.archive.Close()
+}
+
+// winzipCipher 以小端序递增计数器的AES-CTR加密数据，并对密文计算HMAC-SHA1
+type winzipCipher struct {
+	writer  io.Writer
+	block   cipher.Block
+	mac     hash.Hash
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	used    int
+	size    uint64
+}
+
+func (c *winzipCipher) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	for index, b := range p {
+		if c.used == aes.BlockSize {
+			// 计数器从1开始，按小端序递增
+			for i := range c.counter {
+				c.counter[i]++
+				if c.counter[i] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.stream[:], c.counter[:])
+			c.used = 0
+		}
+		buf[index] = b ^ c.stream[c.used]
+		c.used++
+	}
+
+	c.mac.Write(buf)
+	n, err := c.writer.Write(buf)
+	c.size += uint64(n)
+	return n, err
+}
+
+// finish 写出截断为10字节的认证码
+func (c *winzipCipher) finish() error {
+	n, err := c.writer.Write(c.mac.Sum(nil)[:winzipAESMacSize])
+	c.size += uint64(n)
+	return err
+}
@@ -0,0 +1,98 @@
+package exporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 15:20
+// @Desc:	记录导出框架：按格式逐行写出表头与记录，不在内存或磁盘中缓存完整文件
+
+var ErrUnknownFormat = errors.New("unsupported export format")
+
+const (
+	FormatCSV   = "csv"   // 逗号分隔值
+	FormatJSONL = "jsonl" // JSON Lines，每行一个JSON对象
+	FormatXLSX  = "xlsx"  // Excel 工作簿
+)
+
+// Column 导出列
+type Column struct {
+	Key   string // JSON Lines 中的字段名
+	Title string // CSV 与 XLSX 中的列标题
+}
+
+// Writer 导出写入器
+type Writer interface {
+	// ContentType 导出文件的MIME类型
+	ContentType() string
+	// WriteHeader 写出表头，须在写出记录前调用且仅调用一次
+	WriteHeader(columns []Column) error
+	// WriteRow 写出一条记录，values 与表头的列一一对应
+	WriteRow(values []interface{}) error
+	// Close 写出尾部数据并刷新缓冲，不关闭底层的 io.Writer
+	Close() error
+}
+
+// Extension 返回导出格式对应的文件扩展名，格式为空时使用CSV，不支持的格式返回false
+func Extension(format string) (string, bool) {
+	switch format {
+	case "":
+		return FormatCSV, true
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return format, true
+	}
+	return "", false
+}
+
+// NewWriter 创建指定格式的导出写入器，格式为空时使用CSV
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case "", FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// formatText 将记录的字段值转换为文本：字符串原样输出，数字按十进制输出，其余类型序列化为JSON
+func formatText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	content, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// isNumber 判断字段值是否为数字
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int64, uint:
+		return true
+	}
+	return false
+}
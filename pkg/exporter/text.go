@@ -0,0 +1,101 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 15:35
+// @Desc:	CSV 与 JSON Lines 格式的导出写入器
+
+// csvWriter CSV格式：首行为列标题，其余字段值均转换为文本
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (c *csvWriter) WriteHeader(columns []Column) error {
+	titles := make([]string, len(columns))
+	for index, column := range columns {
+		titles[index] = column.Title
+	}
+	return c.writer.Write(titles)
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for index, value := range values {
+		record[index] = formatText(value)
+	}
+	return c.writer.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// jsonlWriter JSON Lines格式：每条记录为一个按列顺序输出字段的JSON对象，字段值保留原始类型
+type jsonlWriter struct {
+	writer  *bufio.Writer
+	columns []Column
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	return &jsonlWriter{writer: bufio.NewWriter(w)}
+}
+
+func (j *jsonlWriter) ContentType() string {
+	return "application/x-ndjson; charset=utf-8"
+}
+
+func (j *jsonlWriter) WriteHeader(columns []Column) error {
+	j.columns = columns
+	return nil
+}
+
+func (j *jsonlWriter) WriteRow(values []interface{}) error {
+	j.writer.WriteByte('{')
+	for index, column := range j.columns {
+		if index > 0 {
+			j.writer.WriteByte(',')
+		}
+
+		key, err := json.Marshal(column.Key)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if index < len(values) {
+			value = values[index]
+		}
+		content, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		j.writer.Write(key)
+		j.writer.WriteByte(':')
+		j.writer.Write(content)
+	}
+	j.writer.WriteString("}\n")
+
+	// bufio.Writer 在首次写入失败后保留错误，此处统一返回
+	_, err := j.writer.Write(nil)
+	return err
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.writer.Flush()
+}
@@ -0,0 +1,159 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 15:50
+// @Desc:	XLSX 格式的导出写入器：工作簿的固定部件直接写出，工作表按行流式写入压缩包
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+	// 样式0为默认样式，样式1为加粗的表头样式
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`
+
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxWriter XLSX格式：单个工作表，首行为冻结的加粗列标题，字符串以内联字符串写入，数字以数值写入
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	row     int
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{archive: zip.NewWriter(w)}
+}
+
+func (x *xlsxWriter) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+func (x *xlsxWriter) WriteHeader(columns []Column) error {
+	if err := x.open(); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	for index, column := range columns {
+		values[index] = column.Title
+	}
+	return x.writeRow(values, 1)
+}
+
+func (x *xlsxWriter) WriteRow(values []interface{}) error {
+	if err := x.open(); err != nil {
+		return err
+	}
+	return x.writeRow(values, 0)
+}
+
+func (x *xlsxWriter) Close() error {
+	if err := x.open(); err != nil {
+		return err
+	}
+
+	x.sheet.WriteString(xlsxSheetEnd)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}
+
+// open 写出工作簿的固定部件并开始写入工作表，工作表须为压缩包中的最后一个文件
+func (x *xlsxWriter) open() error {
+	if x.sheet != nil {
+		return nil
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		file, err := x.archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	file, err := x.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(file)
+	_, err = x.sheet.WriteString(xlsxSheetStart)
+	return err
+}
+
+// writeRow 写出一行单元格，style 为单元格样式序号
+func (x *xlsxWriter) writeRow(values []interface{}, style int) error {
+	x.row++
+	row := strconv.Itoa(x.row)
+
+	x.sheet.WriteString(`<row r="` + row + `">`)
+	for index, value := range values {
+		ref := columnName(index) + row
+		attrs := ` r="` + ref + `"`
+		if style > 0 {
+			attrs += ` s="` + strconv.Itoa(style) + `"`
+		}
+
+		text := formatText(value)
+		switch {
+		case text == "":
+			continue
+		case isNumber(value):
+			x.sheet.WriteString(`<c` + attrs + `><v>` + text + `</v></c>`)
+		default:
+			x.sheet.WriteString(`<c` + attrs + ` t="inlineStr"><is><t xml:space="preserve">`)
+			// EscapeText 会将XML中不允许出现的控制字符替换为U+FFFD
+			if err := xml.EscapeText(x.sheet, []byte(text)); err != nil {
+				return err
+			}
+			x.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+// columnName 将从0开始的列序号转换为 A、B、…、Z、AA 形式的列名
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,280 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 11:05
+// @Desc:	Bitwarden JSON 导出格式，支持明文导出与口令保护的加密导出
+
+func init() {
+	Register(&bitwardenFormat{})
+}
+
+const (
+	bitwardenKdfPBKDF2   = 0
+	bitwardenKdfArgon2id = 1
+
+	bitwardenTypeLogin    = 1
+	bitwardenTypeNote     = 2
+	bitwardenTypeCard     = 3
+	bitwardenTypeIdentity = 4
+	bitwardenTypeSSHKey   = 5
+
+	// 密钥派生参数上限，避免恶意文件耗尽资源
+	bitwardenMaxIterations  = 10000000
+	bitwardenMaxArgonTime   = 100
+	bitwardenMaxArgonMemory = 1024 // MiB
+	bitwardenMaxArgonLanes  = 16
+)
+
+// bitwardenExport 导出文件的顶层结构
+type bitwardenExport struct {
+	Encrypted         bool   `json:"encrypted"`
+	PasswordProtected bool   `json:"passwordProtected"`
+	Salt              string `json:"salt"`
+	KdfType           int    `json:"kdfType"`
+	KdfIterations     int    `json:"kdfIterations"`
+	KdfMemory         int    `json:"kdfMemory"`
+	KdfParallelism    int    `json:"kdfParallelism"`
+	KeyValidation     string `json:"encKeyValidation_DO_NOT_EDIT"`
+	Data              string `json:"data"`
+
+	Folders []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []bitwardenItem `json:"items"`
+}
+
+// bitwardenItem 密码库条目
+type bitwardenItem struct {
+	Type        int    `json:"type"`
+	Name        string `json:"name"`
+	Notes       string `json:"notes"`
+	FolderID    string `json:"folderId"`
+	DeletedDate string `json:"deletedDate"`
+	Fields      []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"fields"`
+	Login *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Totp     string `json:"totp"`
+		Uris     []struct {
+			Uri string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	SSHKey *struct {
+		PrivateKey     string `json:"privateKey"`
+		PublicKey      string `json:"publicKey"`
+		KeyFingerprint string `json:"keyFingerprint"`
+	} `json:"sshKey"`
+}
+
+// bitwardenFormat Bitwarden JSON 导出
+type bitwardenFormat struct{}
+
+func (f *bitwardenFormat) Name() string { return "bitwarden" }
+
+func (f *bitwardenFormat) Detect(filename string, data []byte) bool {
+	if !strings.HasSuffix(strings.ToLower(filename), ".json") {
+		return false
+	}
+
+	var probe struct {
+		Encrypted *bool `json:"encrypted"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Encrypted != nil
+}
+
+func (f *bitwardenFormat) Parse(data []byte, options Options) ([]Record, error) {
+	var export bitwardenExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	if export.Encrypted {
+		if !export.PasswordProtected {
+			// 使用账户密钥加密的导出只能在 Bitwarden 客户端中解密
+			return nil, ErrUnsupportedEncrypt
+		}
+
+		plaintext, err := decryptBitwardenExport(&export, options.Password)
+		if err != nil {
+			return nil, err
+		}
+		export = bitwardenExport{}
+		if err = json.Unmarshal(plaintext, &export); err != nil {
+			return nil, ErrInvalidFile
+		}
+	}
+
+	folders := make(map[string]string)
+	for _, folder := range export.Folders {
+		folders[folder.ID] = folder.Name
+	}
+
+	records := make([]Record, 0, len(export.Items))
+	for _, item := range export.Items {
+		record := Record{
+			Title: item.Name,
+			Group: folders[item.FolderID],
+			Notes: item.Notes,
+		}
+		if record.Group == "" {
+			record.Group = "Bitwarden"
+		}
+		for _, field := range item.Fields {
+			record.Fields = append(record.Fields, Field{Name: field.Name, Value: field.Value})
+		}
+
+		switch item.Type {
+		case bitwardenTypeLogin:
+			if item.Login != nil {
+				record.Username = item.Login.Username
+				record.Password = item.Login.Password
+				if len(item.Login.Uris) > 0 {
+					record.URL = item.Login.Uris[0].Uri
+				}
+			}
+		case bitwardenTypeNote:
+			// 安全笔记根据自定义字段推断类别，无法识别时跳过
+		case bitwardenTypeSSHKey:
+			record.Hint = KindSecret
+			if item.SSHKey != nil {
+				record.Username = item.SSHKey.PublicKey
+				record.Password = item.SSHKey.PrivateKey
+			}
+		case bitwardenTypeCard:
+			record.Skip = "payment cards are not supported"
+		case bitwardenTypeIdentity:
+			record.Skip = "identities are not supported"
+		default:
+			record.Skip = "unsupported item type"
+		}
+		if item.DeletedDate != "" {
+			record.Skip = "the item is in the trash"
+		}
+
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decryptBitwardenExport 使用导出口令派生密钥，校验密钥后解密导出数据
+func decryptBitwardenExport(export *bitwardenExport, password string) ([]byte, error) {
+	if password == "" {
+		return nil, ErrPasswordRequired
+	}
+
+	var (
+		masterKey []byte
+		err       error
+	)
+	switch export.KdfType {
+	case bitwardenKdfPBKDF2:
+		if export.KdfIterations <= 0 || export.KdfIterations > bitwardenMaxIterations {
+			return nil, ErrInvalidFile
+		}
+		masterKey, err = pbkdf2.Key(sha256.New, password, []byte(export.Salt), export.KdfIterations, 32)
+		if err != nil {
+			return nil, err
+		}
+	case bitwardenKdfArgon2id:
+		if export.KdfIterations <= 0 || export.KdfIterations > bitwardenMaxArgonTime ||
+			export.KdfMemory <= 0 || export.KdfMemory > bitwardenMaxArgonMemory ||
+			export.KdfParallelism <= 0 || export.KdfParallelism > bitwardenMaxArgonLanes {
+			return nil, ErrInvalidFile
+		}
+		salt := sha256.Sum256([]byte(export.Salt))
+		masterKey = argon2.IDKey([]byte(password), salt[:], uint32(export.KdfIterations),
+			uint32(export.KdfMemory)*1024, uint8(export.KdfParallelism), 32)
+	default:
+		return nil, ErrUnsupportedEncrypt
+	}
+
+	encKey, err := hkdf.Expand(sha256.New, masterKey, "enc", 32)
+	if err != nil {
+		return nil, err
+	}
+	macKey, err := hkdf.Expand(sha256.New, masterKey, "mac", 32)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = decryptEncString(export.KeyValidation, encKey, macKey); err != nil {
+		return nil, err
+	}
+	plaintext, err := decryptEncString(export.Data, encKey, macKey)
+	if err != nil {
+		if err == ErrInvalidPassword {
+			// 校验值已通过，数据部分认证失败说明文件被篡改
+			return nil, ErrInvalidFile
+		}
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+// decryptEncString 解密 “2.iv|ct|mac” 格式的加密字符串（AES-256-CBC + HMAC-SHA256），认证失败视为口令错误
+func decryptEncString(value string, encKey, macKey []byte) ([]byte, error) {
+	encType, body, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, ErrInvalidFile
+	}
+	if encType != "2" {
+		return nil, ErrUnsupportedEncrypt
+	}
+
+	parts := strings.Split(body, "|")
+	if len(parts) != 3 {
+		return nil, ErrInvalidFile
+	}
+	var decoded [3][]byte
+	for index, part := range parts {
+		raw, err := base64.StdEncoding.DecodeString(part)
+		if err != nil {
+			return nil, ErrInvalidFile
+		}
+		decoded[index] = raw
+	}
+	iv, ciphertext, tag := decoded[0], decoded[1], decoded[2]
+
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	if !hmac.Equal(mac.Sum(nil), tag) {
+		return nil, ErrInvalidPassword
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, ErrInvalidFile
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// 去除PKCS#7填充
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, ErrInvalidFile
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
@@ -0,0 +1,249 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 11:05
+// @Desc:	浏览器与 LastPass 导出的CSV格式：Chrome、Firefox 与 LastPass
+
+func init() {
+	Register(&lastpassFormat{})
+	Register(&chromeFormat{})
+	Register(&firefoxFormat{})
+}
+
+// csvTable 带表头的CSV数据
+type csvTable struct {
+	header map[string]int
+	rows   [][]string
+	lines  []int // 各数据行的起始行号
+}
+
+// get 按列名读取单元格，列不存在时返回空字符串
+func (t *csvTable) get(row []string, column string) string {
+	index, ok := t.header[column]
+	if !ok || index >= len(row) {
+		return ""
+	}
+	return row[index]
+}
+
+// readCSV 读取带表头的CSV数据，表头统一为小写
+func readCSV(data []byte) (*csvTable, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	table := &csvTable{header: make(map[string]int)}
+	for index, column := range header {
+		table.header[strings.ToLower(strings.TrimSpace(column))] = index
+	}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidFile
+		}
+
+		line, _ := reader.FieldPos(0)
+		table.rows = append(table.rows, row)
+		table.lines = append(table.lines, line)
+	}
+	return table, nil
+}
+
+// readCSVWithColumns 读取带表头的CSV数据，表头缺少任一指定列时返回 ErrInvalidFile
+func readCSVWithColumns(data []byte, columns ...string) (*csvTable, error) {
+	table, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range columns {
+		if _, ok := table.header[column]; !ok {
+			return nil, ErrInvalidFile
+		}
+	}
+	return table, nil
+}
+
+// hasColumns 判断CSV数据首行是否包含全部指定列
+func hasColumns(filename string, data []byte, columns ...string) bool {
+	if !strings.HasSuffix(strings.ToLower(filename), ".csv") {
+		return false
+	}
+
+	line, _, _ := bytes.Cut(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), []byte("\n"))
+	_, err := readCSVWithColumns(line, columns...)
+	return err == nil
+}
+
+// hostname 提取URL中的主机名，无法解析时返回原值
+func hostname(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Hostname() != "" {
+		return parsed.Hostname()
+	}
+	return rawURL
+}
+
+// chromeFormat Chrome（及 Edge 等 Chromium 内核浏览器）导出的密码CSV
+type chromeFormat struct{}
+
+var chromeColumns = []string{"name", "url", "username", "password"}
+
+func (f *chromeFormat) Name() string { return "chrome" }
+
+func (f *chromeFormat) Detect(filename string, data []byte) bool {
+	return hasColumns(filename, data, chromeColumns...)
+}
+
+func (f *chromeFormat) Parse(data []byte, options Options) ([]Record, error) {
+	table, err := readCSVWithColumns(data, chromeColumns...)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(table.rows))
+	for index, row := range table.rows {
+		records = append(records, Record{
+			Line:     table.lines[index],
+			Title:    table.get(row, "name"),
+			Group:    "Chrome",
+			URL:      table.get(row, "url"),
+			Username: table.get(row, "username"),
+			Password: table.get(row, "password"),
+			Notes:    table.get(row, "note"),
+			Hint:     KindAccount,
+		})
+	}
+	return records, nil
+}
+
+// firefoxFormat Firefox 导出的登录信息CSV
+type firefoxFormat struct{}
+
+var firefoxColumns = []string{"url", "username", "password", "httprealm", "formactionorigin"}
+
+func (f *firefoxFormat) Name() string { return "firefox" }
+
+func (f *firefoxFormat) Detect(filename string, data []byte) bool {
+	return hasColumns(filename, data, firefoxColumns...)
+}
+
+func (f *firefoxFormat) Parse(data []byte, options Options) ([]Record, error) {
+	table, err := readCSVWithColumns(data, firefoxColumns...)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(table.rows))
+	for index, row := range table.rows {
+		rawURL := table.get(row, "url")
+		records = append(records, Record{
+			Line:     table.lines[index],
+			Title:    hostname(rawURL),
+			Group:    "Firefox",
+			URL:      rawURL,
+			Username: table.get(row, "username"),
+			Password: table.get(row, "password"),
+			Hint:     KindAccount,
+		})
+	}
+	return records, nil
+}
+
+// lastpassFormat LastPass 导出的CSV，安全笔记以 http://sn 作为URL
+type lastpassFormat struct{}
+
+var lastpassColumns = []string{"url", "username", "password", "extra", "name", "grouping"}
+
+func (f *lastpassFormat) Name() string { return "lastpass" }
+
+func (f *lastpassFormat) Detect(filename string, data []byte) bool {
+	return hasColumns(filename, data, lastpassColumns...)
+}
+
+func (f *lastpassFormat) Parse(data []byte, options Options) ([]Record, error) {
+	table, err := readCSVWithColumns(data, lastpassColumns...)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(table.rows))
+	for index, row := range table.rows {
+		record := Record{
+			Line:     table.lines[index],
+			Title:    table.get(row, "name"),
+			Group:    strings.ReplaceAll(table.get(row, "grouping"), "\\", "/"),
+			URL:      table.get(row, "url"),
+			Username: table.get(row, "username"),
+			Password: table.get(row, "password"),
+			Notes:    table.get(row, "extra"),
+			Hint:     KindAccount,
+		}
+		if record.Group == "" {
+			record.Group = "LastPass"
+		}
+		if record.URL == "http://sn" {
+			parseLastpassNote(&record)
+		}
+
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// parseLastpassNote 解析安全笔记：带 NoteType 的笔记由 “键:值” 行组成，服务器与数据库映射为主机，
+// SSH密钥映射为密钥，其余类型跳过；普通笔记没有可导入的凭据，同样跳过
+func parseLastpassNote(record *Record) {
+	record.URL = ""
+	record.Hint = ""
+
+	lines := strings.Split(strings.ReplaceAll(record.Notes, "\r\n", "\n"), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "NoteType:") {
+		record.Skip = "secure notes are not supported"
+		return
+	}
+	noteType := strings.TrimPrefix(lines[0], "NoteType:")
+
+	var notes []string
+	for index := 1; index < len(lines); index++ {
+		name, value, ok := strings.Cut(lines[index], ":")
+		if !ok {
+			continue
+		}
+		if name == "Notes" {
+			// 笔记内容为最后一个字段，可能跨越多行
+			notes = append([]string{value}, lines[index+1:]...)
+			break
+		}
+		record.Fields = append(record.Fields, Field{Name: name, Value: value})
+	}
+	record.Notes = strings.Join(notes, "\n")
+	record.Username = record.Field("username")
+	record.Password = record.Field("password")
+
+	switch noteType {
+	case "Server", "Database":
+		record.Hint = KindHost
+	case "SSH Key":
+		record.Hint = KindSecret
+		record.Username = record.Field("public key")
+		record.Password = record.Field("private key")
+	default:
+		record.Skip = "unsupported secure note type: " + noteType
+	}
+}
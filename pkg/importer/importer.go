@@ -0,0 +1,220 @@
+package importer
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 11:05
+// @Desc:	第三方密码库导入框架：各格式注册识别与解析实现，解析结果统一为 Record，再按类别映射为账号、密钥或主机
+
+var (
+	ErrUnknownFormat      = errors.New("unrecognized import file format")
+	ErrInvalidFile        = errors.New("the import file is malformed")
+	ErrPasswordRequired   = errors.New("the import file is encrypted, a password is required")
+	ErrInvalidPassword    = errors.New("invalid password for the encrypted import file")
+	ErrUnsupportedEncrypt = errors.New("unsupported encryption of the import file")
+)
+
+// Kind 记录的导入类别
+type Kind string
+
+const (
+	KindAccount Kind = "account" // 网站登录凭据
+	KindSecret  Kind = "secret"  // API密钥等密钥对
+	KindHost    Kind = "host"    // 服务器
+)
+
+// Field 自定义字段
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Record 各格式解析后的通用记录
+type Record struct {
+	Title    string
+	Group    string // 所在文件夹、分组或保险库
+	URL      string
+	Username string
+	Password string
+	Notes    string
+	Fields   []Field
+	Hint     Kind   // 源格式明确给出的类别，为空时由字段推断
+	Skip     string // 非空时该记录不导入，值为跳过原因
+	Line     int    // 记录在源文件中的起始行号，仅CSV格式提供
+}
+
+// Options 解析选项
+type Options struct {
+	Password string // 加密导出文件的口令或主密码
+	KeyFile  []byte // KeePass 密钥文件
+}
+
+// Format 导入格式
+type Format interface {
+	// Name 格式名称
+	Name() string
+	// Detect 根据文件名与内容判断是否为该格式
+	Detect(filename string, data []byte) bool
+	// Parse 解析文件内容
+	Parse(data []byte, options Options) ([]Record, error)
+}
+
+var formats []Format
+
+// Register 注册导入格式，自动识别时按注册顺序匹配
+func Register(format Format) {
+	formats = append(formats, format)
+}
+
+// Formats 全部已注册的格式名称
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for _, format := range formats {
+		names = append(names, format.Name())
+	}
+	return names
+}
+
+// Resolve 按名称查找格式，名称为空时根据文件名与内容自动识别
+func Resolve(name, filename string, data []byte) (Format, error) {
+	for _, format := range formats {
+		if name != "" && format.Name() == name {
+			return format, nil
+		}
+		if name == "" && format.Detect(filename, data) {
+			return format, nil
+		}
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// Field 按名称查找自定义字段的值，名称比较忽略大小写、空白与分隔符
+func (r *Record) Field(names ...string) string {
+	for _, name := range names {
+		key := normalizeName(name)
+		for _, field := range r.Fields {
+			if field.Value != "" && normalizeName(field.Name) == key {
+				return field.Value
+			}
+		}
+	}
+	return ""
+}
+
+var (
+	// 可作为主机地址的字段
+	hostFieldNames = []string{"ip address", "ip", "address", "hostname", "host", "server"}
+
+	// 密钥对的标识与密钥字段，按优先级排列
+	keyIDFieldNames     = []string{"access key id", "key id", "api key id", "client id", "app id", "app key", "access key", "public key", "username"}
+	keySecretFieldNames = []string{"secret access key", "access key secret", "secret key", "api secret", "client secret", "app secret", "secret", "api key", "credential", "access token", "token", "private key"}
+
+	// 主机协议及其默认端口
+	hostSchemes = map[string]string{
+		"ssh":    "22",
+		"sftp":   "22",
+		"telnet": "23",
+		"rdp":    "3389",
+		"vnc":    "5900",
+	}
+)
+
+// Classify 判断记录的导入类别：优先使用源格式给出的类别，其次按URL协议与字段推断；
+// 无法识别时返回空字符串
+func Classify(record *Record) Kind {
+	if record.Hint != "" {
+		return record.Hint
+	}
+
+	if parsed, err := url.Parse(record.URL); err == nil && hostSchemes[strings.ToLower(parsed.Scheme)] != "" {
+		return KindHost
+	}
+	if record.Field(hostFieldNames...) != "" {
+		return KindHost
+	}
+	if record.Field(keySecretFieldNames...) != "" {
+		return KindSecret
+	}
+	if record.Username != "" || record.Password != "" {
+		return KindAccount
+	}
+
+	return ""
+}
+
+// KeyPair 提取密钥对的标识与密钥，缺少对应字段时使用用户名与密码
+func KeyPair(record *Record) (keyID, keySecret string) {
+	keyID = record.Field(keyIDFieldNames...)
+	if keyID == "" {
+		keyID = record.Username
+	}
+	keySecret = record.Field(keySecretFieldNames...)
+	if keySecret == "" {
+		keySecret = record.Password
+	}
+	return keyID, keySecret
+}
+
+// HostEndpoint 提取主机地址、端口与服务名称，优先解析主机协议的URL，其次使用地址与端口字段
+func HostEndpoint(record *Record) (address, port, service string) {
+	for _, raw := range []string{record.URL, record.Field("url")} {
+		if raw != "" && !strings.Contains(raw, "://") {
+			// 服务器条目的URL字段通常只填写地址
+			raw = "//" + raw
+		}
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+
+		scheme := strings.ToLower(parsed.Scheme)
+		if defaultPort, ok := hostSchemes[scheme]; ok {
+			port = parsed.Port()
+			if port == "" {
+				port = defaultPort
+			}
+			return parsed.Hostname(), port, scheme
+		}
+		if address == "" {
+			address = parsed.Hostname()
+		}
+	}
+
+	if value := record.Field(hostFieldNames...); value != "" {
+		address = value
+		if host, hostPort, err := net.SplitHostPort(value); err == nil {
+			address, port = host, hostPort
+		}
+	}
+	if value := record.Field("port"); value != "" {
+		port = value
+	}
+	if port != "" {
+		service = "ssh"
+		for scheme, defaultPort := range hostSchemes {
+			if defaultPort == port && scheme != "sftp" {
+				service = scheme
+			}
+		}
+	}
+
+	return address, port, service
+}
+
+// normalizeName 统一字段名称：转为小写并去除空白与分隔符
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '_', '-', '.', ':':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
@@ -0,0 +1,84 @@
+package importer
+
+import (
+	"cyber-life/pkg/kdbx"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 11:05
+// @Desc:	KeePass KDBX 4 数据库格式：分组路径作为分组，回收站中的条目跳过
+
+func init() {
+	Register(&keepassFormat{})
+}
+
+// keepassFormat KeePass KDBX 4 数据库
+type keepassFormat struct{}
+
+func (f *keepassFormat) Name() string { return "keepass" }
+
+func (f *keepassFormat) Detect(filename string, data []byte) bool {
+	return kdbx.IsKDBX(data)
+}
+
+func (f *keepassFormat) Parse(data []byte, options Options) ([]Record, error) {
+	database, err := kdbx.Open(data, options.Password, options.KeyFile)
+	if err != nil {
+		if errors.Is(err, kdbx.ErrInvalidCredentials) && options.Password == "" && options.KeyFile == nil {
+			return nil, ErrPasswordRequired
+		}
+		return nil, err
+	}
+
+	var records []Record
+	collectKeepassGroup(database, database.Root, nil, false, &records)
+	return records, nil
+}
+
+// collectKeepassGroup 递归收集分组中的条目，path 为相对根分组的分组路径，根分组下的条目以根分组名称作为分组
+func collectKeepassGroup(database *kdbx.Database, group *kdbx.Group, path []string, inRecycleBin bool, records *[]Record) {
+	groupName := group.Name
+	if len(path) > 0 {
+		groupName = strings.Join(path, "/")
+	}
+	if groupName == "" {
+		groupName = "KeePass"
+	}
+	inRecycleBin = inRecycleBin || (database.RecycleBinUUID != "" && group.UUID == database.RecycleBinUUID)
+
+	for _, entry := range group.Entries {
+		record := Record{
+			Title:    entry.Title,
+			Group:    groupName,
+			URL:      entry.URL,
+			Username: entry.UserName,
+			Password: entry.Password,
+			Notes:    entry.Notes,
+		}
+		if record.Title == "" {
+			record.Title = hostname(entry.URL)
+		}
+
+		names := make([]string, 0, len(entry.Fields))
+		for name := range entry.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			record.Fields = append(record.Fields, Field{Name: name, Value: entry.Fields[name]})
+		}
+
+		if inRecycleBin {
+			record.Skip = "the entry is in the recycle bin"
+		}
+		*records = append(*records, record)
+	}
+
+	for _, child := range group.Groups {
+		collectKeepassGroup(database, child, append(path[:len(path):len(path)], child.Name), inRecycleBin, records)
+	}
+}
@@ -0,0 +1,206 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 11:05
+// @Desc:	1Password 1PUX 导出格式：ZIP 压缩包中的 export.data 文档
+
+func init() {
+	Register(&onePasswordFormat{})
+}
+
+// export.data 的大小上限
+const onePasswordMaxDataSize = 64 << 20
+
+// 1Password 条目类别与导入类别的对应关系，未列出的类别跳过，值为空的类别按字段推断
+var onePasswordCategories = map[string]Kind{
+	"001": KindAccount, // Login
+	"005": KindAccount, // Password
+	"102": KindHost,    // Database
+	"110": KindHost,    // Server
+	"112": KindSecret,  // API Credential
+	"114": KindSecret,  // SSH Key
+	"003": "",          // Secure Note
+	"109": "",          // Wireless Router
+	"111": "",          // Email Account
+}
+
+// onePasswordExport export.data 的结构
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+// onePasswordItem 条目
+type onePasswordItem struct {
+	State        string `json:"state"`
+	CategoryUuid string `json:"categoryUuid"`
+	Overview     struct {
+		Title string `json:"title"`
+		Url   string `json:"url"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Name        string `json:"name"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+		Sections   []struct {
+			Fields []struct {
+				Title string                     `json:"title"`
+				ID    string                     `json:"id"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+	} `json:"details"`
+}
+
+// onePasswordFormat 1Password 1PUX 导出
+type onePasswordFormat struct{}
+
+func (f *onePasswordFormat) Name() string { return "1password" }
+
+func (f *onePasswordFormat) Detect(filename string, data []byte) bool {
+	return strings.HasSuffix(strings.ToLower(filename), ".1pux") && bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+func (f *onePasswordFormat) Parse(data []byte, options Options) ([]Record, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+	file, err := archive.Open("export.data")
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, onePasswordMaxDataSize+1))
+	if err != nil || len(content) > onePasswordMaxDataSize {
+		return nil, ErrInvalidFile
+	}
+
+	var export onePasswordExport
+	if err = json.Unmarshal(content, &export); err != nil {
+		return nil, ErrInvalidFile
+	}
+
+	var records []Record
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, item := range vault.Items {
+				records = append(records, parseOnePasswordItem(vault.Attrs.Name, &item))
+			}
+		}
+	}
+	return records, nil
+}
+
+// parseOnePasswordItem 将条目转换为通用记录，分区字段展开为自定义字段
+func parseOnePasswordItem(vault string, item *onePasswordItem) Record {
+	record := Record{
+		Title:    item.Overview.Title,
+		Group:    vault,
+		URL:      item.Overview.Url,
+		Password: item.Details.Password,
+		Notes:    item.Details.NotesPlain,
+	}
+	if record.Group == "" {
+		record.Group = "1Password"
+	}
+
+	for _, field := range item.Details.LoginFields {
+		switch field.Designation {
+		case "username":
+			record.Username = field.Value
+		case "password":
+			record.Password = field.Value
+		}
+	}
+	for _, section := range item.Details.Sections {
+		for _, field := range section.Fields {
+			name := field.Title
+			if name == "" {
+				name = field.ID
+			}
+			record.Fields = append(record.Fields, onePasswordFieldValues(name, field.Value)...)
+		}
+	}
+
+	if record.Username == "" {
+		record.Username = record.Field("username")
+	}
+	if record.Password == "" {
+		record.Password = record.Field("password")
+	}
+
+	hint, ok := onePasswordCategories[item.CategoryUuid]
+	switch {
+	case item.State == "archived":
+		record.Skip = "the item is archived"
+	case !ok:
+		record.Skip = "unsupported item category: " + item.CategoryUuid
+	default:
+		record.Hint = hint
+	}
+	if item.CategoryUuid == "114" {
+		record.Username = record.Field("public key")
+		record.Password = record.Field("private key")
+	}
+
+	return record
+}
+
+// onePasswordFieldValues 读取分区字段的值，值对象以类型为键，不支持的类型忽略
+func onePasswordFieldValues(name string, value map[string]json.RawMessage) []Field {
+	for _, kind := range []string{"string", "concealed", "url", "totp", "phone"} {
+		if raw, ok := value[kind]; ok {
+			var text string
+			if json.Unmarshal(raw, &text) == nil && text != "" {
+				return []Field{{Name: name, Value: text}}
+			}
+			return nil
+		}
+	}
+
+	if raw, ok := value["email"]; ok {
+		var email struct {
+			Address string `json:"email_address"`
+		}
+		if json.Unmarshal(raw, &email) == nil && email.Address != "" {
+			return []Field{{Name: name, Value: email.Address}}
+		}
+	}
+	if raw, ok := value["sshKey"]; ok {
+		var sshKey struct {
+			PrivateKey string `json:"privateKey"`
+			Metadata   struct {
+				PublicKey string `json:"publicKey"`
+			} `json:"metadata"`
+		}
+		if json.Unmarshal(raw, &sshKey) == nil && sshKey.PrivateKey != "" {
+			return []Field{
+				{Name: "private key", Value: sshKey.PrivateKey},
+				{Name: "public key", Value: sshKey.Metadata.PublicKey},
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package kdbx
+
+import (
+	"encoding/binary"
+	"golang.org/x/crypto/blake2b"
+	"math/bits"
+	"sync"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 09:20
+// @Desc:	Argon2d密钥派生（RFC 9106），x/crypto 仅提供 Argon2i 与 Argon2id
+
+const (
+	argon2Version    = 0x13
+	argon2SyncPoints = 4
+	argon2BlockWords = 128
+)
+
+type argon2Block [argon2BlockWords]uint64
+
+// argon2dKey 使用Argon2d派生密钥，memory 单位为KB
+func argon2dKey(password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	h0 := argon2InitHash(password, salt, secret, data, time, memory, uint32(threads), keyLen)
+
+	lanes := uint32(threads)
+	memory = memory / (argon2SyncPoints * lanes) * (argon2SyncPoints * lanes)
+	if memory < 2*argon2SyncPoints*lanes {
+		memory = 2 * argon2SyncPoints * lanes
+	}
+
+	blocks := argon2InitBlocks(&h0, memory, lanes)
+	argon2ProcessBlocks(blocks, time, memory, lanes)
+	return argon2ExtractKey(blocks, memory, lanes, keyLen)
+}
+
+// argon2InitHash 计算初始哈希H0，末尾预留8字节供生成首块时填写块序号与通道号
+func argon2InitHash(password, salt, secret, data []byte, time, memory, threads, keyLen uint32) [blake2b.Size + 8]byte {
+	var (
+		h0     [blake2b.Size + 8]byte
+		params [24]byte
+		length [4]byte
+	)
+
+	hash, _ := blake2b.New512(nil)
+
+	binary.LittleEndian.PutUint32(params[0:4], threads)
+	binary.LittleEndian.PutUint32(params[4:8], keyLen)
+	binary.LittleEndian.PutUint32(params[8:12], memory)
+	binary.LittleEndian.PutUint32(params[12:16], time)
+	binary.LittleEndian.PutUint32(params[16:20], argon2Version)
+	binary.LittleEndian.PutUint32(params[20:24], 0) // 0 表示 Argon2d
+	hash.Write(params[:])
+
+	for _, input := range [][]byte{password, salt, secret, data} {
+		binary.LittleEndian.PutUint32(length[:], uint32(len(input)))
+		hash.Write(length[:])
+		hash.Write(input)
+	}
+
+	hash.Sum(h0[:0])
+	return h0
+}
+
+// argon2InitBlocks 分配内存块并生成每个通道的前两个块
+func argon2InitBlocks(h0 *[blake2b.Size + 8]byte, memory, lanes uint32) []argon2Block {
+	var block0 [1024]byte
+
+	blocks := make([]argon2Block, memory)
+	for lane := uint32(0); lane < lanes; lane++ {
+		j := lane * (memory / lanes)
+		binary.LittleEndian.PutUint32(h0[blake2b.Size+4:], lane)
+
+		for i := uint32(0); i < 2; i++ {
+			binary.LittleEndian.PutUint32(h0[blake2b.Size:], i)
+			argon2Hash(block0[:], h0[:])
+			for k := range blocks[j+i] {
+				blocks[j+i][k] = binary.LittleEndian.Uint64(block0[k*8:])
+			}
+		}
+	}
+
+	return blocks
+}
+
+// argon2ProcessBlocks 按轮次与分片填充全部内存块，同一分片内各通道并行计算
+func argon2ProcessBlocks(blocks []argon2Block, time, memory, lanes uint32) {
+	laneLength := memory / lanes
+	segmentLength := laneLength / argon2SyncPoints
+
+	processSegment := func(pass, slice, lane uint32, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		index := uint32(0)
+		if pass == 0 && slice == 0 {
+			index = 2 // 前两个块已生成
+		}
+
+		offset := lane*laneLength + slice*segmentLength + index
+		for index < segmentLength {
+			prev := offset - 1
+			if index == 0 && slice == 0 {
+				prev += laneLength // 通道内的最后一个块
+			}
+
+			// Argon2d 使用前一个块的首个字作为伪随机数，引用位置依赖数据
+			ref := argon2IndexAlpha(blocks[prev][0], laneLength, segmentLength, lanes, pass, slice, lane, index)
+			argon2ProcessBlock(&blocks[offset], &blocks[prev], &blocks[ref])
+
+			index, offset = index+1, offset+1
+		}
+	}
+
+	for pass := uint32(0); pass < time; pass++ {
+		for slice := uint32(0); slice < argon2SyncPoints; slice++ {
+			var wg sync.WaitGroup
+			for lane := uint32(0); lane < lanes; lane++ {
+				wg.Add(1)
+				go processSegment(pass, slice, lane, &wg)
+			}
+			wg.Wait()
+		}
+	}
+}
+
+// argon2ExtractKey 异或各通道的最后一个块并生成指定长度的输出
+func argon2ExtractKey(blocks []argon2Block, memory, lanes, keyLen uint32) []byte {
+	laneLength := memory / lanes
+	for lane := uint32(0); lane < lanes-1; lane++ {
+		for i, v := range blocks[(lane*laneLength)+laneLength-1] {
+			blocks[memory-1][i] ^= v
+		}
+	}
+
+	var block [1024]byte
+	for i, v := range blocks[memory-1] {
+		binary.LittleEndian.PutUint64(block[i*8:], v)
+	}
+
+	key := make([]byte, keyLen)
+	argon2Hash(key, block[:])
+	return key
+}
+
+// argon2IndexAlpha 根据伪随机数计算引用块的位置
+func argon2IndexAlpha(random uint64, laneLength, segmentLength, lanes, pass, slice, lane, index uint32) uint32 {
+	refLane := uint32(random>>32) % lanes
+	if pass == 0 && slice == 0 {
+		refLane = lane
+	}
+
+	area, start := 3*segmentLength, ((slice+1)%argon2SyncPoints)*segmentLength
+	if lane == refLane {
+		area += index
+	}
+	if pass == 0 {
+		area, start = slice*segmentLength, 0
+		if slice == 0 || lane == refLane {
+			area += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		area--
+	}
+
+	p := random & 0xFFFFFFFF
+	p = (p * p) >> 32
+	p = (p * uint64(area)) >> 32
+	return refLane*laneLength + uint32((uint64(start)+uint64(area)-(p+1))%uint64(laneLength))
+}
+
+// argon2ProcessBlock 压缩函数G，结果与目标块原内容异或
+func argon2ProcessBlock(out, in1, in2 *argon2Block) {
+	var t argon2Block
+	for i := range t {
+		t[i] = in1[i] ^ in2[i]
+	}
+
+	// 先按行、再按列应用 BLAKE2b 轮函数
+	for i := 0; i < argon2BlockWords; i += 16 {
+		argon2Blamka(
+			&t[i+0], &t[i+1], &t[i+2], &t[i+3], &t[i+4], &t[i+5], &t[i+6], &t[i+7],
+			&t[i+8], &t[i+9], &t[i+10], &t[i+11], &t[i+12], &t[i+13], &t[i+14], &t[i+15],
+		)
+	}
+	for i := 0; i < argon2BlockWords/8; i += 2 {
+		argon2Blamka(
+			&t[i], &t[i+1], &t[16+i], &t[16+i+1], &t[32+i], &t[32+i+1], &t[48+i], &t[48+i+1],
+			&t[64+i], &t[64+i+1], &t[80+i], &t[80+i+1], &t[96+i], &t[96+i+1], &t[112+i], &t[112+i+1],
+		)
+	}
+
+	for i := range out {
+		out[i] ^= in1[i] ^ in2[i] ^ t[i]
+	}
+}
+
+// argon2Blamka 作用于16个字的 BLAKE2b 轮函数（乘法加强版）
+func argon2Blamka(t00, t01, t02, t03, t04, t05, t06, t07, t08, t09, t10, t11, t12, t13, t14, t15 *uint64) {
+	argon2GB(t00, t04, t08, t12)
+	argon2GB(t01, t05, t09, t13)
+	argon2GB(t02, t06, t10, t14)
+	argon2GB(t03, t07, t11, t15)
+	argon2GB(t00, t05, t10, t15)
+	argon2GB(t01, t06, t11, t12)
+	argon2GB(t02, t07, t08, t13)
+	argon2GB(t03, t04, t09, t14)
+}
+
+// argon2GB 轮函数中的混合函数
+func argon2GB(a, b, c, d *uint64) {
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = bits.RotateLeft64(*d^*a, -32)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = bits.RotateLeft64(*b^*c, -24)
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = bits.RotateLeft64(*d^*a, -16)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = bits.RotateLeft64(*b^*c, -63)
+}
+
+// argon2Hash 变长哈希函数H'
+func argon2Hash(out, in []byte) {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(out)))
+
+	if len(out) <= blake2b.Size {
+		hash, _ := blake2b.New(len(out), nil)
+		hash.Write(length[:])
+		hash.Write(in)
+		hash.Sum(out[:0])
+		return
+	}
+
+	var buffer [blake2b.Size]byte
+	hash, _ := blake2b.New512(nil)
+	hash.Write(length[:])
+	hash.Write(in)
+	hash.Sum(buffer[:0])
+
+	n := copy(out, buffer[:32])
+	for ; len(out)-n > blake2b.Size; n += 32 {
+		hash.Reset()
+		hash.Write(buffer[:])
+		hash.Sum(buffer[:0])
+		copy(out[n:], buffer[:32])
+	}
+
+	hash, _ = blake2b.New(len(out)-n, nil)
+	hash.Write(buffer[:])
+	hash.Sum(out[n:n])
+}
@@ -0,0 +1,220 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 09:20
+// @Desc:	KDBX 内层XML文档的解析：还原分组树与条目，历史版本不导出
+
+// Database 解密后的KeePass数据库
+type Database struct {
+	Name           string
+	RecycleBinUUID string // 回收站分组的UUID（Base64），未启用回收站时为空
+	Root           *Group
+}
+
+// Group 分组
+type Group struct {
+	UUID    string
+	Name    string
+	Groups  []*Group
+	Entries []*Entry
+}
+
+// Entry 条目
+type Entry struct {
+	UUID     string
+	Title    string
+	UserName string
+	Password string
+	URL      string
+	Notes    string
+	Tags     string
+	Fields   map[string]string // 标准字段以外的自定义字段
+}
+
+// xmlNode 简化的XML节点
+type xmlNode struct {
+	name     string
+	text     strings.Builder
+	children []*xmlNode
+}
+
+// child 查找第一个指定名称的子节点
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// childText 查找第一个指定名称的子节点的文本
+func (n *xmlNode) childText(name string) string {
+	child := n.child(name)
+	if child == nil {
+		return ""
+	}
+	return child.text.String()
+}
+
+// parseDocument 解析XML文档，受保护的值按文档顺序使用内层密钥流解密
+func parseDocument(document []byte, stream cipher.Stream) (*Database, error) {
+	root, err := parseXmlTree(document, stream)
+	if err != nil {
+		return nil, err
+	}
+	if root.name != "KeePassFile" {
+		return nil, ErrCorrupted
+	}
+
+	database := &Database{}
+	if meta := root.child("Meta"); meta != nil {
+		database.Name = meta.childText("DatabaseName")
+		if meta.childText("RecycleBinEnabled") == "True" {
+			database.RecycleBinUUID = meta.childText("RecycleBinUUID")
+		}
+	}
+
+	content := root.child("Root")
+	if content == nil || content.child("Group") == nil {
+		return nil, ErrCorrupted
+	}
+	database.Root = parseGroup(content.child("Group"))
+
+	return database, nil
+}
+
+// parseXmlTree 将XML文档解析为节点树
+func parseXmlTree(document []byte, stream cipher.Stream) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+
+	var (
+		root      *xmlNode
+		stack     []*xmlNode
+		protected []bool
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+
+			isProtected := false
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "Protected" && attr.Value == "True" {
+					isProtected = true
+				}
+			}
+			stack = append(stack, node)
+			protected = append(protected, isProtected)
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, ErrCorrupted
+			}
+			node := stack[len(stack)-1]
+			if protected[len(protected)-1] && stream != nil {
+				value, err := decodeBase64(node.text.String())
+				if err != nil {
+					return nil, ErrCorrupted
+				}
+				stream.XORKeyStream(value, value)
+
+				node.text.Reset()
+				node.text.Write(value)
+			}
+			stack = stack[:len(stack)-1]
+			protected = protected[:len(protected)-1]
+		}
+	}
+
+	if root == nil {
+		return nil, ErrCorrupted
+	}
+	return root, nil
+}
+
+// parseGroup 递归解析分组及其条目
+func parseGroup(node *xmlNode) *Group {
+	group := &Group{
+		UUID: node.childText("UUID"),
+		Name: node.childText("Name"),
+	}
+
+	for _, child := range node.children {
+		switch child.name {
+		case "Entry":
+			group.Entries = append(group.Entries, parseEntry(child))
+		case "Group":
+			group.Groups = append(group.Groups, parseGroup(child))
+		}
+	}
+
+	return group
+}
+
+// parseEntry 解析条目的标准字段与自定义字段
+func parseEntry(node *xmlNode) *Entry {
+	entry := &Entry{
+		UUID:   node.childText("UUID"),
+		Tags:   node.childText("Tags"),
+		Fields: make(map[string]string),
+	}
+
+	for _, child := range node.children {
+		if child.name != "String" {
+			continue
+		}
+
+		value := child.childText("Value")
+		switch key := child.childText("Key"); key {
+		case "Title":
+			entry.Title = value
+		case "UserName":
+			entry.UserName = value
+		case "Password":
+			entry.Password = value
+		case "URL":
+			entry.URL = value
+		case "Notes":
+			entry.Notes = value
+		default:
+			entry.Fields[key] = value
+		}
+	}
+
+	return entry
+}
+
+// decodeBase64 解码可能包含空白的Base64文本
+func decodeBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+}
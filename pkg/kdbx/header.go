@@ -0,0 +1,291 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 09:20
+// @Desc:	KDBX 4 外层文件头、KDF参数与主密钥的解析
+
+const (
+	signature1 = 0x9AA2D903
+	signature2 = 0xB54BFB67
+
+	headerEnd         = 0
+	headerCipherID    = 2
+	headerCompression = 3
+	headerMasterSeed  = 4
+	headerEncryptIV   = 7
+	headerKdfParams   = 11
+
+	// 限制KDF开销，避免构造的文件耗尽服务端资源
+	maxArgon2Memory     = 1 << 30
+	maxArgon2Iterations = 100
+	maxAesKdfRounds     = 100_000_000
+)
+
+var (
+	cipherAES256   = mustUUID("31c1f2e6bf714350be5805216afc5aff")
+	cipherChaCha20 = mustUUID("d6038a2b8b6f4cb5a524339a31dbb59a")
+	cipherTwofish  = mustUUID("ad68f29f576f4bb9a36ad47af965346c")
+
+	kdfAES      = mustUUID("c9d9f39a628a4460bf740d08c18a4fea")
+	kdfArgon2d  = mustUUID("ef636ddf8c29444b91f7a9a403e30a0c")
+	kdfArgon2id = mustUUID("9e298b1956db4773b23dfc3ec6f0a1e6")
+)
+
+// outerHeader 外层文件头
+type outerHeader struct {
+	raw         []byte // 文件头原始字节，参与完整性与HMAC校验
+	cipherID    []byte
+	compression uint32
+	masterSeed  []byte
+	encryptIV   []byte
+	kdfParams   variantDictionary
+}
+
+// variantDictionary KDBX 4 中保存KDF参数的键值字典
+type variantDictionary map[string][]byte
+
+// parseOuterHeader 解析签名、版本与外层文件头，返回文件头及其后的数据
+func parseOuterHeader(data []byte) (*outerHeader, []byte, error) {
+	if len(data) < 12 || binary.LittleEndian.Uint32(data[0:4]) != signature1 || binary.LittleEndian.Uint32(data[4:8]) != signature2 {
+		return nil, nil, ErrInvalidFile
+	}
+	if binary.LittleEndian.Uint32(data[8:12])>>16 != 4 {
+		return nil, nil, ErrUnsupportedVersion
+	}
+
+	header := &outerHeader{}
+	pos := 12
+	for {
+		if len(data) < pos+5 {
+			return nil, nil, ErrCorrupted
+		}
+		id := data[pos]
+		size := int(binary.LittleEndian.Uint32(data[pos+1 : pos+5]))
+		pos += 5
+		if size < 0 || len(data) < pos+size {
+			return nil, nil, ErrCorrupted
+		}
+		value := data[pos : pos+size]
+		pos += size
+
+		switch id {
+		case headerCipherID:
+			header.cipherID = value
+		case headerCompression:
+			if len(value) != 4 {
+				return nil, nil, ErrCorrupted
+			}
+			header.compression = binary.LittleEndian.Uint32(value)
+		case headerMasterSeed:
+			header.masterSeed = value
+		case headerEncryptIV:
+			header.encryptIV = value
+		case headerKdfParams:
+			params, err := parseVariantDictionary(value)
+			if err != nil {
+				return nil, nil, err
+			}
+			header.kdfParams = params
+		}
+
+		if id == headerEnd {
+			break
+		}
+	}
+
+	if len(header.masterSeed) != 32 || header.cipherID == nil || header.kdfParams == nil {
+		return nil, nil, ErrCorrupted
+	}
+
+	header.raw = data[:pos]
+	return header, data[pos:], nil
+}
+
+// parseVariantDictionary 解析键值字典，值保留原始字节，按需转换类型
+func parseVariantDictionary(data []byte) (variantDictionary, error) {
+	if len(data) < 2 || binary.LittleEndian.Uint16(data[0:2])&0xFF00 != 0x0100 {
+		return nil, ErrCorrupted
+	}
+
+	dict := make(variantDictionary)
+	pos := 2
+	for {
+		if len(data) < pos+1 {
+			return nil, ErrCorrupted
+		}
+		if data[pos] == 0 {
+			return dict, nil
+		}
+		pos++
+
+		var fields [2][]byte
+		for i := range fields {
+			if len(data) < pos+4 {
+				return nil, ErrCorrupted
+			}
+			size := int(binary.LittleEndian.Uint32(data[pos : pos+4]))
+			pos += 4
+			if size < 0 || len(data) < pos+size {
+				return nil, ErrCorrupted
+			}
+			fields[i] = data[pos : pos+size]
+			pos += size
+		}
+		dict[string(fields[0])] = fields[1]
+	}
+}
+
+// uint64Value 读取整数值，兼容以 UInt32 或 UInt64 保存的参数
+func (d variantDictionary) uint64Value(key string) (uint64, bool) {
+	value, ok := d[key]
+	switch {
+	case ok && len(value) == 8:
+		return binary.LittleEndian.Uint64(value), true
+	case ok && len(value) == 4:
+		return uint64(binary.LittleEndian.Uint32(value)), true
+	default:
+		return 0, false
+	}
+}
+
+// compositeKey 由主口令与密钥文件计算组合密钥
+func compositeKey(password string, keyFile []byte) ([]byte, error) {
+	hash := sha256.New()
+	if password != "" {
+		sum := sha256.Sum256([]byte(password))
+		hash.Write(sum[:])
+	}
+	if keyFile != nil {
+		key, err := keyFileKey(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		hash.Write(key)
+	}
+	if password == "" && keyFile == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return hash.Sum(nil), nil
+}
+
+// keyFileKey 解析密钥文件：支持 KeePass XML 密钥文件（1.0/2.0）、32字节原始密钥、64位十六进制密钥，其它文件取其SHA-256
+func keyFileKey(data []byte) ([]byte, error) {
+	type keyFileType struct {
+		Version string `xml:"Meta>Version"`
+		Data    struct {
+			Hash  string `xml:"Hash,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Key>Data"`
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<KeyFile")) {
+		var keyFile keyFileType
+		if xml.Unmarshal(trimmed, &keyFile) == nil && keyFile.Data.Value != "" {
+			if strings.HasPrefix(keyFile.Version, "2.") {
+				key, err := hex.DecodeString(strings.Join(strings.Fields(keyFile.Data.Value), ""))
+				if err != nil {
+					return nil, ErrInvalidKeyFile
+				}
+				sum := sha256.Sum256(key)
+				if keyFile.Data.Hash != "" && !strings.EqualFold(hex.EncodeToString(sum[:4]), keyFile.Data.Hash) {
+					return nil, ErrInvalidKeyFile
+				}
+				return key, nil
+			}
+
+			key, err := decodeBase64(keyFile.Data.Value)
+			if err != nil {
+				return nil, ErrInvalidKeyFile
+			}
+			return key, nil
+		}
+	}
+
+	if len(data) == 32 {
+		return data, nil
+	}
+	if len(data) == 64 {
+		if key, err := hex.DecodeString(string(data)); err == nil {
+			return key, nil
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+// transformKey 使用文件头指定的KDF变换组合密钥
+func transformKey(params variantDictionary, key []byte) ([]byte, error) {
+	uuid := params["$UUID"]
+	salt := params["S"]
+
+	switch {
+	case bytes.Equal(uuid, kdfArgon2d) || bytes.Equal(uuid, kdfArgon2id):
+		iterations, ok1 := params.uint64Value("I")
+		memory, ok2 := params.uint64Value("M")
+		parallelism, ok3 := params.uint64Value("P")
+		version, _ := params.uint64Value("V")
+		if !ok1 || !ok2 || !ok3 || len(salt) == 0 || version != argon2Version {
+			return nil, ErrCorrupted
+		}
+		if iterations == 0 || iterations > maxArgon2Iterations || memory > maxArgon2Memory || parallelism == 0 || parallelism > 255 {
+			return nil, errors.New("the KDF parameters exceed the allowed limits")
+		}
+
+		if bytes.Equal(uuid, kdfArgon2id) {
+			if len(params["K"]) > 0 || len(params["A"]) > 0 {
+				return nil, ErrUnsupportedKdf
+			}
+			return argon2.IDKey(key, salt, uint32(iterations), uint32(memory/1024), uint8(parallelism), 32), nil
+		}
+		return argon2dKey(key, salt, params["K"], params["A"], uint32(iterations), uint32(memory/1024), uint8(parallelism), 32), nil
+
+	case bytes.Equal(uuid, kdfAES):
+		rounds, ok := params.uint64Value("R")
+		if !ok || len(salt) != 32 {
+			return nil, ErrCorrupted
+		}
+		if rounds > maxAesKdfRounds {
+			return nil, errors.New("the KDF parameters exceed the allowed limits")
+		}
+
+		block, err := aes.NewCipher(salt)
+		if err != nil {
+			return nil, err
+		}
+		transformed := append([]byte(nil), key...)
+		for i := uint64(0); i < rounds; i++ {
+			block.Encrypt(transformed[0:16], transformed[0:16])
+			block.Encrypt(transformed[16:32], transformed[16:32])
+		}
+		sum := sha256.Sum256(transformed)
+		return sum[:], nil
+
+	default:
+		return nil, ErrUnsupportedKdf
+	}
+}
+
+// mustUUID 解析十六进制表示的UUID
+func mustUUID(value string) []byte {
+	uuid, err := hex.DecodeString(value)
+	if err != nil {
+		panic(err)
+	}
+	return uuid
+}
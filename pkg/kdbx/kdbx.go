@@ -0,0 +1,288 @@
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/salsa20/salsa"
+	"golang.org/x/crypto/twofish"
+	"io"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 09:20
+// @Desc:	KeePass KDBX 4 数据库的只读解析：校验文件头HMAC，解密HMAC分块数据并还原受保护字段
+
+var (
+	ErrInvalidFile        = errors.New("not a KDBX file")
+	ErrUnsupportedVersion = errors.New("unsupported KDBX version, only KDBX 4 is supported")
+	ErrUnsupportedCipher  = errors.New("unsupported KDBX cipher")
+	ErrUnsupportedKdf     = errors.New("unsupported KDBX key derivation function")
+	ErrInvalidCredentials = errors.New("invalid master password or key file")
+	ErrInvalidKeyFile     = errors.New("invalid key file")
+	ErrCorrupted          = errors.New("the KDBX file is corrupted")
+)
+
+const (
+	innerHeaderEnd       = 0
+	innerHeaderStreamID  = 1
+	innerHeaderStreamKey = 2
+
+	innerStreamNone     = 0
+	innerStreamSalsa20  = 2
+	innerStreamChaCha20 = 3
+)
+
+// IsKDBX 根据文件签名判断是否为KeePass数据库
+func IsKDBX(data []byte) bool {
+	return len(data) >= 8 && binary.LittleEndian.Uint32(data[0:4]) == signature1 && binary.LittleEndian.Uint32(data[4:8]) == signature2
+}
+
+// Open 使用主口令与可选的密钥文件内容解密KDBX 4数据库
+func Open(data []byte, password string, keyFile []byte) (*Database, error) {
+	header, rest, err := parseOuterHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < 64 {
+		return nil, ErrCorrupted
+	}
+	headerHash, headerHmac, blocks := rest[:32], rest[32:64], rest[64:]
+
+	sum := sha256.Sum256(header.raw)
+	if !hmac.Equal(sum[:], headerHash) {
+		return nil, ErrCorrupted
+	}
+
+	composite, err := compositeKey(password, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	transformed, err := transformKey(header.kdfParams, composite)
+	if err != nil {
+		return nil, err
+	}
+
+	seeded := append(append([]byte(nil), header.masterSeed...), transformed...)
+	cipherKey := sha256.Sum256(seeded)
+	hmacKey := sha512.Sum512(append(seeded, 0x01))
+
+	// 文件头HMAC不一致说明主密钥错误
+	mac := hmac.New(sha256.New, blockKey(hmacKey[:], ^uint64(0)))
+	mac.Write(header.raw)
+	if !hmac.Equal(mac.Sum(nil), headerHmac) {
+		return nil, ErrInvalidCredentials
+	}
+
+	encrypted, err := readHmacBlocks(hmacKey[:], blocks)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := decryptPayload(header, cipherKey[:], encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.compression == 1 {
+		gz, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+		payload, err = io.ReadAll(gz)
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+	}
+
+	stream, document, err := parseInnerHeader(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDocument(document, stream)
+}
+
+// blockHmac 计算数据块的HMAC-SHA256，认证内容为块序号、块长度与块数据
+func blockHmac(hmacKey []byte, index uint64, data []byte) []byte {
+	var prefix [12]byte
+	binary.LittleEndian.PutUint64(prefix[0:8], index)
+	binary.LittleEndian.PutUint32(prefix[8:12], uint32(len(data)))
+
+	mac := hmac.New(sha256.New, blockKey(hmacKey, index))
+	mac.Write(prefix[:])
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// blockKey 派生指定序号数据块的HMAC密钥，文件头使用序号 2^64-1
+func blockKey(hmacKey []byte, index uint64) []byte {
+	var prefix [8]byte
+	binary.LittleEndian.PutUint64(prefix[:], index)
+
+	key := sha512.Sum512(append(prefix[:], hmacKey...))
+	return key[:]
+}
+
+// readHmacBlocks 逐块校验HMAC并拼接密文
+func readHmacBlocks(hmacKey, data []byte) ([]byte, error) {
+	var encrypted bytes.Buffer
+	for index := uint64(0); ; index++ {
+		if len(data) < 36 {
+			return nil, ErrCorrupted
+		}
+		expected := data[:32]
+		size := int(binary.LittleEndian.Uint32(data[32:36]))
+		data = data[36:]
+		if size < 0 || len(data) < size {
+			return nil, ErrCorrupted
+		}
+		block := data[:size]
+		data = data[size:]
+
+		if !hmac.Equal(blockHmac(hmacKey, index, block), expected) {
+			return nil, ErrCorrupted
+		}
+		if size == 0 {
+			return encrypted.Bytes(), nil
+		}
+		encrypted.Write(block)
+	}
+}
+
+// decryptPayload 使用文件头指定的算法解密数据
+func decryptPayload(header *outerHeader, key, data []byte) ([]byte, error) {
+	switch {
+	case bytes.Equal(header.cipherID, cipherChaCha20):
+		if len(header.encryptIV) != chacha20.NonceSize {
+			return nil, ErrCorrupted
+		}
+		stream, err := chacha20.NewUnauthenticatedCipher(key, header.encryptIV)
+		if err != nil {
+			return nil, err
+		}
+		plaintext := make([]byte, len(data))
+		stream.XORKeyStream(plaintext, data)
+		return plaintext, nil
+
+	case bytes.Equal(header.cipherID, cipherAES256), bytes.Equal(header.cipherID, cipherTwofish):
+		var (
+			block cipher.Block
+			err   error
+		)
+		if bytes.Equal(header.cipherID, cipherAES256) {
+			block, err = aes.NewCipher(key)
+		} else {
+			block, err = twofish.NewCipher(key)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(header.encryptIV) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+			return nil, ErrCorrupted
+		}
+
+		plaintext := make([]byte, len(data))
+		cipher.NewCBCDecrypter(block, header.encryptIV).CryptBlocks(plaintext, data)
+
+		// 去除PKCS#7填充
+		padding := int(plaintext[len(plaintext)-1])
+		if padding == 0 || padding > block.BlockSize() {
+			return nil, ErrCorrupted
+		}
+		return plaintext[:len(plaintext)-padding], nil
+
+	default:
+		return nil, ErrUnsupportedCipher
+	}
+}
+
+// parseInnerHeader 解析内层文件头，返回受保护字段的密钥流及XML文档
+func parseInnerHeader(data []byte) (cipher.Stream, []byte, error) {
+	var (
+		streamID  uint32
+		streamKey []byte
+	)
+
+	pos := 0
+	for {
+		if len(data) < pos+5 {
+			return nil, nil, ErrCorrupted
+		}
+		id := data[pos]
+		size := int(binary.LittleEndian.Uint32(data[pos+1 : pos+5]))
+		pos += 5
+		if size < 0 || len(data) < pos+size {
+			return nil, nil, ErrCorrupted
+		}
+		value := data[pos : pos+size]
+		pos += size
+
+		switch id {
+		case innerHeaderStreamID:
+			if len(value) != 4 {
+				return nil, nil, ErrCorrupted
+			}
+			streamID = binary.LittleEndian.Uint32(value)
+		case innerHeaderStreamKey:
+			streamKey = value
+		}
+
+		if id == innerHeaderEnd {
+			break
+		}
+	}
+
+	switch streamID {
+	case innerStreamChaCha20:
+		hash := sha512.Sum512(streamKey)
+		stream, err := chacha20.NewUnauthenticatedCipher(hash[:32], hash[32:44])
+		if err != nil {
+			return nil, nil, err
+		}
+		return stream, data[pos:], nil
+	case innerStreamSalsa20:
+		stream := &salsa20Stream{
+			key:   sha256.Sum256(streamKey),
+			nonce: [8]byte{0xE8, 0x30, 0x09, 0x4B, 0x97, 0x20, 0x5D, 0x2A},
+		}
+		return stream, data[pos:], nil
+	case innerStreamNone:
+		return nil, data[pos:], nil
+	default:
+		return nil, nil, ErrUnsupportedCipher
+	}
+}
+
+// salsa20Stream 可连续调用的Salsa20密钥流，受保护字段按文档顺序共用同一密钥流
+type salsa20Stream struct {
+	key     [32]byte
+	nonce   [8]byte
+	counter uint64
+	buffer  []byte
+}
+
+// XORKeyStream 将src与密钥流异或后写入dst
+func (s *salsa20Stream) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if len(s.buffer) == 0 {
+			var input [16]byte
+			copy(input[:8], s.nonce[:])
+			binary.LittleEndian.PutUint64(input[8:], s.counter)
+			s.counter++
+
+			s.buffer = make([]byte, 64)
+			salsa.XORKeyStream(s.buffer, s.buffer, &input, &s.key)
+		}
+
+		dst[i] = src[i] ^ s.buffer[0]
+		s.buffer = s.buffer[1:]
+	}
+}
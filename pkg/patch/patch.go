@@ -0,0 +1,208 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// @Author: yv1ing
+// @Email:  me@yvling.cn
+// @Date:   2026/10/19 21:10
+// @Desc:	按 JSON Merge Patch（RFC 7396）语义将请求中的字段应用到数据模型，并按模型的 binding 规则校验
+
+// FieldError 单个字段的错误
+type FieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+// Errors 补丁中全部字段的错误，按字段名排序
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for index, fieldError := range e {
+		messages[index] = fieldError.Field + ": " + fieldError.Error
+	}
+	return "invalid patch: " + strings.Join(messages, "; ")
+}
+
+// validate 与 gin 一致使用 binding 标签的校验器，错误中的字段名取JSON字段名
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return jsonName(field)
+	})
+	return v
+}
+
+// Apply 将补丁应用到 target 指向的结构体：仅可修改 writable 中列出的JSON字段，值为null时重置为零值，
+// 映射类型的字段按键合并且键值为null时删除该键；应用后按字段的 binding 规则校验被修改的字段。
+// 返回被修改字段的JSON字段名与修改后的值，存在未知字段、只读字段、类型不符或校验失败的字段时返回 Errors
+func Apply(target interface{}, document map[string]json.RawMessage, writable []string) (map[string]interface{}, error) {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("patch target must be a pointer to struct")
+	}
+	value = value.Elem()
+
+	fields := structFields(value.Type())
+	allowed := make(map[string]bool, len(writable))
+	for _, name := range writable {
+		allowed[name] = true
+	}
+
+	var fieldErrors Errors
+	var changed []string
+	for name, raw := range document {
+		field, ok := fields[name]
+		if !ok {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Error: "unknown field"})
+			continue
+		}
+		if !allowed[name] {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Error: "read-only field"})
+			continue
+		}
+
+		err := mergeValue(value.FieldByIndex(field.Index), raw)
+		if err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Error: err.Error()})
+			continue
+		}
+		changed = append(changed, name)
+	}
+
+	// 仅校验被修改的字段，避免历史数据中的其它字段影响本次修改
+	if len(changed) > 0 {
+		structNames := make([]string, len(changed))
+		for index, name := range changed {
+			structNames[index] = fields[name].Name
+		}
+
+		err := validate.StructPartial(target, structNames...)
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, validationError := range validationErrors {
+				fieldErrors = append(fieldErrors, FieldError{
+					Field: validationError.Field(),
+					Error: fmt.Sprintf("failed on the '%s' rule", validationError.Tag()),
+				})
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		sort.Slice(fieldErrors, func(i, j int) bool {
+			return fieldErrors[i].Field < fieldErrors[j].Field
+		})
+		return nil, fieldErrors
+	}
+
+	values := make(map[string]interface{}, len(changed))
+	for _, name := range changed {
+		values[name] = value.FieldByIndex(fields[name].Index).Interface()
+	}
+	return values, nil
+}
+
+// mergeValue 将补丁中的单个值合并到字段
+func mergeValue(field reflect.Value, raw json.RawMessage) error {
+	if isNull(raw) {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if field.Kind() == reflect.Map && bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return typeError(field.Type())
+		}
+
+		merged := reflect.MakeMap(field.Type())
+		iter := field.MapRange()
+		for iter.Next() {
+			merged.SetMapIndex(iter.Key(), iter.Value())
+		}
+		for key, entry := range entries {
+			mapKey := reflect.ValueOf(key).Convert(field.Type().Key())
+			if isNull(entry) {
+				merged.SetMapIndex(mapKey, reflect.Value{})
+				continue
+			}
+
+			element := reflect.New(field.Type().Elem())
+			if err := json.Unmarshal(entry, element.Interface()); err != nil {
+				return typeError(field.Type())
+			}
+			merged.SetMapIndex(mapKey, element.Elem())
+		}
+		field.Set(merged)
+		return nil
+	}
+
+	decoded := reflect.New(field.Type())
+	if err := json.Unmarshal(raw, decoded.Interface()); err != nil {
+		return typeError(field.Type())
+	}
+	field.Set(decoded.Elem())
+	return nil
+}
+
+// structFields 按JSON字段名索引结构体中带有json标签的字段，嵌入的结构体不参与
+func structFields(structType reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+		if name := jsonName(field); name != "" {
+			fields[name] = field
+		}
+	}
+	return fields
+}
+
+// jsonName 返回字段的JSON字段名，未设置json标签或标签为"-"时返回空字符串
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func isNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// typeError 字段类型不符的错误，说明期望的JSON类型
+func typeError(fieldType reflect.Type) error {
+	expected := "string"
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		expected = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		expected = "integer"
+	case reflect.Float32, reflect.Float64:
+		expected = "number"
+	case reflect.Map, reflect.Struct:
+		expected = "object"
+	case reflect.Slice, reflect.Array:
+		expected = "array"
+	}
+	return fmt.Errorf("invalid type, expected %s", expected)
+}
@@ -15,9 +15,41 @@ const Auth = {
         return Storage.get('user', {});
     },
 
+    // 使用刷新令牌换取新的访问令牌，并发请求共用同一次刷新
+    refresh() {
+        const refresh_token = Storage.get('refresh_token');
+        if (!refresh_token) {
+            return Promise.resolve(false);
+        }
+
+        if (!this.refreshing) {
+            this.refreshing = fetch(`${API_BASE_URL}/sys/users/refresh`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ refresh_token })
+            })
+                .then(async (response) => {
+                    if (!response.ok) {
+                        return false;
+                    }
+                    const data = await response.json();
+                    Storage.set('jwt_token', data.data.jwt_token);
+                    Storage.set('refresh_token', data.data.refresh_token);
+                    return true;
+                })
+                .catch(() => false)
+                .finally(() => {
+                    this.refreshing = null;
+                });
+        }
+
+        return this.refreshing;
+    },
+
     // 登出
     logout() {
         Storage.remove('jwt_token');
+        Storage.remove('refresh_token');
         Storage.remove('user');
         window.location.href = '/login.html';
     }
@@ -25,9 +25,11 @@ class HTTP {
         const skipAuthCheck = options.skipAuthCheck || false;
         const showSuccessToast = options.showSuccessToast !== undefined ? options.showSuccessToast : false;
         const showErrorToast = options.showErrorToast !== undefined ? options.showErrorToast : true;
+        const isRetry = options.isRetry || false;
 
         // 删除自定义选项，避免传递给 fetch
         delete finalOptions.skipAuthCheck;
+        delete finalOptions.isRetry;
         delete finalOptions.showSuccessToast;
         delete finalOptions.showErrorToast;
 
@@ -48,6 +50,11 @@ class HTTP {
 
             // 处理未授权 - 但跳过登录接口的自动处理
             if (response.status === 401 && !skipAuthCheck) {
+                // 访问令牌过期时尝试使用刷新令牌续期，并重试一次原请求
+                if (!isRetry && await Auth.refresh()) {
+                    return this.request(url, { ...options, isRetry: true });
+                }
+
                 // 显示过期提示（使用国际化）
                 const messageKey = getMessageKeyByCode(InfoCodes.EXPIRED_TOKEN);
                 const message = messageKey ? langManager.t(messageKey) : langManager.t('toast.loginExpired');
@@ -70,13 +77,18 @@ class HTTP {
                             Toast.success(message);
                         }
                     } else if (isErrorCode(data.code)) {
-                        // 错误消息默认显示
+                        // 错误消息默认显示，参数错误附带出错的字段
+                        const fieldErrors = Array.isArray(data.errors) ? data.errors : [];
+                        const detail = fieldErrors.map(e => `${e.field}: ${e.error}`).join('; ');
                         if (showErrorToast) {
-                            Toast.error(message);
+                            Toast.error(detail ? `${message} (${detail})` : message);
                         }
                         // 对于错误响应，抛出业务异常（标记为业务错误，不打印到控制台）
                         const error = new Error(message);
                         error.isBusinessError = true;
+                        error.code = data.code;
+                        error.errors = fieldErrors;
+                        error.requestId = data.request_id;
                         throw error;
                     }
                 }
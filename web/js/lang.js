@@ -55,6 +55,8 @@ const translations = {
         'login.success': '登录成功',
         'login.error': '登录失败，请检查账号和密码',
         'login.formatError': '登录响应格式错误',
+        'login.totpPrompt': '请输入身份验证器中的动态验证码或恢复码',
+        'login.totpCancelled': '已取消双因素认证',
         'login.fillAll': '请填写完整信息',
 
         // 首页
@@ -202,6 +204,15 @@ const translations = {
         'api.success.successfulUpdate': '更新成功',
         'api.success.successfulFind': '查询成功',
         'api.success.successfulUpload': '上传成功',
+        'api.success.successfulUnlock': '解锁成功',
+        'api.success.successfulLock': '锁定成功',
+        'api.success.totpRequired': '请输入动态验证码',
+        'api.success.successfulEnableTotp': '双因素认证已启用',
+        'api.success.successfulDisableTotp': '双因素认证已停用',
+        'api.success.successfulReveal': '获取明文成功',
+        'api.success.successfulRestore': '恢复成功',
+        'api.success.successfulPurge': '彻底删除成功',
+        'api.success.successfulRollback': '回滚成功',
 
         // API响应消息 - 错误
         'api.error.internalError': '系统内部错误',
@@ -220,11 +231,39 @@ const translations = {
         'api.error.failedToUpload': '上传失败',
         'api.error.recordNotFound': '记录不存在',
         'api.error.usernameAlreadyExists': '用户名已存在',
+        'api.error.vaultSealed': '保险库已封存，请先解锁',
+        'api.error.failedToUnlock': '解锁失败，请检查保险库口令',
+        'api.error.invalidTotpCode': '动态验证码错误',
+        'api.error.totpAlreadyEnabled': '双因素认证已启用',
+        'api.error.totpNotEnabled': '双因素认证未启用',
+        'api.error.permissionDenied': '权限不足',
+        'api.error.roleAlreadyExists': '角色已存在',
+        'api.error.roleInUse': '角色仍被用户使用',
+        'api.error.roleImmutable': '内置角色不可修改',
+        'api.error.lastAdminRequired': '系统至少需要保留一名管理员',
+        'api.error.unknownPermission': '未知的权限',
+        'api.error.groupAlreadyExists': '用户组已存在',
+        'api.error.reauthRequired': '请输入登录密码以查看明文',
+        'api.error.reauthFailed': '登录密码错误',
+        'api.error.accountLocked': '账户已被临时锁定，请稍后再试',
+        'api.error.tooManyLoginAttempts': '登录尝试过于频繁，请稍后再试',
+        'api.error.invalidImportFile': '导入文件格式无效或已损坏',
+        'api.error.invalidImportCredentials': '导入文件的主密码或密钥文件错误',
+        'api.error.failedToRestore': '恢复失败',
+        'api.error.failedToPurge': '彻底删除失败',
+        'api.error.recordNotInTrash': '记录不在回收站中，请先删除到回收站',
+        'api.error.failedToRollback': '回滚失败',
+        'api.error.revisionNotFound': '修订记录不存在',
+        'api.error.sessionRequired': '该操作需要登录会话，不能使用访问令牌',
+        'api.error.invalidTokenScope': '访问令牌的权限范围无效',
 
         // CSV导入导出
         'csv.exporting': '正在导出CSV文件...',
         'csv.exportSuccess': '导出成功',
         'csv.exportFailed': '导出失败',
+        'csv.exportPasswordPrompt': '导出内容包含密码等敏感字段，请输入登录密码',
+        'csv.archivePasswordPrompt': '如需导出为加密的ZIP压缩包，请输入压缩包密码（至少8位），留空则导出明文CSV',
+        'csv.archivePasswordTooShort': '压缩包密码至少8位',
         'csv.importing': '正在导入CSV文件...',
         'csv.importSuccess': '成功导入 {count} 条记录',
         'csv.importPartial': '导入完成：成功 {success} 条，失败 {failed} 条',
@@ -293,6 +332,8 @@ const translations = {
         'login.success': 'Login successful',
         'login.error': 'Login failed, please check your account and password',
         'login.formatError': 'Invalid login response format',
+        'login.totpPrompt': 'Enter the code from your authenticator app or a recovery code',
+        'login.totpCancelled': 'Two-factor authentication cancelled',
         'login.fillAll': 'Please fill in all fields',
 
         // Index Page
@@ -440,6 +481,15 @@ const translations = {
         'api.success.successfulUpdate': 'Update successful',
         'api.success.successfulFind': 'Query successful',
         'api.success.successfulUpload': 'Upload successful',
+        'api.success.successfulUnlock': 'Unlock successful',
+        'api.success.successfulLock': 'Lock successful',
+        'api.success.totpRequired': 'Please enter the verification code',
+        'api.success.successfulEnableTotp': 'Two-factor authentication enabled',
+        'api.success.successfulDisableTotp': 'Two-factor authentication disabled',
+        'api.success.successfulReveal': 'Reveal successful',
+        'api.success.successfulRestore': 'Restore successful',
+        'api.success.successfulPurge': 'Permanently deleted',
+        'api.success.successfulRollback': 'Rollback successful',
 
         // API Response Messages - Error
         'api.error.internalError': 'Internal system error',
@@ -458,11 +508,39 @@ const translations = {
         'api.error.failedToUpload': 'Upload failed',
         'api.error.recordNotFound': 'Record not found',
         'api.error.usernameAlreadyExists': 'Username already exists',
+        'api.error.vaultSealed': 'The vault is sealed, please unlock it first',
+        'api.error.failedToUnlock': 'Unlock failed, please check the vault passphrase',
+        'api.error.invalidTotpCode': 'Invalid verification code',
+        'api.error.totpAlreadyEnabled': 'Two-factor authentication is already enabled',
+        'api.error.totpNotEnabled': 'Two-factor authentication is not enabled',
+        'api.error.permissionDenied': 'Permission denied',
+        'api.error.roleAlreadyExists': 'Role already exists',
+        'api.error.roleInUse': 'Role is still assigned to users',
+        'api.error.roleImmutable': 'Built-in role cannot be modified',
+        'api.error.lastAdminRequired': 'At least one administrator is required',
+        'api.error.unknownPermission': 'Unknown permission',
+        'api.error.groupAlreadyExists': 'Group already exists',
+        'api.error.reauthRequired': 'Please enter your password to reveal',
+        'api.error.reauthFailed': 'Incorrect password',
+        'api.error.accountLocked': 'Account is temporarily locked, please try again later',
+        'api.error.tooManyLoginAttempts': 'Too many login attempts, please try again later',
+        'api.error.invalidImportFile': 'The import file is invalid or corrupted',
+        'api.error.invalidImportCredentials': 'Invalid master password or key file for the import file',
+        'api.error.failedToRestore': 'Restore failed',
+        'api.error.failedToPurge': 'Permanent deletion failed',
+        'api.error.recordNotInTrash': 'The record is not in the trash, move it to the trash first',
+        'api.error.failedToRollback': 'Rollback failed',
+        'api.error.revisionNotFound': 'Revision not found',
+        'api.error.sessionRequired': 'This operation requires a login session and cannot use an access token',
+        'api.error.invalidTokenScope': 'Invalid access token scope',
 
         // CSV Import/Export
         'csv.exporting': 'Exporting CSV file...',
         'csv.exportSuccess': 'Export successful',
         'csv.exportFailed': 'Export failed',
+        'csv.exportPasswordPrompt': 'The export contains passwords and other sensitive fields, please enter your password',
+        'csv.archivePasswordPrompt': 'To export an encrypted ZIP archive, enter an archive password (at least 8 characters); leave empty for a plain CSV',
+        'csv.archivePasswordTooShort': 'The archive password must be at least 8 characters',
         'csv.importing': 'Importing CSV file...',
         'csv.importSuccess': 'Successfully imported {count} records',
         'csv.importPartial': 'Import completed: {success} succeeded, {failed} failed',
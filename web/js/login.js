@@ -51,11 +51,21 @@ document.addEventListener('DOMContentLoaded', () => {
 
         try {
             // 登录请求 - 成功时显示提示，错误会自动处理
-            const response = await UserAPI.login(username, password, { showSuccessToast: true });
+            let response = await UserAPI.login(username, password, { showSuccessToast: true });
+
+            // 已启用双因素认证时，继续提交动态验证码或恢复码
+            if (response.data && response.data.mfa_token) {
+                const code = window.prompt(langManager.t('login.totpPrompt'));
+                if (!code) {
+                    throw new Error(langManager.t('login.totpCancelled'));
+                }
+                response = await UserAPI.loginTotp(response.data.mfa_token, code.trim(), { showSuccessToast: true });
+            }
 
             // 保存 token 和用户信息
             if (response.data && response.data.jwt_token) {
                 Storage.set('jwt_token', response.data.jwt_token);
+                Storage.set('refresh_token', response.data.refresh_token);
                 Storage.set('user', { username });
 
                 // 延迟跳转，让用户看到成功提示
@@ -26,9 +26,23 @@ class CSVManager {
      * @returns {Promise<void>}
      */
     async _handleExport() {
+        const config = PageConfig[this.dataManager.currentPage];
+
+        // 站点记录不含敏感字段，其余记录导出前须重新输入登录密码
+        let password = '';
+        if (config.api.resource !== 'sites') {
+            password = window.prompt(langManager.t('csv.exportPasswordPrompt'));
+            if (!password) return;
+        }
+
+        const archivePassword = window.prompt(langManager.t('csv.archivePasswordPrompt')) || '';
+        if (archivePassword && archivePassword.length < 8) {
+            Toast.error(langManager.t('csv.archivePasswordTooShort'));
+            return;
+        }
+
         try {
-            const config = PageConfig[this.dataManager.currentPage];
-            await config.api.exportCSV();
+            await config.api.exportCSV(password, archivePassword);
             Toast.success(langManager.t('csv.exportSuccess'));
         } catch (error) {
             Toast.error(langManager.t('csv.exportFailed') + ': ' + (error.message || ''));
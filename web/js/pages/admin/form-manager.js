@@ -86,7 +86,14 @@ class AdminFormManager {
             if (typeof newValue === 'object' && typeof oldValue === 'object') {
                 // 对象类型（如ports）
                 if (JSON.stringify(newValue) !== JSON.stringify(oldValue)) {
-                    changes[key] = newValue;
+                    // 后端按 JSON Merge Patch 合并对象，已删除的键须显式置为null
+                    const patch = { ...newValue };
+                    for (const oldKey in (oldValue || {})) {
+                        if (!(oldKey in newValue)) {
+                            patch[oldKey] = null;
+                        }
+                    }
+                    changes[key] = patch;
                     hasChanges = true;
                 }
             } else {
@@ -69,14 +69,36 @@ class BaseAPI {
         return HTTP.get(`${this.baseUrl}/list`, { page, size: pageSize }, options);
     }
 
+    /**
+     * 查看单条记录敏感字段明文
+     * @param {number} id - 记录ID
+     * @param {string} field - 敏感字段名
+     * @param {string} password - 登录口令（服务端要求重新认证时提供）
+     * @param {Object} options - HTTP请求选项
+     * @returns {Promise<Object>}
+     */
+    reveal(id, field, password = '', options = {}) {
+        const idField = `${this.resource.slice(0, -1)}_id`; // accounts -> account_id
+        return HTTP.post(`${this.baseUrl}/reveal`, {
+            [idField]: parseInt(id),
+            field,
+            password
+        }, options);
+    }
+
     /**
      * 导出CSV
+     * @param {string} password - 登录密码（导出含敏感字段的记录时必填）
+     * @param {string} archivePassword - 压缩包密码，非空时导出为加密的ZIP压缩包
      * @returns {Promise<void>}
      */
-    async exportCSV() {
+    async exportCSV(password = '', archivePassword = '') {
         const date = new Date().toISOString().slice(0, 10);
-        const filename = `${this.resource}_${date}.csv`;
-        return CSVHandler.exportCSV(`${this.baseUrl}/export`, filename);
+        const filename = `${this.resource}_${date}.${archivePassword ? 'zip' : 'csv'}`;
+        return CSVHandler.exportCSV(`${this.baseUrl}/export`, filename, {
+            password,
+            archive_password: archivePassword
+        });
     }
 
     /**
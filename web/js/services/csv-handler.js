@@ -4,13 +4,18 @@ const CSVHandler = {
      * 导出CSV文件
      * @param {string} apiPath - API路径
      * @param {string} filename - 文件名
+     * @param {Object} body - 导出参数：password 登录密码，archive_password 压缩包密码
      * @returns {Promise<void>}
      */
-    async exportCSV(apiPath, filename) {
+    async exportCSV(apiPath, filename, body = {}) {
         const jwt_token = Storage.get('jwt_token');
         const response = await fetch(apiPath, {
-            method: 'GET',
-            headers: jwt_token ? { 'Authorization': `Bearer ${jwt_token}` } : {}
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                ...(jwt_token ? { 'Authorization': `Bearer ${jwt_token}` } : {})
+            },
+            body: JSON.stringify({ format: 'csv', ...body })
         });
 
         if (response.status === 401) {
@@ -19,7 +24,12 @@ const CSVHandler = {
             throw new Error('Unauthorized');
         }
 
-        if (!response.ok) throw new Error(langManager.t('csv.exportFailed'));
+        if (!response.ok) {
+            // 错误响应为JSON，优先使用信息码对应的提示
+            const data = await response.json().catch(() => ({}));
+            const messageKey = getMessageKeyByCode(data.code);
+            throw new Error(messageKey ? langManager.t(messageKey) : (data.info || ''));
+        }
 
         const blob = await response.blob();
         const url = window.URL.createObjectURL(blob);
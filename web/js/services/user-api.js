@@ -12,6 +12,17 @@ const UserAPI = {
         return HTTP.post(`${API_BASE_URL}/sys/users/login`, { username, password }, { skipAuthCheck: true, ...options });
     },
 
+    /**
+     * 两步登录：提交动态验证码或恢复码
+     * @param {string} mfaToken - 口令校验通过后返回的临时凭证
+     * @param {string} code - 动态验证码或恢复码
+     * @param {Object} options - HTTP请求选项
+     * @returns {Promise<Object>}
+     */
+    loginTotp(mfaToken, code, options = {}) {
+        return HTTP.post(`${API_BASE_URL}/sys/users/login/totp`, { mfa_token: mfaToken, code }, { skipAuthCheck: true, ...options });
+    },
+
     /**
      * 根据用户ID查询用户信息
      * @param {number} userId - 用户ID
@@ -35,6 +35,9 @@ class TableRenderer {
         // 保存 callbacks 引用
         this._callbacks = callbacks;
 
+        // 保存 API 引用，敏感字段明文需通过 reveal 接口按需获取
+        this._api = config.api;
+
         container.innerHTML = `
             <div class="data-table-wrapper">
                 <table class="data-table">
@@ -141,7 +144,7 @@ class TableRenderer {
                 formattedValue = value ? value.split('T')[0] : '-';
                 break;
             case 'password':
-                formattedValue = this._formatPassword(value, rowId, col.copyable);
+                formattedValue = this._formatPassword(rowId, col.key, col.copyable);
                 break;
             case 'platformLink':
                 formattedValue = this._formatPlatformLink(value, item, col);
@@ -205,14 +208,14 @@ class TableRenderer {
         return `<span>${formattedDate}</span>`;
     }
 
-    static _formatPassword(value, rowId, copyable = false) {
-        const uniqueId = `pwd-${rowId}`;
-        const escapedValue = Helpers.escapeHtml(String(value));
+    static _formatPassword(rowId, field, copyable = false) {
+        const uniqueId = `pwd-${rowId}-${field}`;
 
+        // 列表接口只返回掩码，不在页面中嵌入明文
         if (copyable) {
             return `
                 <div style="display: flex; align-items: center; gap: 8px;">
-                    <span id="${uniqueId}" class="password-field copyable-cell" data-password="${escapedValue}" data-copy-value="${escapedValue}" onclick="TableRenderer.copyToClipboard('${escapedValue}')" title="${langManager.t('title.clickToCopy')}">••••••••</span>
+                    <span id="${uniqueId}" class="password-field copyable-cell" data-id="${rowId}" data-field="${field}" onclick="TableRenderer.copyPassword('${rowId}', '${field}')" title="${langManager.t('title.clickToCopy')}">••••••••</span>
                     <button class="btn-icon" onclick="TableRenderer.togglePassword('${uniqueId}')">
                         <i class="fas fa-eye"></i>
                     </button>
@@ -221,7 +224,7 @@ class TableRenderer {
         } else {
             return `
                 <div style="display: flex; align-items: center; gap: 8px;">
-                    <span id="${uniqueId}" class="password-field" data-password="${escapedValue}">••••••••</span>
+                    <span id="${uniqueId}" class="password-field" data-id="${rowId}" data-field="${field}">••••••••</span>
                     <button class="btn-icon" onclick="TableRenderer.togglePassword('${uniqueId}')">
                         <i class="fas fa-eye"></i>
                     </button>
@@ -464,13 +467,15 @@ class TableRenderer {
         }
     }
 
-    static togglePassword(elementId) {
+    static async togglePassword(elementId) {
         const element = document.getElementById(elementId);
         const button = element.nextElementSibling;
         const icon = button.querySelector('i');
-        const password = element.getAttribute('data-password');
 
         if (element.textContent === '••••••••') {
+            const password = await this._revealPassword(element.getAttribute('data-id'), element.getAttribute('data-field'));
+            if (password === null) return;
+
             element.textContent = password;
             icon.className = 'fas fa-eye-slash';
         } else {
@@ -479,6 +484,46 @@ class TableRenderer {
         }
     }
 
+    /**
+     * 获取敏感字段明文并复制到剪贴板
+     * @param {string} rowId - 记录ID
+     * @param {string} field - 敏感字段名
+     */
+    static async copyPassword(rowId, field) {
+        const password = await this._revealPassword(rowId, field);
+        if (password === null) return;
+
+        this.copyToClipboard(password);
+    }
+
+    /**
+     * 通过 reveal 接口获取单个敏感字段明文，服务端要求重新认证时提示输入登录密码
+     * @param {string} rowId - 记录ID
+     * @param {string} field - 敏感字段名
+     * @returns {Promise<string|null>} 明文，失败或取消时返回 null
+     */
+    static async _revealPassword(rowId, field) {
+        try {
+            const response = await this._api.reveal(rowId, field, '', { showErrorToast: false });
+            return response.data.value;
+        } catch (error) {
+            if (error.code !== InfoCodes.REAUTH_REQUIRED) {
+                Toast.error(error.message);
+                return null;
+            }
+        }
+
+        const password = window.prompt(langManager.t('api.error.reauthRequired'));
+        if (!password) return null;
+
+        try {
+            const response = await this._api.reveal(rowId, field, password);
+            return response.data.value;
+        } catch (error) {
+            return null;
+        }
+    }
+
     /**
      * 创建可复制的元素
      * @param {string} displayValue - 显示的值
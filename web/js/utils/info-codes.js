@@ -31,9 +31,61 @@ const InfoCodes = {
     FAILED_TO_UPLOAD: 110035,
     SUCCESSFUL_UPLOAD: 100035,
 
+    // 凭据保险库相关
+    VAULT_SEALED: 110041,
+    FAILED_TO_UNLOCK: 110042,
+    SUCCESSFUL_UNLOCK: 100042,
+    SUCCESSFUL_LOCK: 100043,
+
+    // 双因素认证相关
+    TOTP_REQUIRED: 100051,
+    INVALID_TOTP_CODE: 110052,
+    TOTP_ALREADY_ENABLED: 110053,
+    TOTP_NOT_ENABLED: 110054,
+    SUCCESSFUL_ENABLE_TOTP: 100055,
+    SUCCESSFUL_DISABLE_TOTP: 100056,
+
+    // 角色权限相关
+    PERMISSION_DENIED: 110061,
+    ROLE_ALREADY_EXISTS: 110062,
+    ROLE_IN_USE: 110063,
+    ROLE_IMMUTABLE: 110064,
+    LAST_ADMIN_REQUIRED: 110065,
+    UNKNOWN_PERMISSION: 110066,
+
+    // 敏感字段查看相关
+    SUCCESSFUL_REVEAL: 100071,
+    REAUTH_REQUIRED: 110072,
+    REAUTH_FAILED: 110073,
+
+    // 登录防护相关
+    ACCOUNT_LOCKED: 110081,
+    TOO_MANY_LOGIN_ATTEMPTS: 110082,
+
+    // 导入相关
+    INVALID_IMPORT_FILE: 110091,
+    INVALID_IMPORT_CREDENTIALS: 110092,
+
+    // 回收站相关
+    FAILED_TO_RESTORE: 110101,
+    SUCCESSFUL_RESTORE: 100101,
+    FAILED_TO_PURGE: 110102,
+    SUCCESSFUL_PURGE: 100102,
+    RECORD_NOT_IN_TRASH: 110103,
+
+    // 修订历史相关
+    FAILED_TO_ROLLBACK: 110111,
+    SUCCESSFUL_ROLLBACK: 100111,
+    REVISION_NOT_FOUND: 110112,
+
+    // 访问令牌相关
+    SESSION_REQUIRED: 110121,
+    INVALID_TOKEN_SCOPE: 110122,
+
     // 其它附加提示
     RECORD_NOT_FOUND: 210001,
     USERNAME_ALREADY_EXISTS: 210002,
+    GROUP_ALREADY_EXISTS: 210003,
 };
 
 // 信息码到国际化键的映射
@@ -69,9 +121,61 @@ const InfoCodeMessages = {
     [InfoCodes.FAILED_TO_UPLOAD]: 'api.error.failedToUpload',
     [InfoCodes.SUCCESSFUL_UPLOAD]: 'api.success.successfulUpload',
 
+    // 凭据保险库相关
+    [InfoCodes.VAULT_SEALED]: 'api.error.vaultSealed',
+    [InfoCodes.FAILED_TO_UNLOCK]: 'api.error.failedToUnlock',
+    [InfoCodes.SUCCESSFUL_UNLOCK]: 'api.success.successfulUnlock',
+    [InfoCodes.SUCCESSFUL_LOCK]: 'api.success.successfulLock',
+
+    // 双因素认证相关
+    [InfoCodes.TOTP_REQUIRED]: 'api.success.totpRequired',
+    [InfoCodes.INVALID_TOTP_CODE]: 'api.error.invalidTotpCode',
+    [InfoCodes.TOTP_ALREADY_ENABLED]: 'api.error.totpAlreadyEnabled',
+    [InfoCodes.TOTP_NOT_ENABLED]: 'api.error.totpNotEnabled',
+    [InfoCodes.SUCCESSFUL_ENABLE_TOTP]: 'api.success.successfulEnableTotp',
+    [InfoCodes.SUCCESSFUL_DISABLE_TOTP]: 'api.success.successfulDisableTotp',
+
+    // 角色权限相关
+    [InfoCodes.PERMISSION_DENIED]: 'api.error.permissionDenied',
+    [InfoCodes.ROLE_ALREADY_EXISTS]: 'api.error.roleAlreadyExists',
+    [InfoCodes.ROLE_IN_USE]: 'api.error.roleInUse',
+    [InfoCodes.ROLE_IMMUTABLE]: 'api.error.roleImmutable',
+    [InfoCodes.LAST_ADMIN_REQUIRED]: 'api.error.lastAdminRequired',
+    [InfoCodes.UNKNOWN_PERMISSION]: 'api.error.unknownPermission',
+
+    // 敏感字段查看相关
+    [InfoCodes.SUCCESSFUL_REVEAL]: 'api.success.successfulReveal',
+    [InfoCodes.REAUTH_REQUIRED]: 'api.error.reauthRequired',
+    [InfoCodes.REAUTH_FAILED]: 'api.error.reauthFailed',
+
+    // 登录防护相关
+    [InfoCodes.ACCOUNT_LOCKED]: 'api.error.accountLocked',
+    [InfoCodes.TOO_MANY_LOGIN_ATTEMPTS]: 'api.error.tooManyLoginAttempts',
+
+    // 导入相关
+    [InfoCodes.INVALID_IMPORT_FILE]: 'api.error.invalidImportFile',
+    [InfoCodes.INVALID_IMPORT_CREDENTIALS]: 'api.error.invalidImportCredentials',
+
+    // 回收站相关
+    [InfoCodes.FAILED_TO_RESTORE]: 'api.error.failedToRestore',
+    [InfoCodes.SUCCESSFUL_RESTORE]: 'api.success.successfulRestore',
+    [InfoCodes.FAILED_TO_PURGE]: 'api.error.failedToPurge',
+    [InfoCodes.SUCCESSFUL_PURGE]: 'api.success.successfulPurge',
+    [InfoCodes.RECORD_NOT_IN_TRASH]: 'api.error.recordNotInTrash',
+
+    // 修订历史相关
+    [InfoCodes.FAILED_TO_ROLLBACK]: 'api.error.failedToRollback',
+    [InfoCodes.SUCCESSFUL_ROLLBACK]: 'api.success.successfulRollback',
+    [InfoCodes.REVISION_NOT_FOUND]: 'api.error.revisionNotFound',
+
+    // 访问令牌相关
+    [InfoCodes.SESSION_REQUIRED]: 'api.error.sessionRequired',
+    [InfoCodes.INVALID_TOKEN_SCOPE]: 'api.error.invalidTokenScope',
+
     // 其它附加提示
     [InfoCodes.RECORD_NOT_FOUND]: 'api.error.recordNotFound',
     [InfoCodes.USERNAME_ALREADY_EXISTS]: 'api.error.usernameAlreadyExists',
+    [InfoCodes.GROUP_ALREADY_EXISTS]: 'api.error.groupAlreadyExists',
 };
 
 /**